@@ -15,6 +15,17 @@ type Task struct {
 	Status      int32                  `protobuf:"varint,7,opt,name=status,proto3" json:"status,omitempty"`
 	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	Priority    string                 `protobuf:"bytes,10,opt,name=priority,proto3" json:"priority,omitempty"`
+	StoryPoints *int32                 `protobuf:"varint,11,opt,name=story_points,json=storyPoints,proto3,oneof" json:"story_points,omitempty"`
+	DueDate     string                 `protobuf:"bytes,12,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	Labels      []*Label               `protobuf:"bytes,13,rep,name=labels,proto3" json:"labels,omitempty"`
+	Version     int32                  `protobuf:"varint,14,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+type Label struct {
+	Id    string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Color string `protobuf:"bytes,3,opt,name=color,proto3" json:"color,omitempty"`
 }
 
 type GetTaskRequest struct {
@@ -22,17 +33,27 @@ type GetTaskRequest struct {
 }
 
 type CreateTaskRequest struct {
-	ProjectId   string `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
-	SprintId    string `protobuf:"bytes,2,opt,name=sprint_id,json=sprintId,proto3" json:"sprint_id,omitempty"`
-	Title       string `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
-	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	ProjectId   string   `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	SprintId    string   `protobuf:"bytes,2,opt,name=sprint_id,json=sprintId,proto3" json:"sprint_id,omitempty"`
+	Title       string   `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Description string   `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Priority    string   `protobuf:"bytes,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	StoryPoints *int32   `protobuf:"varint,6,opt,name=story_points,json=storyPoints,proto3,oneof" json:"story_points,omitempty"`
+	DueDate     string   `protobuf:"bytes,7,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	LabelIds    []string `protobuf:"bytes,8,rep,name=label_ids,json=labelIds,proto3" json:"label_ids,omitempty"`
 }
 
 type UpdateTaskRequest struct {
-	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Title       string `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
-	Description string `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Status      int32  `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`
+	Id              string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title           string   `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description     string   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Status          int32    `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`
+	Priority        *string  `protobuf:"bytes,5,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	StoryPoints     *int32   `protobuf:"varint,6,opt,name=story_points,json=storyPoints,proto3,oneof" json:"story_points,omitempty"`
+	DueDate         *string  `protobuf:"bytes,7,opt,name=due_date,json=dueDate,proto3,oneof" json:"due_date,omitempty"`
+	LabelIds        []string `protobuf:"bytes,8,rep,name=label_ids,json=labelIds,proto3" json:"label_ids,omitempty"`
+	ReplaceLabels   bool     `protobuf:"varint,9,opt,name=replace_labels,json=replaceLabels,proto3" json:"replace_labels,omitempty"`
+	ExpectedVersion *int32   `protobuf:"varint,10,opt,name=expected_version,json=expectedVersion,proto3,oneof" json:"expected_version,omitempty"`
 }
 
 type DeleteTaskRequest struct {
@@ -43,14 +64,23 @@ type ListTasksRequest struct {
 	ProjectId  string `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
 	SprintId   string `protobuf:"bytes,2,opt,name=sprint_id,json=sprintId,proto3" json:"sprint_id,omitempty"`
 	AssigneeId string `protobuf:"bytes,3,opt,name=assignee_id,json=assigneeId,proto3" json:"assignee_id,omitempty"`
-	Status     int32  `protobuf:"varint,4,opt,name=status,proto3" json:"status,omitempty"`
+	Status     *int32 `protobuf:"varint,4,opt,name=status,proto3,oneof" json:"status,omitempty"`
 	Limit      int32  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset     int32  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Deprecated: ignored; use Cursor.
+	Offset    int32    `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	LabelIds  []string `protobuf:"bytes,7,rep,name=label_ids,json=labelIds,proto3" json:"label_ids,omitempty"`
+	DueAfter  string   `protobuf:"bytes,8,opt,name=due_after,json=dueAfter,proto3" json:"due_after,omitempty"`
+	DueBefore string   `protobuf:"bytes,9,opt,name=due_before,json=dueBefore,proto3" json:"due_before,omitempty"`
+	Query     string   `protobuf:"bytes,10,opt,name=query,proto3" json:"query,omitempty"`
+	Sort      string   `protobuf:"bytes,11,opt,name=sort,proto3" json:"sort,omitempty"`
+	Order     string   `protobuf:"bytes,12,opt,name=order,proto3" json:"order,omitempty"`
+	Cursor    string   `protobuf:"bytes,13,opt,name=cursor,proto3" json:"cursor,omitempty"`
 }
 
 type ListTasksResponse struct {
-	Tasks []*Task `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
-	Total int32   `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Tasks      []*Task `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	Total      int32   `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	NextCursor string  `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 }
 
 type AssignTaskRequest struct {
@@ -59,6 +89,7 @@ type AssignTaskRequest struct {
 }
 
 type UpdateTaskStatusRequest struct {
-	TaskId string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
-	Status int32  `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
+	TaskId          string `protobuf:"bytes,1,opt,name=task_id,json=taskId,proto3" json:"task_id,omitempty"`
+	Status          int32  `protobuf:"varint,2,opt,name=status,proto3" json:"status,omitempty"`
+	ExpectedVersion *int32 `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3,oneof" json:"expected_version,omitempty"`
 }
@@ -11,12 +11,16 @@ import (
 	"syscall"
 	"time"
 
+	"devhive-backend/cmd/devhive-api/migrations"
 	"devhive-backend/db"
+	"devhive-backend/internal/broadcast"
 	"devhive-backend/internal/config"
 	dbnotify "devhive-backend/internal/db"
 	"devhive-backend/internal/grpc"
 	"devhive-backend/internal/http/router"
+	"devhive-backend/internal/idempotency"
 	"devhive-backend/internal/repo"
+	"devhive-backend/internal/trash"
 	"devhive-backend/internal/ws"
 
 	"github.com/jackc/pgx/v5"
@@ -47,7 +51,7 @@ func main() {
 	}()
 
 	// Run database migrations
-	if err := db.RunMigrations(database); err != nil {
+	if err := db.RunMigrations(database, migrations.FS); err != nil {
 		log.Printf("Warning: Migration failed: %v", err)
 	}
 	
@@ -74,17 +78,36 @@ func main() {
 	queries := repo.New(pool)
 
 	// Start WebSocket hub FIRST (before HTTP server)
-	ws.StartWebSocketHub()
+	ws.StartWebSocketHub(queries)
 	log.Println("WebSocket hub started")
 
+	// Fan hub events out through PostgreSQL so every replica's clients get
+	// them; each instance's NOTIFY listener delivers to its own clients
+	switch cfg.Realtime.Transport {
+	case "local":
+		log.Println("WebSocket hub using in-process transport")
+	case "postgres":
+		ws.GlobalHub.SetTransport(ws.NewPostgresTransport(queries))
+		log.Println("WebSocket hub using PostgreSQL NOTIFY transport")
+	default:
+		log.Fatalf("Unknown REALTIME_TRANSPORT %q (want postgres or local)", cfg.Realtime.Transport)
+	}
+	broadcast.SetPublisher(ws.GlobalHub)
+
 	// Start NOTIFY listener with dedicated connection
 	log.Println("🔧 main.go: About to call StartNotifyListener...")
 	log.Printf("🔧 main.go: DatabaseURL length: %d characters", len(cfg.DatabaseURL))
 	log.Printf("🔧 main.go: Hub is nil: %v", ws.GlobalHub == nil)
-	dbnotify.StartNotifyListener(cfg.DatabaseURL, ws.GlobalHub)
+	dbnotify.StartNotifyListener(cfg.DatabaseURL, ws.GlobalHub, queries)
 	log.Println("✅ main.go: StartNotifyListener call completed")
 	log.Println("PostgreSQL NOTIFY listener started")
 
+	// Permanently remove soft-deleted items once their retention period ends
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	trash.StartPurger(purgeCtx, queries, cfg.Trash.Retention, cfg.Trash.PurgeInterval)
+	idempotency.StartPurger(purgeCtx, queries, cfg.Idempotency.PurgeInterval)
+
 	// Setup router (pass hub to router)
 	r := router.Setup(cfg, queries, database, ws.GlobalHub)
 
@@ -0,0 +1,18 @@
+-- Migration: Store password reset tokens as hashes
+-- Reset tokens were stored in plaintext. They are now stored as SHA-256
+-- hashes, are single-use (used_at) and outstanding plaintext tokens are
+-- invalidated.
+
+-- Outstanding plaintext tokens cannot be converted, so drop them
+DELETE FROM password_resets;
+
+DROP INDEX IF EXISTS idx_password_resets_reset_token;
+ALTER TABLE password_resets DROP COLUMN IF EXISTS reset_token;
+
+ALTER TABLE password_resets
+  ADD COLUMN IF NOT EXISTS token_hash TEXT NOT NULL UNIQUE,
+  ADD COLUMN IF NOT EXISTS used_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_password_resets_user_active
+  ON password_resets (user_id, expires_at)
+  WHERE used_at IS NULL;
@@ -0,0 +1,12 @@
+-- Revert 014: drop per-recipient invites. Invites for a specific recipient
+-- are deleted; without recipient_email anyone with the link could accept them.
+
+DELETE FROM project_invites WHERE recipient_email IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_project_invites_recipient_email;
+ALTER TABLE project_invites
+  DROP COLUMN IF EXISTS recipient_email,
+  DROP COLUMN IF EXISTS accepted_at,
+  DROP COLUMN IF EXISTS accepted_by,
+  DROP COLUMN IF EXISTS last_sent_at,
+  DROP COLUMN IF EXISTS send_count;
@@ -0,0 +1,15 @@
+-- Migration: Add per-recipient email invites
+-- Invites can now target a specific email address. The invite link is
+-- mailed to the recipient and can only be accepted by a user with that
+-- email. Acceptance and delivery are tracked per invite.
+
+ALTER TABLE project_invites
+  ADD COLUMN IF NOT EXISTS recipient_email TEXT,
+  ADD COLUMN IF NOT EXISTS accepted_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS accepted_by UUID REFERENCES users(id) ON DELETE SET NULL,
+  ADD COLUMN IF NOT EXISTS last_sent_at TIMESTAMPTZ,
+  ADD COLUMN IF NOT EXISTS send_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_project_invites_recipient_email
+  ON project_invites (project_id, lower(recipient_email))
+  WHERE recipient_email IS NOT NULL;
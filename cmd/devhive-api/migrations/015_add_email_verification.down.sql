@@ -0,0 +1,4 @@
+-- Revert 015: drop email verification
+
+DROP TABLE IF EXISTS email_verifications;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified_at;
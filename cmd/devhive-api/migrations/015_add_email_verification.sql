@@ -0,0 +1,26 @@
+-- Migration: Add email address verification
+-- Records when a user's email was verified and stores hashed,
+-- single-use verification tokens. Google OAuth users are verified by Google.
+-- Accounts that exist before verification was introduced count as verified,
+-- so turning on REQUIRE_VERIFIED_EMAIL does not lock them out.
+
+ALTER TABLE users
+  ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+
+UPDATE users
+SET email_verified_at = created_at
+WHERE email_verified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS email_verifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verifications_user_active
+  ON email_verifications (user_id, expires_at)
+  WHERE used_at IS NULL;
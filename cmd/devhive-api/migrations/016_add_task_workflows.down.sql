@@ -0,0 +1,7 @@
+-- Revert 016: drop task workflows. Task statuses are left as they are.
+
+DROP TRIGGER IF EXISTS projects_seed_workflow ON projects;
+DROP FUNCTION IF EXISTS seed_project_workflow();
+DROP FUNCTION IF EXISTS seed_default_workflow(UUID);
+DROP TABLE IF EXISTS workflow_transitions;
+DROP TABLE IF EXISTS workflow_states;
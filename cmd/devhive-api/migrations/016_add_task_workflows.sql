@@ -0,0 +1,76 @@
+-- Migration: Add per-project task workflows
+-- Gives tasks.status a meaning: each project defines named states keyed by
+-- the status integer, plus the transitions allowed between them. Every
+-- project is seeded with To Do (0) / In Progress (1) / Done (2).
+
+CREATE TABLE IF NOT EXISTS workflow_states (
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    status INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL DEFAULT '#6B7280',
+    position INTEGER NOT NULL DEFAULT 0,
+    is_initial BOOLEAN NOT NULL DEFAULT false,
+    is_final BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, status)
+);
+
+-- At most one initial state per project
+CREATE UNIQUE INDEX IF NOT EXISTS idx_workflow_states_initial
+  ON workflow_states (project_id)
+  WHERE is_initial;
+
+CREATE TABLE IF NOT EXISTS workflow_transitions (
+    project_id UUID NOT NULL,
+    from_status INTEGER NOT NULL,
+    to_status INTEGER NOT NULL,
+    PRIMARY KEY (project_id, from_status, to_status),
+    FOREIGN KEY (project_id, from_status) REFERENCES workflow_states(project_id, status) ON DELETE CASCADE,
+    FOREIGN KEY (project_id, to_status) REFERENCES workflow_states(project_id, status) ON DELETE CASCADE,
+    CHECK (from_status <> to_status)
+);
+
+-- Seeds the default workflow for a project; a no-op if it already has states
+CREATE OR REPLACE FUNCTION seed_default_workflow(p_project_id UUID) RETURNS void AS $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM workflow_states WHERE project_id = p_project_id) THEN
+    RETURN;
+  END IF;
+
+  INSERT INTO workflow_states (project_id, status, name, color, position, is_initial, is_final)
+  VALUES
+    (p_project_id, 0, 'To Do', '#6B7280', 0, true, false),
+    (p_project_id, 1, 'In Progress', '#3B82F6', 1, false, false),
+    (p_project_id, 2, 'Done', '#10B981', 2, false, true);
+
+  -- Keep statuses already stored on tasks valid
+  INSERT INTO workflow_states (project_id, status, name, position)
+  SELECT DISTINCT t.project_id, t.status, 'Status ' || t.status, t.status
+  FROM tasks t
+  WHERE t.project_id = p_project_id AND t.status NOT IN (0, 1, 2)
+  ON CONFLICT (project_id, status) DO NOTHING;
+
+  -- Default workflow lets a task move between any two states
+  INSERT INTO workflow_transitions (project_id, from_status, to_status)
+  SELECT p_project_id, f.status, t.status
+  FROM workflow_states f
+  JOIN workflow_states t ON t.project_id = f.project_id AND t.status <> f.status
+  WHERE f.project_id = p_project_id;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION seed_project_workflow() RETURNS trigger AS $$
+BEGIN
+  PERFORM seed_default_workflow(NEW.id);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS projects_seed_workflow ON projects;
+CREATE TRIGGER projects_seed_workflow
+  AFTER INSERT ON projects
+  FOR EACH ROW EXECUTE FUNCTION seed_project_workflow();
+
+-- Backfill existing projects
+SELECT seed_default_workflow(id) FROM projects;
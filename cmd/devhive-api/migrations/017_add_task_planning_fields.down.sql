@@ -0,0 +1,12 @@
+-- Revert 017: drop labels and task planning fields
+
+DROP TABLE IF EXISTS task_labels;
+DROP TABLE IF EXISTS labels;
+
+DROP INDEX IF EXISTS idx_tasks_due_date;
+ALTER TABLE tasks
+  DROP CONSTRAINT IF EXISTS tasks_priority_check,
+  DROP CONSTRAINT IF EXISTS tasks_story_points_check,
+  DROP COLUMN IF EXISTS priority,
+  DROP COLUMN IF EXISTS story_points,
+  DROP COLUMN IF EXISTS due_date;
@@ -0,0 +1,39 @@
+-- Migration: Add planning data to tasks
+-- Priority (0 none, 1 low, 2 medium, 3 high, 4 urgent), story-point estimates,
+-- due dates, and project-scoped labels attached to tasks many-to-many.
+
+ALTER TABLE tasks
+  ADD COLUMN IF NOT EXISTS priority SMALLINT NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS story_points INTEGER,
+  ADD COLUMN IF NOT EXISTS due_date DATE;
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_priority_check') THEN
+    ALTER TABLE tasks ADD CONSTRAINT tasks_priority_check CHECK (priority BETWEEN 0 AND 4);
+  END IF;
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'tasks_story_points_check') THEN
+    ALTER TABLE tasks ADD CONSTRAINT tasks_story_points_check CHECK (story_points >= 0);
+  END IF;
+END $$;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_due_date ON tasks (project_id, due_date) WHERE due_date IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT NOT NULL DEFAULT '#6B7280',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_project_name ON labels (project_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    label_id UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label_id ON task_labels (label_id);
@@ -0,0 +1,6 @@
+-- Revert 018: drop the task listing and search indexes
+
+DROP INDEX IF EXISTS idx_tasks_search;
+DROP INDEX IF EXISTS idx_tasks_project_status;
+DROP INDEX IF EXISTS idx_tasks_project_priority;
+DROP INDEX IF EXISTS idx_tasks_project_updated_at;
@@ -0,0 +1,10 @@
+-- Migration: Index tasks for listing filters and full-text search
+-- The search expression must match the one used by the ListTasks query
+-- (to_tsvector('english', coalesce(description, ''))) for the index to apply.
+
+CREATE INDEX IF NOT EXISTS idx_tasks_search ON tasks
+  USING GIN (to_tsvector('english', coalesce(description, '')));
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_status ON tasks (project_id, status);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_priority ON tasks (project_id, priority);
+CREATE INDEX IF NOT EXISTS idx_tasks_project_updated_at ON tasks (project_id, updated_at);
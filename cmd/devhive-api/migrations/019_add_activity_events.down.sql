@@ -0,0 +1,7 @@
+-- Revert 019: drop the activity log
+
+DROP TRIGGER IF EXISTS activity_events_notify ON activity_events;
+DROP TRIGGER IF EXISTS activity_events_append_only ON activity_events;
+DROP FUNCTION IF EXISTS notify_activity_event();
+DROP FUNCTION IF EXISTS reject_activity_event_update();
+DROP TABLE IF EXISTS activity_events;
@@ -0,0 +1,62 @@
+-- Migration: Append-only activity log of project mutations
+-- Rows are written by the API with the acting user and a before/after diff,
+-- and are only ever removed together with their project. actor_id is not a
+-- foreign key so the history survives account deletion.
+
+CREATE TABLE IF NOT EXISTS activity_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    actor_id UUID,
+    resource TEXT NOT NULL,
+    resource_id UUID,
+    action TEXT NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_events_project ON activity_events (project_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_activity_events_resource ON activity_events (project_id, resource, resource_id);
+
+-- Reject edits so the log stays append-only
+CREATE OR REPLACE FUNCTION reject_activity_event_update()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'activity_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS activity_events_append_only ON activity_events;
+CREATE TRIGGER activity_events_append_only
+  BEFORE UPDATE ON activity_events
+  FOR EACH ROW EXECUTE FUNCTION reject_activity_event_update();
+
+-- Stream new events to the API instances on the 'activity' channel.
+-- NOTIFY payloads are capped at 8000 bytes, so large diffs are left out
+-- and flagged; clients can fetch the full event from the activity endpoint.
+CREATE OR REPLACE FUNCTION notify_activity_event()
+RETURNS TRIGGER AS $$
+DECLARE
+  payload JSONB;
+BEGIN
+  payload := jsonb_build_object(
+    'id', NEW.id,
+    'projectId', NEW.project_id,
+    'actorId', NEW.actor_id,
+    'resource', NEW.resource,
+    'resourceId', NEW.resource_id,
+    'action', NEW.action,
+    'changes', NEW.changes,
+    'createdAt', NEW.created_at
+  );
+  IF octet_length(payload::text) > 7500 THEN
+    payload := payload - 'changes' || jsonb_build_object('truncated', true);
+  END IF;
+  PERFORM pg_notify('activity', payload::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS activity_events_notify ON activity_events;
+CREATE TRIGGER activity_events_notify
+  AFTER INSERT ON activity_events
+  FOR EACH ROW EXECUTE FUNCTION notify_activity_event();
@@ -0,0 +1,78 @@
+-- Revert 020: drop soft delete. Trashed rows are purged first; without
+-- deleted_at they would reappear as live rows.
+
+DELETE FROM projects WHERE deleted_at IS NOT NULL;
+DELETE FROM sprints WHERE deleted_at IS NOT NULL;
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+-- Restore the function from 012, which reports updates as UPDATE
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+DECLARE
+  notification_payload JSONB;
+  project_uuid UUID;
+  record_id TEXT;
+  resource_name TEXT;
+BEGIN
+  -- Extract project_id based on resource type
+  IF TG_TABLE_NAME = 'projects' THEN
+    project_uuid := COALESCE(NEW.id, OLD.id);
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSE
+    -- Unknown table, skip notification
+    RETURN COALESCE(NEW, OLD);
+  END IF;
+
+  -- Build record ID - for project_members, use composite key since there's no id column
+  IF TG_TABLE_NAME = 'project_members' THEN
+    record_id := COALESCE(NEW.project_id::text || ':' || NEW.user_id::text, OLD.project_id::text || ':' || OLD.user_id::text);
+  ELSE
+    record_id := COALESCE(NEW.id::text, OLD.id::text);
+  END IF;
+  
+  -- Normalize resource name to singular for frontend consistency
+  -- Frontend expects: 'project', 'sprint', 'task', 'message', 'project_members'
+  IF TG_TABLE_NAME = 'projects' THEN
+    resource_name := 'project';
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    resource_name := 'sprint';
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    resource_name := 'task';
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    resource_name := 'message';
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    resource_name := 'project_members'; -- Keep plural for consistency
+  ELSE
+    resource_name := TG_TABLE_NAME; -- Fallback to table name
+  END IF;
+  
+  -- Build minimal payload (< 1KB)
+  notification_payload := json_build_object(
+    'resource', resource_name,
+    'id', record_id,
+    'action', TG_OP,
+    'projectId', project_uuid::text,
+    'timestamp', NOW()
+  );
+
+  -- Use single channel with payload filtering
+  PERFORM pg_notify('cache_invalidate', notification_payload::text);
+  
+  RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_projects_deleted_at;
+DROP INDEX IF EXISTS idx_sprints_deleted_at;
+DROP INDEX IF EXISTS idx_tasks_deleted_at;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE sprints DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
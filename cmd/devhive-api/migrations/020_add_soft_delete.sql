@@ -0,0 +1,89 @@
+-- Migration: Soft delete for projects, sprints and tasks
+-- Deleting sets deleted_at instead of removing the row, so nothing cascades
+-- until the purge job removes items whose retention period has passed.
+-- Tasks deleted together with their sprint share the sprint's deleted_at,
+-- which is how restoring the sprint finds them.
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE sprints ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects (owner_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_sprints_deleted_at ON sprints (project_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks (project_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Report soft deletes and restores as DELETE and INSERT cache invalidations
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+DECLARE
+  notification_payload JSONB;
+  action_name TEXT;
+  project_uuid UUID;
+  record_id TEXT;
+  resource_name TEXT;
+BEGIN
+  -- Extract project_id based on resource type
+  IF TG_TABLE_NAME = 'projects' THEN
+    project_uuid := COALESCE(NEW.id, OLD.id);
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSE
+    -- Unknown table, skip notification
+    RETURN COALESCE(NEW, OLD);
+  END IF;
+
+  -- Build record ID - for project_members, use composite key since there's no id column
+  IF TG_TABLE_NAME = 'project_members' THEN
+    record_id := COALESCE(NEW.project_id::text || ':' || NEW.user_id::text, OLD.project_id::text || ':' || OLD.user_id::text);
+  ELSE
+    record_id := COALESCE(NEW.id::text, OLD.id::text);
+  END IF;
+  
+  -- Normalize resource name to singular for frontend consistency
+  -- Frontend expects: 'project', 'sprint', 'task', 'message', 'project_members'
+  IF TG_TABLE_NAME = 'projects' THEN
+    resource_name := 'project';
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    resource_name := 'sprint';
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    resource_name := 'task';
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    resource_name := 'message';
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    resource_name := 'project_members'; -- Keep plural for consistency
+  ELSE
+    resource_name := TG_TABLE_NAME; -- Fallback to table name
+  END IF;
+  
+  -- Moving a row to or from the trash looks like a delete or insert to
+  -- clients, so their lists drop or regain it
+  action_name := TG_OP;
+  IF TG_OP = 'UPDATE' AND TG_TABLE_NAME IN ('projects', 'sprints', 'tasks') THEN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      action_name := 'DELETE';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      action_name := 'INSERT';
+    END IF;
+  END IF;
+
+  -- Build minimal payload (< 1KB)
+  notification_payload := json_build_object(
+    'resource', resource_name,
+    'id', record_id,
+    'action', action_name,
+    'projectId', project_uuid::text,
+    'timestamp', NOW()
+  );
+
+  -- Use single channel with payload filtering
+  PERFORM pg_notify('cache_invalidate', notification_payload::text);
+  
+  RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
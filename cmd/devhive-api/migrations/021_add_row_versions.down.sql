@@ -0,0 +1,10 @@
+-- Revert 021: drop row versions
+
+DROP TRIGGER IF EXISTS bump_projects_version ON projects;
+DROP TRIGGER IF EXISTS bump_sprints_version ON sprints;
+DROP TRIGGER IF EXISTS bump_tasks_version ON tasks;
+DROP FUNCTION IF EXISTS bump_row_version();
+
+ALTER TABLE projects DROP COLUMN IF EXISTS version;
+ALTER TABLE sprints DROP COLUMN IF EXISTS version;
+ALTER TABLE tasks DROP COLUMN IF EXISTS version;
@@ -0,0 +1,27 @@
+-- Migration: Add row versions for optimistic concurrency
+-- Every update to a project, sprint or task bumps its version, which the API
+-- exposes as an ETag and checks against If-Match before writing.
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE sprints ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+CREATE OR REPLACE FUNCTION bump_row_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS bump_projects_version ON projects;
+CREATE TRIGGER bump_projects_version BEFORE UPDATE ON projects
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS bump_sprints_version ON sprints;
+CREATE TRIGGER bump_sprints_version BEFORE UPDATE ON sprints
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
+
+DROP TRIGGER IF EXISTS bump_tasks_version ON tasks;
+CREATE TRIGGER bump_tasks_version BEFORE UPDATE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION bump_row_version();
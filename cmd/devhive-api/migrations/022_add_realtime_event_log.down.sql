@@ -0,0 +1,104 @@
+-- Revert 022: drop the realtime event log and restore the notify functions
+-- from 020 and 019, whose payloads carry no sequence number
+
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+DECLARE
+  notification_payload JSONB;
+  action_name TEXT;
+  project_uuid UUID;
+  record_id TEXT;
+  resource_name TEXT;
+BEGIN
+  -- Extract project_id based on resource type
+  IF TG_TABLE_NAME = 'projects' THEN
+    project_uuid := COALESCE(NEW.id, OLD.id);
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSE
+    -- Unknown table, skip notification
+    RETURN COALESCE(NEW, OLD);
+  END IF;
+
+  -- Build record ID - for project_members, use composite key since there's no id column
+  IF TG_TABLE_NAME = 'project_members' THEN
+    record_id := COALESCE(NEW.project_id::text || ':' || NEW.user_id::text, OLD.project_id::text || ':' || OLD.user_id::text);
+  ELSE
+    record_id := COALESCE(NEW.id::text, OLD.id::text);
+  END IF;
+  
+  -- Normalize resource name to singular for frontend consistency
+  -- Frontend expects: 'project', 'sprint', 'task', 'message', 'project_members'
+  IF TG_TABLE_NAME = 'projects' THEN
+    resource_name := 'project';
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    resource_name := 'sprint';
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    resource_name := 'task';
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    resource_name := 'message';
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    resource_name := 'project_members'; -- Keep plural for consistency
+  ELSE
+    resource_name := TG_TABLE_NAME; -- Fallback to table name
+  END IF;
+  
+  -- Moving a row to or from the trash looks like a delete or insert to
+  -- clients, so their lists drop or regain it
+  action_name := TG_OP;
+  IF TG_OP = 'UPDATE' AND TG_TABLE_NAME IN ('projects', 'sprints', 'tasks') THEN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      action_name := 'DELETE';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      action_name := 'INSERT';
+    END IF;
+  END IF;
+
+  -- Build minimal payload (< 1KB)
+  notification_payload := json_build_object(
+    'resource', resource_name,
+    'id', record_id,
+    'action', action_name,
+    'projectId', project_uuid::text,
+    'timestamp', NOW()
+  );
+
+  -- Use single channel with payload filtering
+  PERFORM pg_notify('cache_invalidate', notification_payload::text);
+  
+  RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_activity_event()
+RETURNS TRIGGER AS $$
+DECLARE
+  payload JSONB;
+BEGIN
+  payload := jsonb_build_object(
+    'id', NEW.id,
+    'projectId', NEW.project_id,
+    'actorId', NEW.actor_id,
+    'resource', NEW.resource,
+    'resourceId', NEW.resource_id,
+    'action', NEW.action,
+    'changes', NEW.changes,
+    'createdAt', NEW.created_at
+  );
+  IF octet_length(payload::text) > 7500 THEN
+    payload := payload - 'changes' || jsonb_build_object('truncated', true);
+  END IF;
+  PERFORM pg_notify('activity', payload::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS record_realtime_event(UUID, TEXT, JSONB);
+DROP TABLE IF EXISTS realtime_events;
+DROP TABLE IF EXISTS project_event_seqs;
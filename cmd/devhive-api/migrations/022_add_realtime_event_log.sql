@@ -0,0 +1,164 @@
+-- Migration: Sequenced realtime event log for WebSocket replay
+-- Every cache invalidation and activity event gets the next sequence number
+-- of its project and is kept in realtime_events, so a client that reconnects
+-- can resume from the last sequence number it saw. Only the newest 1000
+-- events per project are kept; older gaps need a full resync.
+-- Neither table references projects: events for a project are recorded by
+-- triggers that also fire while the project row is being deleted.
+
+CREATE TABLE IF NOT EXISTS project_event_seqs (
+    project_id UUID PRIMARY KEY,
+    last_seq BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS realtime_events (
+    project_id UUID NOT NULL,
+    seq BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, seq)
+);
+
+-- Assign the project's next sequence number and append the event, trimming
+-- the log to its bound. The row lock on project_event_seqs orders concurrent
+-- writers, so sequence numbers follow commit order. Returns NULL, recording
+-- nothing, once the project is gone.
+CREATE OR REPLACE FUNCTION record_realtime_event(p_project_id UUID, p_type TEXT, p_data JSONB)
+RETURNS BIGINT AS $$
+DECLARE
+  next_seq BIGINT;
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM projects WHERE id = p_project_id) THEN
+    DELETE FROM realtime_events WHERE project_id = p_project_id;
+    DELETE FROM project_event_seqs WHERE project_id = p_project_id;
+    RETURN NULL;
+  END IF;
+
+  INSERT INTO project_event_seqs (project_id, last_seq)
+  VALUES (p_project_id, 1)
+  ON CONFLICT (project_id) DO UPDATE SET last_seq = project_event_seqs.last_seq + 1
+  RETURNING last_seq INTO next_seq;
+
+  INSERT INTO realtime_events (project_id, seq, type, data)
+  VALUES (p_project_id, next_seq, p_type, p_data);
+
+  DELETE FROM realtime_events WHERE project_id = p_project_id AND seq <= next_seq - 1000;
+
+  RETURN next_seq;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Same as 020, plus the event's sequence number in the payload. The logged
+-- data is the message data clients receive for a cache invalidation.
+CREATE OR REPLACE FUNCTION notify_cache_invalidation()
+RETURNS TRIGGER AS $$
+DECLARE
+  notification_payload JSONB;
+  action_name TEXT;
+  project_uuid UUID;
+  record_id TEXT;
+  resource_name TEXT;
+  event_seq BIGINT;
+BEGIN
+  -- Extract project_id based on resource type
+  IF TG_TABLE_NAME = 'projects' THEN
+    project_uuid := COALESCE(NEW.id, OLD.id);
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    project_uuid := COALESCE(NEW.project_id, OLD.project_id);
+  ELSE
+    -- Unknown table, skip notification
+    RETURN COALESCE(NEW, OLD);
+  END IF;
+
+  -- Build record ID - for project_members, use composite key since there's no id column
+  IF TG_TABLE_NAME = 'project_members' THEN
+    record_id := COALESCE(NEW.project_id::text || ':' || NEW.user_id::text, OLD.project_id::text || ':' || OLD.user_id::text);
+  ELSE
+    record_id := COALESCE(NEW.id::text, OLD.id::text);
+  END IF;
+
+  -- Normalize resource name to singular for frontend consistency
+  -- Frontend expects: 'project', 'sprint', 'task', 'message', 'project_members'
+  IF TG_TABLE_NAME = 'projects' THEN
+    resource_name := 'project';
+  ELSIF TG_TABLE_NAME = 'sprints' THEN
+    resource_name := 'sprint';
+  ELSIF TG_TABLE_NAME = 'tasks' THEN
+    resource_name := 'task';
+  ELSIF TG_TABLE_NAME = 'messages' THEN
+    resource_name := 'message';
+  ELSIF TG_TABLE_NAME = 'project_members' THEN
+    resource_name := 'project_members'; -- Keep plural for consistency
+  ELSE
+    resource_name := TG_TABLE_NAME; -- Fallback to table name
+  END IF;
+
+  -- Moving a row to or from the trash looks like a delete or insert to
+  -- clients, so their lists drop or regain it
+  action_name := TG_OP;
+  IF TG_OP = 'UPDATE' AND TG_TABLE_NAME IN ('projects', 'sprints', 'tasks') THEN
+    IF OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL THEN
+      action_name := 'DELETE';
+    ELSIF OLD.deleted_at IS NOT NULL AND NEW.deleted_at IS NULL THEN
+      action_name := 'INSERT';
+    END IF;
+  END IF;
+
+  event_seq := record_realtime_event(project_uuid, 'cache_invalidate', jsonb_build_object(
+    'resource', resource_name,
+    'id', record_id,
+    'action', action_name,
+    'project_id', project_uuid::text,
+    'timestamp', NOW()
+  ));
+
+  -- Build minimal payload (< 1KB)
+  notification_payload := jsonb_build_object(
+    'resource', resource_name,
+    'id', record_id,
+    'action', action_name,
+    'projectId', project_uuid::text,
+    'timestamp', NOW(),
+    'seq', event_seq
+  );
+
+  -- Use single channel with payload filtering
+  PERFORM pg_notify('cache_invalidate', notification_payload::text);
+
+  RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Same as 019, plus the sequence number. The log keeps the full event even
+-- when the NOTIFY payload has to leave the diff out.
+CREATE OR REPLACE FUNCTION notify_activity_event()
+RETURNS TRIGGER AS $$
+DECLARE
+  payload JSONB;
+  event_seq BIGINT;
+BEGIN
+  payload := jsonb_build_object(
+    'id', NEW.id,
+    'projectId', NEW.project_id,
+    'actorId', NEW.actor_id,
+    'resource', NEW.resource,
+    'resourceId', NEW.resource_id,
+    'action', NEW.action,
+    'changes', NEW.changes,
+    'createdAt', NEW.created_at
+  );
+  event_seq := record_realtime_event(NEW.project_id, 'activity', payload);
+  IF octet_length(payload::text) > 7500 THEN
+    payload := payload - 'changes' || jsonb_build_object('truncated', true);
+  END IF;
+  PERFORM pg_notify('activity', (payload || jsonb_build_object('seq', event_seq))::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
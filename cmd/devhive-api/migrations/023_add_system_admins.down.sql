@@ -0,0 +1,6 @@
+-- Revert 023: drop system admins and the admin audit log
+
+DROP TRIGGER IF EXISTS admin_audit_log_append_only ON admin_audit_log;
+DROP FUNCTION IF EXISTS reject_admin_audit_log_change();
+DROP TABLE IF EXISTS admin_audit_log;
+DROP TABLE IF EXISTS system_admins;
@@ -0,0 +1,37 @@
+-- Migration: System administrators and the admin audit log
+-- Admins may use the /migrations endpoints. They are granted by hand:
+--   INSERT INTO system_admins (user_id) SELECT id FROM users WHERE username = '...';
+-- Every request to an admin endpoint is appended to admin_audit_log, including
+-- refused ones. user_id is not a foreign key so the log survives account
+-- deletion.
+
+CREATE TABLE IF NOT EXISTS system_admins (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    status INT NOT NULL,
+    request_body TEXT NOT NULL DEFAULT '',
+    remote_addr TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created ON admin_audit_log (created_at DESC);
+
+-- Reject edits and deletes so the log stays append-only
+CREATE OR REPLACE FUNCTION reject_admin_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+  RAISE EXCEPTION 'admin_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS admin_audit_log_append_only ON admin_audit_log;
+CREATE TRIGGER admin_audit_log_append_only
+  BEFORE UPDATE OR DELETE ON admin_audit_log
+  FOR EACH ROW EXECUTE FUNCTION reject_admin_audit_log_change();
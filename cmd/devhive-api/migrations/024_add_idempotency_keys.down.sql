@@ -0,0 +1,3 @@
+-- Revert 024: drop idempotency keys
+
+DROP TABLE IF EXISTS idempotency_keys;
@@ -0,0 +1,25 @@
+-- Migration: Idempotency keys for create requests
+-- A client may send an Idempotency-Key header with a POST; the first request
+-- with a key claims it and stores its response, and retries with the same key
+-- get that response instead of creating a duplicate. status is NULL while the
+-- first request is still running. Keys are scoped to the user and expire.
+-- A running request holds its key until locked_until, so a claim left behind
+-- by a crashed server can be taken over long before the key expires.
+-- claim_token identifies the request holding the key, so a request whose
+-- claim was taken over cannot store its response over, or release, the new one.
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key TEXT NOT NULL,
+    fingerprint TEXT NOT NULL,
+    status INTEGER,
+    content_type TEXT NOT NULL DEFAULT '',
+    body BYTEA,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    claim_token UUID NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,8 @@
+-- Revert 025: notify every task change individually again
+
+DROP TRIGGER IF EXISTS tasks_cache_invalidate ON tasks;
+CREATE TRIGGER tasks_cache_invalidate
+  AFTER INSERT OR UPDATE OR DELETE ON tasks
+  FOR EACH ROW EXECUTE FUNCTION notify_cache_invalidation();
+
+DROP FUNCTION IF EXISTS notify_tasks_changed(UUID, UUID[]);
@@ -0,0 +1,45 @@
+-- Migration: One realtime event for a bulk task change
+-- A bulk task request sets devhive.defer_task_notify for its transaction,
+-- which holds back the per-row task cache invalidations; it then calls
+-- notify_tasks_changed once with every task it touched. Clients get a single
+-- cache_invalidate with action BULK and the task IDs in "ids".
+
+DROP TRIGGER IF EXISTS tasks_cache_invalidate ON tasks;
+CREATE TRIGGER tasks_cache_invalidate
+  AFTER INSERT OR UPDATE OR DELETE ON tasks
+  FOR EACH ROW
+  WHEN (current_setting('devhive.defer_task_notify', true) IS DISTINCT FROM 'on')
+  EXECUTE FUNCTION notify_cache_invalidation();
+
+-- Record and send one cache invalidation for many tasks of a project. Payloads
+-- are kept under the NOTIFY limit by the callers' cap on bulk operations.
+CREATE OR REPLACE FUNCTION notify_tasks_changed(p_project_id UUID, p_task_ids UUID[])
+RETURNS VOID AS $$
+DECLARE
+  task_ids JSONB;
+  event_seq BIGINT;
+BEGIN
+  IF cardinality(p_task_ids) = 0 THEN
+    RETURN;
+  END IF;
+
+  SELECT jsonb_agg(id::text) INTO task_ids FROM unnest(p_task_ids) AS id;
+
+  event_seq := record_realtime_event(p_project_id, 'cache_invalidate', jsonb_build_object(
+    'resource', 'task',
+    'ids', task_ids,
+    'action', 'BULK',
+    'project_id', p_project_id::text,
+    'timestamp', NOW()
+  ));
+
+  PERFORM pg_notify('cache_invalidate', jsonb_build_object(
+    'resource', 'task',
+    'ids', task_ids,
+    'action', 'BULK',
+    'projectId', p_project_id::text,
+    'timestamp', NOW(),
+    'seq', event_seq
+  )::text);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,36 @@
+-- Revert 026: drop the lifecycle constraints, leaving sprint states as they
+-- are, and restore notify_tasks_changed from 025
+
+DROP INDEX IF EXISTS idx_sprints_one_active;
+ALTER TABLE sprints DROP CONSTRAINT IF EXISTS sprints_completed_after_started;
+
+CREATE OR REPLACE FUNCTION notify_tasks_changed(p_project_id UUID, p_task_ids UUID[])
+RETURNS VOID AS $$
+DECLARE
+  task_ids JSONB;
+  event_seq BIGINT;
+BEGIN
+  IF cardinality(p_task_ids) = 0 THEN
+    RETURN;
+  END IF;
+
+  SELECT jsonb_agg(id::text) INTO task_ids FROM unnest(p_task_ids) AS id;
+
+  event_seq := record_realtime_event(p_project_id, 'cache_invalidate', jsonb_build_object(
+    'resource', 'task',
+    'ids', task_ids,
+    'action', 'BULK',
+    'project_id', p_project_id::text,
+    'timestamp', NOW()
+  ));
+
+  PERFORM pg_notify('cache_invalidate', jsonb_build_object(
+    'resource', 'task',
+    'ids', task_ids,
+    'action', 'BULK',
+    'projectId', p_project_id::text,
+    'timestamp', NOW(),
+    'seq', event_seq
+  )::text);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,58 @@
+-- Migration: Sprint lifecycle
+-- A sprint is planned (not started), active (started, not completed) or
+-- completed, and only moves forward. A project has at most one active sprint.
+-- Existing rows are brought in line first: a completed sprint counts as
+-- started, and where a project has several active sprints only the one that
+-- starts last stays active; the others go back to planned.
+
+UPDATE sprints SET is_started = true WHERE is_completed AND NOT is_started;
+
+UPDATE sprints s
+SET is_started = false, updated_at = now()
+WHERE s.is_started AND NOT s.is_completed AND s.deleted_at IS NULL
+  AND EXISTS (
+      SELECT 1 FROM sprints o
+      WHERE o.project_id = s.project_id AND o.id <> s.id
+        AND o.is_started AND NOT o.is_completed AND o.deleted_at IS NULL
+        AND (o.start_date, o.created_at, o.id) > (s.start_date, s.created_at, s.id)
+  );
+
+ALTER TABLE sprints DROP CONSTRAINT IF EXISTS sprints_completed_after_started;
+ALTER TABLE sprints ADD CONSTRAINT sprints_completed_after_started
+  CHECK (is_started OR NOT is_completed);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sprints_one_active
+  ON sprints (project_id)
+  WHERE is_started AND NOT is_completed AND deleted_at IS NULL;
+
+-- Completing a sprint announces its carried-over tasks through
+-- notify_tasks_changed (025), and a sprint can hold more tasks than fit in a
+-- NOTIFY payload. Past 100 tasks the event leaves out "ids", telling clients
+-- to reload all of the project's tasks.
+CREATE OR REPLACE FUNCTION notify_tasks_changed(p_project_id UUID, p_task_ids UUID[])
+RETURNS VOID AS $$
+DECLARE
+  data JSONB;
+  event_seq BIGINT;
+BEGIN
+  IF cardinality(p_task_ids) = 0 THEN
+    RETURN;
+  END IF;
+
+  data := jsonb_build_object(
+    'resource', 'task',
+    'action', 'BULK',
+    'project_id', p_project_id::text,
+    'timestamp', NOW()
+  );
+  IF cardinality(p_task_ids) <= 100 THEN
+    data := data || jsonb_build_object('ids', (SELECT jsonb_agg(id::text) FROM unnest(p_task_ids) AS id));
+  END IF;
+
+  event_seq := record_realtime_event(p_project_id, 'cache_invalidate', data);
+
+  PERFORM pg_notify('cache_invalidate', (
+    data - 'project_id' || jsonb_build_object('projectId', p_project_id::text, 'seq', event_seq)
+  )::text);
+END;
+$$ LANGUAGE plpgsql;
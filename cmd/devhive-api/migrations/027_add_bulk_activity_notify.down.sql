@@ -0,0 +1,8 @@
+-- Revert 027: notify every activity entry individually again
+
+DROP TRIGGER IF EXISTS activity_events_notify ON activity_events;
+CREATE TRIGGER activity_events_notify
+  AFTER INSERT ON activity_events
+  FOR EACH ROW EXECUTE FUNCTION notify_activity_event();
+
+DROP FUNCTION IF EXISTS notify_activity_batch(UUID, UUID[]);
@@ -0,0 +1,40 @@
+-- Migration: One realtime event for a batch of activity entries
+-- A transaction that sets devhive.defer_activity_notify holds back the
+-- per-row activity notification; it then calls notify_activity_batch once
+-- with the IDs of the entries it wrote. Clients get a single activity
+-- message with action BULK and the entry IDs in "ids", and read the entries
+-- from the activity feed.
+
+DROP TRIGGER IF EXISTS activity_events_notify ON activity_events;
+CREATE TRIGGER activity_events_notify
+  AFTER INSERT ON activity_events
+  FOR EACH ROW
+  WHEN (current_setting('devhive.defer_activity_notify', true) IS DISTINCT FROM 'on')
+  EXECUTE FUNCTION notify_activity_event();
+
+-- Record and send one activity message for many entries of a project. Past
+-- 100 entries "ids" is left out to stay under the NOTIFY limit.
+CREATE OR REPLACE FUNCTION notify_activity_batch(p_project_id UUID, p_event_ids UUID[])
+RETURNS VOID AS $$
+DECLARE
+  payload JSONB;
+  event_seq BIGINT;
+BEGIN
+  IF cardinality(p_event_ids) = 0 THEN
+    RETURN;
+  END IF;
+
+  payload := jsonb_build_object(
+    'projectId', p_project_id::text,
+    'action', 'BULK',
+    'count', cardinality(p_event_ids),
+    'createdAt', NOW()
+  );
+  IF cardinality(p_event_ids) <= 100 THEN
+    payload := payload || jsonb_build_object('ids', (SELECT jsonb_agg(id::text) FROM unnest(p_event_ids) AS id));
+  END IF;
+
+  event_seq := record_realtime_event(p_project_id, 'activity', payload);
+  PERFORM pg_notify('activity', (payload || jsonb_build_object('seq', event_seq))::text);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,10 @@
+// Package migrations embeds the database migrations, so binaries do not
+// depend on the directory they are started from
+package migrations
+
+import "embed"
+
+// FS holds NNN_name.sql migrations and their NNN_name.down.sql rollbacks
+//
+//go:embed *.sql
+var FS embed.FS
@@ -7,6 +7,7 @@ import (
 	"log"
 	"net/http"
 
+	"devhive-backend/cmd/devhive-api/migrations"
 	"devhive-backend/db"
 	"devhive-backend/internal/broadcast"
 	"devhive-backend/internal/config"
@@ -37,7 +38,7 @@ func init() {
 	}
 
 	// Run database migrations
-	if err := db.RunMigrations(database); err != nil {
+	if err := db.RunMigrations(database, migrations.FS); err != nil {
 		log.Printf("Warning: Migration failed: %v", err)
 	}
 
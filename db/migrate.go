@@ -1,120 +1,22 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"io/ioutil"
+	"io/fs"
 	"log"
-	"os"
-	"path/filepath"
-	"sort"
-	"strings"
 )
 
-// RunMigrations runs database migrations using SQL files
-func RunMigrations(db *sql.DB) error {
+// RunMigrations applies the pending migrations in fsys, see Migrator.Up
+func RunMigrations(db *sql.DB, fsys fs.FS) error {
 	log.Println("Starting database migrations...")
 
-	// Create migrations tracking table if it doesn't exist
-	_, err := db.Exec(`
-		CREATE TABLE IF NOT EXISTS schema_migrations (
-			version VARCHAR(255) PRIMARY KEY,
-			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
-		)
-	`)
+	migrator, err := NewMigrator(db, fsys)
 	if err != nil {
-		return fmt.Errorf("failed to create migrations table: %w", err)
-	}
-
-	// Read all migration files
-	// Try multiple possible paths for migrations directory
-	migrationsDirs := []string{
-		"cmd/devhive-api/migrations",
-		"./cmd/devhive-api/migrations",
-		"migrations",
-		"./migrations",
-	}
-
-	var migrationsDir string
-	var entries []os.FileInfo
-	for _, dir := range migrationsDirs {
-		var err error
-		entries, err = ioutil.ReadDir(dir)
-		if err == nil {
-			migrationsDir = dir
-			break
-		}
-	}
-
-	if migrationsDir == "" {
-		return fmt.Errorf("could not find migrations directory (tried: %v)", migrationsDirs)
+		return err
 	}
-
-	// Filter and sort migration files
-	var migrationFiles []string
-	for _, entry := range entries {
-		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".sql") {
-			migrationFiles = append(migrationFiles, entry.Name())
-		}
-	}
-	sort.Strings(migrationFiles)
-
-	// Apply each migration
-	for _, filename := range migrationFiles {
-		// Check if migration has already been applied
-		var count int
-		err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = $1", filename).Scan(&count)
-		if err != nil {
-			return fmt.Errorf("failed to check migration status: %w", err)
-		}
-
-		if count > 0 {
-			log.Printf("Migration %s already applied, skipping", filename)
-			continue
-		}
-
-		// Read migration file
-		migrationPath := filepath.Join(migrationsDir, filename)
-		migrationSQL, err := ioutil.ReadFile(migrationPath)
-		if err != nil {
-			return fmt.Errorf("failed to read migration %s: %w", filename, err)
-		}
-
-		// Execute migration
-		log.Printf("Applying migration: %s", filename)
-		_, err = db.Exec(string(migrationSQL))
-		if err != nil {
-			// Check if error is due to already existing objects (idempotency)
-			errStr := err.Error()
-			if strings.Contains(errStr, "already exists") || 
-			   strings.Contains(errStr, "duplicate key") ||
-			   (strings.Contains(errStr, "relation") && strings.Contains(errStr, "already exists")) ||
-			   (strings.Contains(errStr, "does not exist") && strings.Contains(errStr, "DROP")) {
-				log.Printf("Migration %s: Some objects already exist or don't exist (idempotent), continuing...", filename)
-				// Continue - this is expected for idempotent migrations
-			} else {
-				log.Printf("❌ Migration %s failed with error: %v", filename, err)
-				return fmt.Errorf("failed to execute migration %s: %w", filename, err)
-			}
-		} else {
-			log.Printf("✅ Migration %s executed successfully", filename)
-		}
-
-		// Record migration as applied (use ON CONFLICT to handle idempotency)
-		_, err = db.Exec(`
-			INSERT INTO schema_migrations (version) 
-			VALUES ($1) 
-			ON CONFLICT (version) DO NOTHING
-		`, filename)
-		if err != nil {
-			return fmt.Errorf("failed to record migration %s: %w", filename, err)
-		}
-
-		log.Printf("Successfully applied migration: %s", filename)
-	}
-
-	log.Println("Database migrations completed successfully")
-	return nil
+	return migrator.Up(context.Background())
 }
 
 // CreateIndexes creates additional database indexes for performance
@@ -0,0 +1,488 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationLockID is the PostgreSQL advisory lock held while migrating, so
+// replicas starting at the same time apply each migration once
+const migrationLockID int64 = 0x6465766869766500 // "devhive\x00"
+
+// migrationFile matches NNN_name.sql and NNN_name.down.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+?)(\.down)?\.sql$`)
+
+// ErrUnknownMigration is returned by Apply for a name that is not an up
+// migration of this build
+var ErrUnknownMigration = errors.New("unknown migration")
+
+// ErrMigrationApplied is returned by Apply for a migration that is already applied
+var ErrMigrationApplied = errors.New("migration already applied")
+
+// Migration is a numbered schema change. NNN_name.sql applies it and the
+// optional NNN_name.down.sql reverts it.
+type Migration struct {
+	Version  int
+	Name     string // File name of the up migration, its key in schema_migrations
+	Up       string
+	Down     string // Empty when the migration cannot be reverted
+	Checksum string // Hex SHA-256 of Up
+}
+
+// MigrationStatus is a migration's state in the database
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+	Modified  bool // Applied with a different checksum: the file was edited since
+	Missing   bool // Applied, but there is no file for it in this build
+}
+
+// appliedMigration is a row of schema_migrations
+type appliedMigration struct {
+	appliedAt time.Time
+	checksum  string // Empty for rows recorded before checksums were kept
+}
+
+// LoadMigrations reads the migrations in the root of fsys, ordered by
+// version. Files that do not start with a version number are ignored.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	downs := make(map[int]string)
+	for _, entry := range entries {
+		m := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.Atoi(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %s: %w", entry.Name(), err)
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		upName := m[1] + "_" + m[2] + ".sql"
+		if m[3] != "" {
+			if _, ok := downs[version]; ok {
+				return nil, fmt.Errorf("duplicate down migration for version %d: %s", version, entry.Name())
+			}
+			downs[version] = upName
+			mig := byVersion[version]
+			if mig == nil {
+				mig = &Migration{Version: version}
+				byVersion[version] = mig
+			}
+			mig.Down = string(content)
+			continue
+		}
+
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version}
+			byVersion[version] = mig
+		} else if mig.Name != "" {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, mig.Name, entry.Name())
+		}
+		sum := sha256.Sum256(content)
+		mig.Name = entry.Name()
+		mig.Up = string(content)
+		mig.Checksum = hex.EncodeToString(sum[:])
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, mig := range byVersion {
+		if mig.Name == "" {
+			return nil, fmt.Errorf("down migration for version %d has no up migration", version)
+		}
+		if name, ok := downs[version]; ok && name != mig.Name {
+			return nil, fmt.Errorf("down migration %s does not match %s", strings.TrimSuffix(name, ".sql")+".down.sql", mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations. Every operation holds a
+// PostgreSQL advisory lock, and each migration runs in its own transaction
+// together with its schema_migrations record.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+
+	// DryRun logs what would be done without touching the schema. Only the
+	// schema_migrations table is created if it is missing.
+	DryRun bool
+}
+
+// NewMigrator creates a migrator for the migrations in fsys
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in version order. It refuses to run
+// if an applied migration was edited since.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[string]appliedMigration) error {
+		if err := m.verify(ctx, conn, applied); err != nil {
+			return err
+		}
+
+		count := 0
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Name]; ok {
+				continue
+			}
+			if err := m.apply(ctx, conn, mig); err != nil {
+				return err
+			}
+			count++
+		}
+		log.Printf("Database migrations completed: %d applied", count)
+		return nil
+	})
+}
+
+// Apply applies the single migration with the given file name, for running
+// a migration out of order. Like Up it refuses to run if an applied migration
+// was edited since.
+func (m *Migrator) Apply(ctx context.Context, name string) error {
+	var target *Migration
+	for i := range m.migrations {
+		if m.migrations[i].Name == name {
+			target = &m.migrations[i]
+			break
+		}
+	}
+	if target == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownMigration, name)
+	}
+
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[string]appliedMigration) error {
+		if err := m.verify(ctx, conn, applied); err != nil {
+			return err
+		}
+		if _, ok := applied[target.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrMigrationApplied, target.Name)
+		}
+		return m.apply(ctx, conn, *target)
+	})
+}
+
+// Down reverts the n most recently applied migrations, newest first. Nothing
+// is reverted if one of them has no down migration.
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[string]appliedMigration) error {
+		if err := m.verify(ctx, conn, applied); err != nil {
+			return err
+		}
+		targets, err := m.lastApplied(applied, n)
+		if err != nil {
+			return err
+		}
+		for _, mig := range targets {
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Redo reverts the most recently applied migration and applies it again
+func (m *Migrator) Redo(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[string]appliedMigration) error {
+		if err := m.verify(ctx, conn, applied); err != nil {
+			return err
+		}
+		targets, err := m.lastApplied(applied, 1)
+		if err != nil {
+			return err
+		}
+		if err := m.revert(ctx, conn, targets[0]); err != nil {
+			return err
+		}
+		return m.apply(ctx, conn, targets[0])
+	})
+}
+
+// Force records the migrations up to version as applied with their current
+// checksums and forgets those after it, without running any SQL. It is for
+// repairing the record after a schema was changed by hand, or accepting an
+// edited migration.
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	return m.withLock(ctx, func(conn *sql.Conn, applied map[string]appliedMigration) error {
+		known := make(map[string]bool)
+		for _, mig := range m.migrations {
+			known[mig.Name] = true
+			rec, ok := applied[mig.Name]
+			switch {
+			case mig.Version <= version && (!ok || rec.checksum != mig.Checksum):
+				log.Printf("Force: recording %s as applied", mig.Name)
+				if !m.DryRun {
+					if err := recordMigration(ctx, conn, mig); err != nil {
+						return err
+					}
+				}
+			case mig.Version > version && ok:
+				log.Printf("Force: recording %s as not applied", mig.Name)
+				if !m.DryRun {
+					if err := forgetMigration(ctx, conn, mig.Name); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		for name := range applied {
+			if v, ok := versionOf(name); !known[name] && ok && v > version {
+				log.Printf("Force: recording unknown migration %s as not applied", name)
+				if !m.DryRun {
+					if err := forgetMigration(ctx, conn, name); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Status reports every migration and whether it is applied, followed by
+// applied migrations this build does not know
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn, applied map[string]appliedMigration) error {
+		for _, mig := range m.migrations {
+			rec, ok := applied[mig.Name]
+			statuses = append(statuses, MigrationStatus{
+				Migration: mig,
+				Applied:   ok,
+				AppliedAt: rec.appliedAt,
+				Modified:  ok && rec.checksum != "" && rec.checksum != mig.Checksum,
+			})
+			delete(applied, mig.Name)
+		}
+		for name, rec := range applied {
+			version, _ := versionOf(name)
+			statuses = append(statuses, MigrationStatus{
+				Migration: Migration{Version: version, Name: name},
+				Applied:   true,
+				AppliedAt: rec.appliedAt,
+				Missing:   true,
+			})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// withLock runs fn on a connection holding the migration lock, with the
+// applied migrations read after the lock was taken
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn, applied map[string]appliedMigration) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a database connection: %w", err)
+	}
+	defer conn.Close()
+
+	// Session-level lock: blocks until any other migrator is done
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID); err != nil {
+			log.Printf("Failed to release migration lock: %v", err)
+		}
+	}()
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+		ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum TEXT;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at, COALESCE(checksum, '') FROM schema_migrations")
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[string]appliedMigration)
+	for rows.Next() {
+		var name string
+		var rec appliedMigration
+		if err := rows.Scan(&name, &rec.appliedAt, &rec.checksum); err != nil {
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[name] = rec
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	return fn(conn, applied)
+}
+
+// verify checks that applied migrations were not edited since. Rows recorded
+// before checksums were kept get the current checksum.
+func (m *Migrator) verify(ctx context.Context, conn *sql.Conn, applied map[string]appliedMigration) error {
+	var modified []string
+	for _, mig := range m.migrations {
+		rec, ok := applied[mig.Name]
+		switch {
+		case !ok:
+		case rec.checksum == "":
+			if !m.DryRun {
+				if err := recordMigration(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		case rec.checksum != mig.Checksum:
+			modified = append(modified, mig.Name)
+		}
+	}
+	if len(modified) > 0 {
+		return fmt.Errorf("applied migrations were edited: %s; restore them, or accept the edits with `migrate force`", strings.Join(modified, ", "))
+	}
+	return nil
+}
+
+// lastApplied returns the n most recently applied migrations, newest first,
+// checking that each can be reverted
+func (m *Migrator) lastApplied(applied map[string]appliedMigration, n int) ([]Migration, error) {
+	var targets []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(targets) < n; i-- {
+		if _, ok := applied[m.migrations[i].Name]; ok {
+			targets = append(targets, m.migrations[i])
+		}
+	}
+	if len(targets) < n {
+		return nil, fmt.Errorf("cannot revert %d migrations: only %d are applied", n, len(targets))
+	}
+	for _, mig := range targets {
+		if mig.Down == "" {
+			return nil, fmt.Errorf("migration %s has no down migration", mig.Name)
+		}
+	}
+	return targets, nil
+}
+
+// apply runs a migration and records it in one transaction
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if m.DryRun {
+		log.Printf("Would apply migration: %s", mig.Name)
+		return nil
+	}
+
+	log.Printf("Applying migration: %s", mig.Name)
+	start := time.Now()
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)
+			ON CONFLICT (version) DO UPDATE SET checksum = EXCLUDED.checksum, applied_at = now()
+		`, mig.Name, mig.Checksum)
+		return err
+	})
+	if err != nil {
+		log.Printf("❌ Migration %s failed with error: %v", mig.Name, err)
+		return fmt.Errorf("failed to apply migration %s: %w", mig.Name, err)
+	}
+	log.Printf("✅ Migration %s applied in %s", mig.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// revert runs a down migration and removes the record in one transaction
+func (m *Migrator) revert(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	if m.DryRun {
+		log.Printf("Would revert migration: %s", mig.Name)
+		return nil
+	}
+
+	log.Printf("Reverting migration: %s", mig.Name)
+	start := time.Now()
+	err := inTx(ctx, conn, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", mig.Name)
+		return err
+	})
+	if err != nil {
+		log.Printf("❌ Reverting %s failed with error: %v", mig.Name, err)
+		return fmt.Errorf("failed to revert migration %s: %w", mig.Name, err)
+	}
+	log.Printf("✅ Migration %s reverted in %s", mig.Name, time.Since(start).Round(time.Millisecond))
+	return nil
+}
+
+// inTx runs fn in a transaction on conn, committing if it succeeds
+func inTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordMigration marks a migration as applied with its current checksum
+func recordMigration(ctx context.Context, conn *sql.Conn, mig Migration) error {
+	_, err := conn.ExecContext(ctx, `
+		INSERT INTO schema_migrations (version, checksum) VALUES ($1, $2)
+		ON CONFLICT (version) DO UPDATE SET checksum = EXCLUDED.checksum
+	`, mig.Name, mig.Checksum)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", mig.Name, err)
+	}
+	return nil
+}
+
+// forgetMigration marks a migration as not applied
+func forgetMigration(ctx context.Context, conn *sql.Conn, name string) error {
+	if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", name); err != nil {
+		return fmt.Errorf("failed to forget migration %s: %w", name, err)
+	}
+	return nil
+}
+
+// versionOf parses the version of a migration file name
+func versionOf(name string) (int, bool) {
+	m := migrationFile.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	version, err := strconv.Atoi(m[1])
+	return version, err == nil
+}
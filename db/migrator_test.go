@@ -0,0 +1,94 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"devhive-backend/cmd/devhive-api/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_add_tasks.sql":      {Data: []byte("CREATE TABLE tasks ();")},
+		"002_add_tasks.down.sql": {Data: []byte("DROP TABLE tasks;")},
+		"001_init.sql":           {Data: []byte("CREATE TABLE users ();")},
+		"VERIFY.sql":             {Data: []byte("SELECT 1;")},
+		"README.md":              {Data: []byte("not a migration")},
+	}
+
+	migs, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migs) != 2 {
+		t.Fatalf("loaded %d migrations, want 2", len(migs))
+	}
+	if migs[0].Name != "001_init.sql" || migs[0].Down != "" {
+		t.Errorf("first migration = %+v, want 001_init.sql without a down migration", migs[0])
+	}
+	if migs[1].Version != 2 || migs[1].Down != "DROP TABLE tasks;" {
+		t.Errorf("second migration = %+v", migs[1])
+	}
+	if len(migs[0].Checksum) != 64 || migs[0].Checksum == migs[1].Checksum {
+		t.Errorf("checksums %q and %q", migs[0].Checksum, migs[1].Checksum)
+	}
+
+	edited := fstest.MapFS{"001_init.sql": {Data: []byte("CREATE TABLE users (id INT);")}}
+	again, err := LoadMigrations(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again[0].Checksum == migs[0].Checksum {
+		t.Error("editing a migration did not change its checksum")
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+		want string
+	}{
+		{"duplicate version", fstest.MapFS{
+			"001_a.sql": {Data: []byte("")},
+			"001_b.sql": {Data: []byte("")},
+		}, "duplicate migration version 1"},
+		{"down without up", fstest.MapFS{
+			"001_a.down.sql": {Data: []byte("")},
+		}, "has no up migration"},
+		{"mismatched down", fstest.MapFS{
+			"001_a.sql":      {Data: []byte("")},
+			"001_b.down.sql": {Data: []byte("")},
+		}, "does not match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations checks the shipped migrations load and that every
+// migration after the first reversible one can be reverted, so `down` is
+// never stuck in the middle
+func TestEmbeddedMigrations(t *testing.T) {
+	migs, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversible := false
+	for i, mig := range migs {
+		if mig.Version != i+1 {
+			t.Errorf("migration %s has version %d, want %d", mig.Name, mig.Version, i+1)
+		}
+		if mig.Down != "" {
+			reversible = true
+		} else if reversible {
+			t.Errorf("migration %s has no down migration", mig.Name)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package activity records the append-only audit log of project mutations:
+// who changed which resource, how, and a field-level before/after diff.
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+
+	"devhive-backend/internal/broadcast"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Resources that appear in the log
+const (
+	ResourceProject  = "project"
+	ResourceMember   = "member"
+	ResourceInvite   = "invite"
+	ResourceSprint   = "sprint"
+	ResourceTask     = "task"
+	ResourceLabel    = "label"
+	ResourceWorkflow = "workflow"
+	ResourceMessage  = "message"
+)
+
+// Actions that appear in the log
+const (
+	ActionCreated              = "created"
+	ActionUpdated              = "updated"
+	ActionDeleted              = "deleted"
+	ActionStatusChanged        = "status_changed"
+	ActionAssigned             = "assigned"
+	ActionJoined               = "joined"
+	ActionLeft                 = "left"
+	ActionRemoved              = "removed"
+	ActionRoleChanged          = "role_changed"
+	ActionOwnershipTransferred = "ownership_transferred"
+	ActionAccepted             = "accepted"
+	ActionRevoked              = "revoked"
+	ActionResent               = "resent"
+	ActionRestored             = "restored"
+)
+
+// ignoredFields are left out of diffs: timestamps change on every write and
+// the user display columns joined into many rows are not part of the resource
+var ignoredFields = map[string]bool{
+	"createdAt":         true,
+	"updatedAt":         true,
+	"ownerUsername":     true,
+	"ownerEmail":        true,
+	"ownerFirstName":    true,
+	"ownerLastName":     true,
+	"ownerAvatarUrl":    true,
+	"assigneeUsername":  true,
+	"assigneeFirstName": true,
+	"assigneeLastName":  true,
+	"senderUsername":    true,
+	"senderFirstName":   true,
+	"senderLastName":    true,
+	"senderAvatarUrl":   true,
+}
+
+// Store is the subset of repo.Queries used to write events
+type Store interface {
+	CreateActivityEvent(ctx context.Context, arg repo.CreateActivityEventParams) (repo.ActivityEvent, error)
+}
+
+// Event describes one mutation. Before and After are any JSON-encodable
+// values (usually repo rows); either is nil for creations and deletions.
+type Event struct {
+	ProjectID  uuid.UUID
+	ActorID    uuid.UUID
+	Resource   string
+	ResourceID uuid.UUID
+	Action     string
+	Before     any
+	After      any
+}
+
+// Change is the before and after value of one field
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// BatchStore is the subset of repo.Queries used to write a batch of events
+type BatchStore interface {
+	Store
+	DeferActivityNotifications(ctx context.Context) error
+	NotifyActivityBatch(ctx context.Context, arg repo.NotifyActivityBatchParams) error
+}
+
+// MaxAnnouncedIDs is how many event IDs a batch announcement lists; larger
+// batches only report their count, to stay under the NOTIFY payload limit
+const MaxAnnouncedIDs = 100
+
+// Record appends an event to the log. Failures are logged rather than
+// returned so that auditing never fails a mutation that already succeeded.
+func Record(ctx context.Context, store Store, ev Event) {
+	event, err := store.CreateActivityEvent(ctx, newEventParams(ev))
+	if err != nil {
+		log.Printf("activity: record %s %s on project %s: %v", ev.Resource, ev.Action, ev.ProjectID, err)
+		return
+	}
+
+	// The in-process hub is fed by the activity NOTIFY trigger; the Lambda
+	// broadcaster has no listener and is sent the event directly
+	broadcast.Send(ctx, ev.ProjectID.String(), broadcast.EventActivity, map[string]any{
+		"id":         event.ID,
+		"projectId":  event.ProjectID,
+		"actorId":    event.ActorID,
+		"resource":   event.Resource,
+		"resourceId": event.ResourceID,
+		"action":     event.Action,
+		"changes":    json.RawMessage(event.Changes),
+		"createdAt":  event.CreatedAt,
+	})
+}
+
+// RecordBatch appends events of one project from inside the caller's
+// transaction, so they commit or roll back with the mutations they describe.
+// Realtime clients get a single BULK activity message for the batch instead
+// of one per event. Errors are returned since they abort the transaction;
+// call AnnounceBatch with the returned IDs once it has committed.
+func RecordBatch(ctx context.Context, store BatchStore, projectID uuid.UUID, events []Event) ([]uuid.UUID, error) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	if err := store.DeferActivityNotifications(ctx); err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(events))
+	for i, ev := range events {
+		event, err := store.CreateActivityEvent(ctx, newEventParams(ev))
+		if err != nil {
+			return nil, fmt.Errorf("activity: record %s %s: %w", ev.Resource, ev.Action, err)
+		}
+		ids[i] = event.ID
+	}
+	if err := store.NotifyActivityBatch(ctx, repo.NotifyActivityBatchParams{ProjectID: projectID, EventIds: ids}); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// AnnounceBatch sends the BULK activity message of a committed RecordBatch
+// to the Lambda broadcaster, which has no NOTIFY listener
+func AnnounceBatch(ctx context.Context, projectID uuid.UUID, ids []uuid.UUID) {
+	if len(ids) == 0 {
+		return
+	}
+	msg := map[string]any{
+		"projectId": projectID,
+		"action":    "BULK",
+		"count":     len(ids),
+	}
+	if len(ids) <= MaxAnnouncedIDs {
+		msg["ids"] = ids
+	}
+	broadcast.Send(ctx, projectID.String(), broadcast.EventActivity, msg)
+}
+
+// newEventParams diffs an event into the row to insert
+func newEventParams(ev Event) repo.CreateActivityEventParams {
+	changes, err := Diff(ev.Before, ev.After)
+	if err != nil {
+		log.Printf("activity: diff %s %s: %v", ev.Resource, ev.Action, err)
+		changes = map[string]Change{}
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		log.Printf("activity: encode %s %s: %v", ev.Resource, ev.Action, err)
+		encoded = []byte("{}")
+	}
+	return repo.CreateActivityEventParams{
+		ProjectID:  ev.ProjectID,
+		ActorID:    optionalUUID(ev.ActorID),
+		Resource:   ev.Resource,
+		ResourceID: optionalUUID(ev.ResourceID),
+		Action:     ev.Action,
+		Changes:    encoded,
+	}
+}
+
+// Diff compares the top-level JSON fields of before and after and returns
+// the fields that differ
+func Diff(before, after any) (map[string]Change, error) {
+	from, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for key, value := range from {
+		if !reflect.DeepEqual(value, to[key]) {
+			changes[key] = Change{From: value, To: to[key]}
+		}
+	}
+	for key, value := range to {
+		if _, seen := from[key]; !seen && value != nil {
+			changes[key] = Change{From: nil, To: value}
+		}
+	}
+	return changes, nil
+}
+
+// fields decodes v's JSON object form, dropping ignored fields
+func fields(v any) (map[string]any, error) {
+	m := make(map[string]any)
+	if v == nil {
+		return m, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	for key := range m {
+		if ignoredFields[key] {
+			delete(m, key)
+		}
+	}
+	return m, nil
+}
+
+func optionalUUID(id uuid.UUID) pgtype.UUID {
+	return pgtype.UUID{Bytes: id, Valid: id != uuid.Nil}
+}
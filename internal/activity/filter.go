@@ -0,0 +1,100 @@
+package activity
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ErrInvalidFilter is returned for a malformed filter value
+var ErrInvalidFilter = errors.New("activity: invalid filter")
+
+// Filter narrows a project's activity feed. The zero value lists every
+// event, newest first.
+type Filter struct {
+	ActorID    pgtype.UUID
+	Resource   *string
+	ResourceID pgtype.UUID
+	Action     *string
+	Since      pgtype.Timestamptz
+	Until      pgtype.Timestamptz
+}
+
+// ParseQuery reads a filter from URL query parameters:
+//
+//	actor       user ID of the acting user
+//	resource    project, member, invite, sprint, task, label, workflow or message
+//	resourceId  ID of the changed resource
+//	action      created, updated, deleted, status_changed, ...
+//	since       earliest event time (RFC 3339), inclusive
+//	until       latest event time (RFC 3339), exclusive
+func ParseQuery(values url.Values) (Filter, error) {
+	var f Filter
+	var err error
+
+	if f.ActorID, err = parseID(values.Get("actor"), "actor"); err != nil {
+		return Filter{}, err
+	}
+	if f.ResourceID, err = parseID(values.Get("resourceId"), "resourceId"); err != nil {
+		return Filter{}, err
+	}
+	if s := values.Get("resource"); s != "" {
+		f.Resource = &s
+	}
+	if s := values.Get("action"); s != "" {
+		f.Action = &s
+	}
+	if f.Since, err = parseTime(values.Get("since"), "since"); err != nil {
+		return Filter{}, err
+	}
+	if f.Until, err = parseTime(values.Get("until"), "until"); err != nil {
+		return Filter{}, err
+	}
+	return f, nil
+}
+
+// Params builds the query arguments for one page of the project's feed
+func (f Filter) Params(projectID uuid.UUID, page pagination.Page) repo.ListActivityEventsParams {
+	cursorID, cursorCreatedAt := page.Keyset()
+	return repo.ListActivityEventsParams{
+		ProjectID:       projectID,
+		ActorID:         f.ActorID,
+		Resource:        f.Resource,
+		ResourceID:      f.ResourceID,
+		Action:          f.Action,
+		Since:           f.Since,
+		Until:           f.Until,
+		CursorID:        cursorID,
+		CursorCreatedAt: cursorCreatedAt,
+		Limit:           page.FetchLimit(),
+	}
+}
+
+func parseID(s, name string) (pgtype.UUID, error) {
+	if s == "" {
+		return pgtype.UUID{}, nil
+	}
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return pgtype.UUID{}, fmt.Errorf("%w: %s must be a UUID", ErrInvalidFilter, name)
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
+
+func parseTime(s, name string) (pgtype.Timestamptz, error) {
+	if s == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return pgtype.Timestamptz{}, fmt.Errorf("%w: %s must be an RFC 3339 time", ErrInvalidFilter, name)
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
@@ -0,0 +1,30 @@
+-- name: CreateActivityEvent :one
+INSERT INTO activity_events (project_id, actor_id, resource, resource_id, action, changes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, project_id, actor_id, resource, resource_id, action, changes, created_at;
+
+-- name: DeferActivityNotifications :exec
+-- Holds back per-entry activity notifications until the end of the
+-- transaction; NotifyActivityBatch then sends one for all of them
+SELECT set_config('devhive.defer_activity_notify', 'on', true);
+
+-- name: NotifyActivityBatch :exec
+SELECT notify_activity_batch(sqlc.arg(project_id)::uuid, sqlc.arg(event_ids)::uuid[]);
+
+-- name: ListActivityEvents :many
+-- Every filter is optional; pages are keyed on (created_at, id), newest first.
+SELECT a.id, a.project_id, a.actor_id, a.resource, a.resource_id, a.action, a.changes, a.created_at,
+       u.username AS actor_username, u.first_name AS actor_first_name, u.last_name AS actor_last_name
+FROM activity_events a
+LEFT JOIN users u ON u.id = a.actor_id
+WHERE a.project_id = sqlc.arg(project_id)
+  AND (sqlc.narg(actor_id)::uuid IS NULL OR a.actor_id = sqlc.narg(actor_id)::uuid)
+  AND (sqlc.narg(resource)::text IS NULL OR a.resource = sqlc.narg(resource)::text)
+  AND (sqlc.narg(resource_id)::uuid IS NULL OR a.resource_id = sqlc.narg(resource_id)::uuid)
+  AND (sqlc.narg(action)::text IS NULL OR a.action = sqlc.narg(action)::text)
+  AND (sqlc.narg(since)::timestamptz IS NULL OR a.created_at >= sqlc.narg(since)::timestamptz)
+  AND (sqlc.narg(until)::timestamptz IS NULL OR a.created_at < sqlc.narg(until)::timestamptz)
+  AND (sqlc.narg(cursor_id)::uuid IS NULL
+       OR (a.created_at, a.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT sqlc.arg('limit');
@@ -1,20 +1,46 @@
 -- name: CreatePasswordReset :one
-INSERT INTO password_resets (user_id, reset_token, expires_at)
+INSERT INTO password_resets (user_id, token_hash, expires_at)
 VALUES ($1, $2, $3)
-RETURNING id, user_id, reset_token, expires_at, created_at;
+RETURNING id, user_id, token_hash, expires_at, used_at, created_at;
 
--- name: GetPasswordResetByToken :one
-SELECT pr.id, pr.user_id, pr.reset_token, pr.expires_at, pr.created_at,
-       u.username, u.email, u.first_name, u.last_name
-FROM password_resets pr
-JOIN users u ON pr.user_id = u.id
-WHERE pr.reset_token = $1;
+-- name: CountActivePasswordResets :one
+SELECT COUNT(*) FROM password_resets
+WHERE user_id = $1 AND used_at IS NULL AND expires_at > now();
 
--- name: DeletePasswordReset :exec
-DELETE FROM password_resets WHERE reset_token = $1;
+-- name: ConsumePasswordReset :one
+UPDATE password_resets
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING id, user_id, token_hash, expires_at, used_at, created_at;
+
+-- name: InvalidateUserPasswordResets :exec
+UPDATE password_resets
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL;
 
 -- name: DeleteExpiredPasswordResets :exec
-DELETE FROM password_resets WHERE expires_at < now();
+DELETE FROM password_resets WHERE expires_at < now() OR used_at IS NOT NULL;
+
+-- Email Verification Queries
+-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (user_id, email, token_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at;
+
+-- name: CountActiveEmailVerifications :one
+SELECT COUNT(*) FROM email_verifications
+WHERE user_id = $1 AND used_at IS NULL AND expires_at > now();
+
+-- name: ConsumeEmailVerification :one
+UPDATE email_verifications
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at;
+
+-- name: InvalidateUserEmailVerifications :exec
+UPDATE email_verifications
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL;
 
 -- Refresh Token Queries
 -- name: CreateRefreshToken :one
@@ -64,3 +90,15 @@ UPDATE refresh_tokens
 SET google_access_token = $2, google_token_expiry = $3
 WHERE token = $1;
 
+
+-- System Admin Queries
+-- name: IsSystemAdmin :one
+SELECT EXISTS (
+    SELECT 1 FROM system_admins sa
+    JOIN users u ON u.id = sa.user_id
+    WHERE sa.user_id = $1 AND u.active
+) AS is_admin;
+
+-- name: CreateAdminAuditEntry :exec
+INSERT INTO admin_audit_log (user_id, method, path, status, request_body, remote_addr)
+VALUES ($1, $2, $3, $4, $5, $6);
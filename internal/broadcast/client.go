@@ -28,6 +28,32 @@ type BroadcastEvent struct {
 
 var defaultClient *Client
 
+// Publisher delivers events to the WebSocket hub of a long-running server
+type Publisher interface {
+	PublishToProject(ctx context.Context, projectID, eventType string, data interface{}) error
+}
+
+var hubPublisher Publisher
+
+// SetPublisher makes Send and SendExcluding also publish to a WebSocket hub,
+// for servers that hold WebSocket connections themselves. Activity events
+// are left to the hub's NOTIFY listener.
+func SetPublisher(p Publisher) {
+	hubPublisher = p
+}
+
+// publish hands the event to the hub, if there is one. Activity events are
+// skipped: the hub already gets them, sequenced, from the activity NOTIFY
+// trigger.
+func publish(ctx context.Context, projectID, eventType string, data interface{}) {
+	if hubPublisher == nil || eventType == EventActivity {
+		return
+	}
+	if err := hubPublisher.PublishToProject(ctx, projectID, eventType, data); err != nil {
+		log.Printf("Failed to publish %s to WebSocket hub: %v", eventType, err)
+	}
+}
+
 // Init initializes the broadcast client (call during Lambda init)
 func Init() {
 	functionName := os.Getenv("BROADCASTER_FUNCTION_NAME")
@@ -54,14 +80,18 @@ func Init() {
 
 // Send broadcasts a message to all connections subscribed to a project
 func Send(ctx context.Context, projectID, eventType string, data interface{}) error {
+	publish(ctx, projectID, eventType, data)
 	if defaultClient == nil || !defaultClient.enabled {
 		return nil // Broadcasting disabled, silently skip
 	}
 	return defaultClient.Send(ctx, projectID, eventType, data, "")
 }
 
-// SendExcluding broadcasts a message to all connections except the specified one
+// SendExcluding broadcasts a message to all connections except the specified one.
+// The exclusion only applies to API Gateway connections; hub clients all
+// receive the message.
 func SendExcluding(ctx context.Context, projectID, eventType string, data interface{}, excludeConnID string) error {
+	publish(ctx, projectID, eventType, data)
 	if defaultClient == nil || !defaultClient.enabled {
 		return nil
 	}
@@ -103,15 +133,24 @@ func (c *Client) Send(ctx context.Context, projectID, eventType string, data int
 
 // Common event types
 const (
-	EventTaskCreated     = "task_created"
-	EventTaskUpdated     = "task_updated"
-	EventTaskDeleted     = "task_deleted"
-	EventSprintCreated   = "sprint_created"
-	EventSprintUpdated   = "sprint_updated"
-	EventSprintDeleted   = "sprint_deleted"
-	EventMessageCreated  = "message_created"
-	EventProjectUpdated  = "project_updated"
-	EventMemberAdded     = "member_added"
-	EventMemberRemoved   = "member_removed"
-	EventCacheInvalidate = "cache_invalidate"
+	EventTaskCreated          = "task_created"
+	EventTaskUpdated          = "task_updated"
+	EventTaskDeleted          = "task_deleted"
+	EventTaskRestored         = "task_restored"
+	EventTasksBulk            = "tasks_bulk"
+	EventWorkflowUpdated      = "workflow_updated"
+	EventSprintCreated        = "sprint_created"
+	EventSprintUpdated        = "sprint_updated"
+	EventSprintDeleted        = "sprint_deleted"
+	EventSprintRestored       = "sprint_restored"
+	EventMessageCreated       = "message_created"
+	EventProjectUpdated       = "project_updated"
+	EventProjectDeleted       = "project_deleted"
+	EventProjectRestored      = "project_restored"
+	EventMemberAdded          = "member_added"
+	EventMemberRemoved        = "member_removed"
+	EventMemberRoleChanged    = "member_role_changed"
+	EventOwnershipTransferred = "ownership_transferred"
+	EventCacheInvalidate      = "cache_invalidate"
+	EventActivity             = "activity"
 )
@@ -11,14 +11,22 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Port          string
-	GRPCPort      string
-	DatabaseURL   string
-	JWT           JWTConfig
-	CORS          CORSConfig
-	Mail          MailConfig
-	GoogleOAuth   GoogleOAuthConfig
-	AdminPassword string
+	Port              string
+	GRPCPort          string
+	DatabaseURL       string
+	JWT               JWTConfig
+	CORS              CORSConfig
+	Mail              MailConfig
+	GoogleOAuth       GoogleOAuthConfig
+	AdminPassword     string
+	Admin             AdminConfig
+	FrontendURL       string
+	PasswordReset     PasswordResetConfig
+	Invites           InviteConfig
+	EmailVerification EmailVerificationConfig
+	Trash             TrashConfig
+	Realtime          RealtimeConfig
+	Idempotency       IdempotencyConfig
 }
 
 // JWTConfig holds JWT-related configuration
@@ -38,10 +46,76 @@ type CORSConfig struct {
 	AllowCredentials bool
 }
 
-// MailConfig holds mail service configuration (Resend)
+// MailConfig holds mail service configuration
 type MailConfig struct {
-	APIKey    string
-	FromEmail string
+	Provider   string // "resend", "smtp" or "capture" (default: resend when an API key is set; capture must be set explicitly)
+	APIKey     string // Resend API key
+	FromEmail  string
+	FromName   string
+	SMTP       SMTPConfig
+	CaptureDir string // Optional directory for captured .eml files
+	SendLimit  int    // Messages a system admin may send through /mail/send per hour (default: 20)
+}
+
+// SMTPConfig holds SMTP relay configuration
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+}
+
+// From returns the formatted sender address
+func (m MailConfig) From() string {
+	if m.FromName == "" {
+		return m.FromEmail
+	}
+	return m.FromName + " <" + m.FromEmail + ">"
+}
+
+// PasswordResetConfig holds password reset token configuration
+type PasswordResetConfig struct {
+	TokenTTL        time.Duration // How long a reset link stays valid (default: 1 hour)
+	MaxActiveTokens int           // Maximum unused, unexpired tokens per user (default: 3)
+	Path            string        // Frontend path that handles the reset link (default: /reset-password)
+}
+
+// InviteConfig holds project invite configuration
+type InviteConfig struct {
+	Path     string        // Frontend path that handles invite links (default: /invite)
+	EmailTTL time.Duration // Default expiration for email invites (default: 7 days)
+}
+
+// EmailVerificationConfig holds email verification configuration
+type EmailVerificationConfig struct {
+	Required        bool          // Require a verified email for login and invite acceptance (default: false)
+	TokenTTL        time.Duration // How long a verification link stays valid (default: 24 hours)
+	MaxActiveTokens int           // Maximum unused, unexpired tokens per user (default: 3)
+	Path            string        // Frontend path that handles the verification link (default: /verify-email)
+}
+
+// TrashConfig holds soft-delete retention configuration
+type TrashConfig struct {
+	Retention     time.Duration // How long deleted projects, sprints and tasks stay restorable (default: 30 days)
+	PurgeInterval time.Duration // How often the purge job runs (default: 1 hour)
+}
+
+// IdempotencyConfig holds Idempotency-Key configuration
+type IdempotencyConfig struct {
+	TTL           time.Duration // How long a key and its stored response are kept (default: 24 hours)
+	PurgeInterval time.Duration // How often expired keys are deleted (default: 1 hour)
+	LockLease     time.Duration // How long a running request holds its key before a retry may take it over (default: 60 seconds)
+}
+
+// RealtimeConfig holds WebSocket hub configuration
+type RealtimeConfig struct {
+	Transport string // "postgres" fans hub events out to every API instance through NOTIFY; "local" keeps them in-process (default: postgres)
+}
+
+// AdminConfig holds system admin configuration
+type AdminConfig struct {
+	ReauthWindow     time.Duration // How recently an admin must have entered their password (default: 10 minutes)
+	AllowDestructive bool          // Enables the database reset and deploy endpoints (default: false)
 }
 
 // GoogleOAuthConfig holds Google OAuth 2.0 configuration
@@ -75,9 +149,51 @@ func Load() (*Config, error) {
 			AllowCredentials: getEnvAsBool("CORS_ALLOW_CREDENTIALS", true),
 		},
 		AdminPassword: getEnv("ADMIN_CERTIFICATES_PASSWORD", "jtAppmine2021"),
+		Admin: AdminConfig{
+			ReauthWindow:     time.Duration(getEnvAsInt("ADMIN_REAUTH_WINDOW_MINUTES", 10)) * time.Minute,
+			AllowDestructive: getEnvAsBool("ADMIN_ALLOW_DESTRUCTIVE", false),
+		},
+		FrontendURL:   strings.TrimRight(getEnv("FRONTEND_URL", "https://devhive.it.com"), "/"),
+		PasswordReset: PasswordResetConfig{
+			TokenTTL:        time.Duration(getEnvAsInt("PASSWORD_RESET_TTL_MINUTES", 60)) * time.Minute,
+			MaxActiveTokens: getEnvAsInt("PASSWORD_RESET_MAX_ACTIVE", 3),
+			Path:            getEnv("PASSWORD_RESET_PATH", "/reset-password"),
+		},
+		Invites: InviteConfig{
+			Path:     getEnv("INVITE_PATH", "/invite"),
+			EmailTTL: time.Duration(getEnvAsInt("INVITE_EMAIL_EXPIRATION_HOURS", 168)) * time.Hour,
+		},
+		EmailVerification: EmailVerificationConfig{
+			Required:        getEnvAsBool("REQUIRE_VERIFIED_EMAIL", false),
+			TokenTTL:        time.Duration(getEnvAsInt("EMAIL_VERIFICATION_TTL_HOURS", 24)) * time.Hour,
+			MaxActiveTokens: getEnvAsInt("EMAIL_VERIFICATION_MAX_ACTIVE", 3),
+			Path:            getEnv("EMAIL_VERIFICATION_PATH", "/verify-email"),
+		},
+		Trash: TrashConfig{
+			Retention:     time.Duration(getEnvAsInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			PurgeInterval: time.Duration(getEnvAsInt("TRASH_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+		},
+		Realtime: RealtimeConfig{
+			Transport: getEnv("REALTIME_TRANSPORT", "postgres"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL:           time.Duration(getEnvAsInt("IDEMPOTENCY_KEY_TTL_HOURS", 24)) * time.Hour,
+			PurgeInterval: time.Duration(getEnvAsInt("IDEMPOTENCY_PURGE_INTERVAL_MINUTES", 60)) * time.Minute,
+			LockLease:     time.Duration(getEnvAsInt("IDEMPOTENCY_LOCK_LEASE_SECONDS", 60)) * time.Second,
+		},
 		Mail: MailConfig{
+			Provider:  getEnv("MAIL_PROVIDER", ""),
 			APIKey:    getEnv("RESEND_API_KEY", ""),
 			FromEmail: getEnv("RESEND_FROM_EMAIL", "noreply@devhive.it.com"),
+			FromName:  getEnv("MAIL_FROM_NAME", "DevHive"),
+			SMTP: SMTPConfig{
+				Host:     getEnv("SMTP_HOST", ""),
+				Port:     getEnvAsInt("SMTP_PORT", 587),
+				Username: getEnv("SMTP_USERNAME", ""),
+				Password: getEnv("SMTP_PASSWORD", ""),
+			},
+			CaptureDir: getEnv("MAIL_CAPTURE_DIR", ""),
+			SendLimit:  getEnvAsInt("MAIL_SEND_LIMIT_PER_HOUR", 20),
 		},
 		GoogleOAuth: GoogleOAuthConfig{
 			ClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
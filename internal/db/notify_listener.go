@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"strings"
 	"time"
 
 	"devhive-backend/internal/ws"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -16,6 +18,7 @@ import (
 type NotifyListener struct {
 	databaseURL string
 	hub         *ws.Hub
+	members     MemberStore
 	conn        *pgx.Conn
 	ctx         context.Context
 	cancel      context.CancelFunc
@@ -23,16 +26,24 @@ type NotifyListener struct {
 
 // CacheInvalidationPayload represents the notification payload from PostgreSQL
 type CacheInvalidationPayload struct {
-	Resource  string `json:"resource"`
-	ID        string `json:"id"`
-	Action    string `json:"action"`
-	ProjectID string `json:"projectId"`
-	Timestamp string `json:"timestamp"`
+	Resource  string   `json:"resource"`
+	ID        string   `json:"id"`
+	Action    string   `json:"action"`
+	ProjectID string   `json:"projectId"`
+	Timestamp string   `json:"timestamp"`
+	Seq       int64    `json:"seq"`           // Sequence number in the project's realtime event log
+	IDs       []string `json:"ids,omitempty"` // Every task a BULK action changed; ID is empty
+}
+
+// MemberStore is the subset of repo.Queries used to find who a project-level
+// notification concerns
+type MemberStore interface {
+	ListProjectMemberIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error)
 }
 
 // StartNotifyListener creates a dedicated connection and starts listening for NOTIFY events
 // CRITICAL: Uses dedicated connection, NOT from pool
-func StartNotifyListener(databaseURL string, hub *ws.Hub) {
+func StartNotifyListener(databaseURL string, hub *ws.Hub, members MemberStore) {
 	log.Println("🔧 StartNotifyListener called - initializing NOTIFY listener...")
 	if databaseURL == "" {
 		log.Println("❌ ERROR: databaseURL is empty! NOTIFY listener cannot start.")
@@ -47,6 +58,7 @@ func StartNotifyListener(databaseURL string, hub *ws.Hub) {
 	listener := &NotifyListener{
 		databaseURL: databaseURL,
 		hub:         hub,
+		members:     members,
 		ctx:         ctx,
 		cancel:      cancel,
 	}
@@ -104,10 +116,39 @@ func (l *NotifyListener) listen() {
 			}
 		}
 
-		log.Println("✅ NOTIFY listener started, listening on 'cache_invalidate' channel")
+		// Listen to the activity channel for the project activity feed
+		_, err = conn.Exec(l.ctx, "LISTEN activity")
+		if err != nil {
+			log.Printf("Failed to LISTEN on activity: %v", err)
+			conn.Close(l.ctx)
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-time.After(backoff):
+				continue
+			}
+		}
+
+		// Listen to the hub's cross-instance channel so events published by
+		// any API instance reach this instance's WebSocket clients
+		_, err = conn.Exec(l.ctx, "LISTEN "+ws.HubEventsChannel)
+		if err != nil {
+			log.Printf("Failed to LISTEN on %s: %v", ws.HubEventsChannel, err)
+			conn.Close(l.ctx)
+			select {
+			case <-l.ctx.Done():
+				return
+			case <-time.After(backoff):
+				continue
+			}
+		}
+
+		log.Println("✅ NOTIFY listener started, listening on 'cache_invalidate', 'activity' and 'hub_events' channels")
 		log.Printf("✅ NOTIFY listener connection established successfully at %s", time.Now().Format(time.RFC3339))
 
-		// Notify clients of reconnection (if this is a reconnect)
+		// Notify clients of reconnection (if this is a reconnect). Events sent
+		// while the listener was down are in the realtime event log, so
+		// clients catch up by sending resume with their last sequence number.
 		if l.conn != nil {
 			l.hub.BroadcastToAll("reconnect", map[string]string{"reason": "notify_reconnect"})
 		}
@@ -129,6 +170,14 @@ func (l *NotifyListener) listen() {
 
 			// Handle notification
 			log.Printf("📨 Notification received at %s", time.Now().Format(time.RFC3339))
+			if notification.Channel == "activity" {
+				l.handleActivity(notification)
+				continue
+			}
+			if notification.Channel == ws.HubEventsChannel {
+				l.hub.DeliverPayload(notification.Payload)
+				continue
+			}
 			l.handleNotification(notification)
 		}
 	}
@@ -172,18 +221,43 @@ func (l *NotifyListener) handleNotification(notification *pgconn.Notification) {
 		"project_id": payload.ProjectID,
 		"timestamp":  payload.Timestamp,
 	}
+	if len(payload.IDs) > 0 {
+		messageData["ids"] = payload.IDs
+	}
 
-	// For project deletions and creations, broadcast to ALL clients (not just project-scoped)
-	// This ensures all users' project lists are invalidated
+	// Project creations and deletions and member changes alter which projects
+	// users can see, so they go to the affected users' connections whether or
+	// not those are subscribed to the project
 	// Note: Resource is now normalized to singular ('project', not 'projects')
 	if payload.Resource == "project" && (payload.Action == "DELETE" || payload.Action == "INSERT") {
-		l.hub.BroadcastToAll("cache_invalidate", messageData)
-		log.Printf("Broadcasted project %s cache invalidation to all clients: project_id=%s", payload.Action, payload.ProjectID)
+		recipients, err := affectedUsers(l.ctx, l.members, payload)
+		if err != nil {
+			log.Printf("❌ Failed to load members of project %s: %v", payload.ProjectID, err)
+			return
+		}
+		l.hub.SendToUsers(recipients, payload.ProjectID, payload.Seq, "cache_invalidate", messageData)
+		log.Printf("Sent project %s cache invalidation to %d users: project_id=%s", payload.Action, len(recipients), payload.ProjectID)
+		if payload.Action == "DELETE" {
+			l.hub.Unsubscribe(payload.ProjectID, "", "project_deleted")
+		}
 	} else if payload.Resource == "project_members" {
-		// Broadcast member changes to ALL clients to ensure owners see updates
-		// even if they're not actively viewing the project WebSocket
-		l.hub.BroadcastToAll("cache_invalidate", messageData)
-		log.Printf("Broadcasted project_members %s cache invalidation to all clients: project_id=%s", payload.Action, payload.ProjectID)
+		// Owners see member updates even if they're not actively viewing the
+		// project WebSocket
+		recipients, err := affectedUsers(l.ctx, l.members, payload)
+		if err != nil {
+			log.Printf("❌ Failed to load members of project %s: %v", payload.ProjectID, err)
+			return
+		}
+		l.hub.SendToUsers(recipients, payload.ProjectID, payload.Seq, "cache_invalidate", messageData)
+		log.Printf("Sent project_members %s cache invalidation to %d users: project_id=%s", payload.Action, len(recipients), payload.ProjectID)
+
+		// A removed member stops receiving the project's events. The ID is
+		// "projectId:userId".
+		if payload.Action == "DELETE" {
+			if _, userID, ok := strings.Cut(payload.ID, ":"); ok {
+				l.hub.Unsubscribe(payload.ProjectID, userID, "removed_from_project")
+			}
+		}
 
 		// Log WebSocket connection status AFTER broadcasting
 		total, matching, users := l.hub.GetProjectConnections(payload.ProjectID)
@@ -191,11 +265,62 @@ func (l *NotifyListener) handleNotification(notification *pgconn.Notification) {
 			payload.ProjectID, total, matching, users)
 	} else {
 		// For other changes, broadcast project-scoped
-		l.hub.BroadcastToProject(payload.ProjectID, "cache_invalidate", messageData)
+		l.hub.BroadcastToProject(payload.ProjectID, payload.Seq, "cache_invalidate", messageData)
 		log.Printf("Broadcasted cache invalidation: resource=%s, action=%s, project_id=%s", payload.Resource, payload.Action, payload.ProjectID)
 	}
 }
 
+// affectedUsers returns the IDs of the users a project or membership change
+// concerns: the project's members, plus for membership changes the member
+// themselves, who is no longer listed once removed
+func affectedUsers(ctx context.Context, members MemberStore, payload CacheInvalidationPayload) ([]string, error) {
+	projectID, err := uuid.Parse(payload.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs, err := members.ListProjectMemberIDs(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(memberIDs)+1)
+	users := make([]string, 0, len(memberIDs)+1)
+	for _, id := range memberIDs {
+		seen[id.String()] = true
+		users = append(users, id.String())
+	}
+	// Membership IDs are "projectId:userId"
+	if payload.Resource == "project_members" {
+		if _, userID, ok := strings.Cut(payload.ID, ":"); ok && !seen[userID] {
+			users = append(users, userID)
+		}
+	}
+	return users, nil
+}
+
+// handleActivity streams a new activity_events row to the project's WebSocket
+// clients. The payload is the event as written by the notify_activity_event
+// trigger and is forwarded unchanged, apart from its sequence number moving
+// to the message envelope.
+func (l *NotifyListener) handleActivity(notification *pgconn.Notification) {
+	var event map[string]interface{}
+	if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+		log.Printf("❌ Failed to parse activity payload: %v. Payload: %s", err, notification.Payload)
+		return
+	}
+
+	projectID, _ := event["projectId"].(string)
+	if projectID == "" {
+		log.Printf("ERROR: Activity notification missing projectId, skipping. Payload: %s", notification.Payload)
+		return
+	}
+
+	seq, _ := event["seq"].(float64)
+	delete(event, "seq")
+
+	l.hub.BroadcastToProject(projectID, int64(seq), "activity", event)
+}
+
 // Stop stops the NOTIFY listener
 func (l *NotifyListener) Stop() {
 	if l.cancel != nil {
@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type fakeMembers struct {
+	members map[uuid.UUID][]uuid.UUID
+	err     error
+}
+
+func (f *fakeMembers) ListProjectMemberIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error) {
+	return f.members[projectID], f.err
+}
+
+func TestAffectedUsers(t *testing.T) {
+	projectID := uuid.New()
+	owner, member, removed, outsider := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	store := &fakeMembers{members: map[uuid.UUID][]uuid.UUID{
+		projectID:  {owner, member},
+		uuid.New(): {outsider},
+	}}
+
+	tests := []struct {
+		name    string
+		payload CacheInvalidationPayload
+		want    []uuid.UUID
+	}{
+		{"project created", CacheInvalidationPayload{Resource: "project", Action: "INSERT", ID: projectID.String()}, []uuid.UUID{owner, member}},
+		{"project deleted", CacheInvalidationPayload{Resource: "project", Action: "DELETE", ID: projectID.String()}, []uuid.UUID{owner, member}},
+		{"member added", CacheInvalidationPayload{Resource: "project_members", Action: "INSERT", ID: projectID.String() + ":" + member.String()}, []uuid.UUID{owner, member}},
+		{"member removed", CacheInvalidationPayload{Resource: "project_members", Action: "DELETE", ID: projectID.String() + ":" + removed.String()}, []uuid.UUID{owner, member, removed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.payload.ProjectID = projectID.String()
+			got, err := affectedUsers(context.Background(), store, tt.payload)
+			if err != nil {
+				t.Fatalf("affectedUsers() error = %v", err)
+			}
+			want := make([]string, 0, len(tt.want))
+			for _, id := range tt.want {
+				want = append(want, id.String())
+			}
+			sort.Strings(got)
+			sort.Strings(want)
+			if len(got) != len(want) {
+				t.Fatalf("affectedUsers() = %v, want %v", got, want)
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Fatalf("affectedUsers() = %v, want %v", got, want)
+				}
+			}
+			for _, id := range got {
+				if id == outsider.String() {
+					t.Errorf("affectedUsers() includes non-member %s", id)
+				}
+			}
+		})
+	}
+
+	t.Run("lookup failure", func(t *testing.T) {
+		failing := &fakeMembers{err: errors.New("connection refused")}
+		if _, err := affectedUsers(context.Background(), failing, CacheInvalidationPayload{Resource: "project", ProjectID: projectID.String()}); err == nil {
+			t.Error("affectedUsers() error = nil, want lookup error")
+		}
+	})
+}
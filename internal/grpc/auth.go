@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"devhive-backend/internal/permission"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type contextKey string
+
+const userIDKey contextKey = "userID"
+
+// publicMethods lists RPCs that may be called without a bearer token
+var publicMethods = map[string]bool{
+	"/devhive.v1.UserService/CreateUser": true,
+}
+
+// AuthInterceptor validates the "authorization: Bearer <jwt>" metadata on
+// every non-public RPC and stores the token's subject in the context
+func AuthInterceptor(signingKey string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if publicMethods[info.FullMethod] || strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+			return handler(ctx, req)
+		}
+
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok || len(md.Get("authorization")) == 0 {
+			return nil, status.Error(codes.Unauthenticated, "authorization metadata required")
+		}
+
+		parts := strings.Split(md.Get("authorization")[0], " ")
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			return nil, status.Error(codes.Unauthenticated, "invalid authorization metadata format")
+		}
+
+		token, err := jwt.Parse(parts[1], func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.ErrSignatureInvalid
+			}
+			return []byte(signingKey), nil
+		})
+		if err != nil || !token.Valid {
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid token claims")
+		}
+		userID, ok := claims["sub"].(string)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "invalid user ID in token")
+		}
+
+		return handler(context.WithValue(ctx, userIDKey, userID), req)
+	}
+}
+
+// userFromContext returns the authenticated user's ID set by AuthInterceptor
+func userFromContext(ctx context.Context) (uuid.UUID, error) {
+	userID, ok := ctx.Value(userIDKey).(string)
+	if !ok {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "user not authenticated")
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return uuid.Nil, status.Error(codes.Unauthenticated, "invalid user ID in token")
+	}
+	return userUUID, nil
+}
+
+// authorize checks that the caller's project role grants action and returns
+// the matching gRPC status error when it does not
+func authorize(ctx context.Context, lookup permission.RoleLookup, projectID uuid.UUID, action permission.Action) (permission.Role, error) {
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	role, err := permission.Authorize(ctx, lookup, projectID, userID, action)
+	var denied *permission.DeniedError
+	switch {
+	case err == nil:
+		return role, nil
+	case errors.Is(err, permission.ErrNotMember):
+		return "", status.Error(codes.PermissionDenied, "access denied to project")
+	case errors.As(err, &denied):
+		return "", status.Errorf(codes.PermissionDenied, "your role (%s) does not allow %s", denied.Role, denied.Action)
+	default:
+		log.Printf("Permission check failed: %v", err)
+		return "", status.Error(codes.Internal, "failed to check project permissions")
+	}
+}
@@ -0,0 +1,34 @@
+package grpc
+
+import (
+	"devhive-backend/internal/pagination"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// pageFromRequest builds a keyset page from a list request's limit and
+// cursor, using the same bounds and cursor format as the REST API
+func pageFromRequest(limit int32, cursor string) (pagination.Page, error) {
+	page := pagination.Page{Limit: pagination.DefaultLimit}
+	if limit > 0 && limit <= pagination.MaxLimit {
+		page.Limit = int(limit)
+	}
+	if cursor != "" {
+		c, err := pagination.DecodeCursor(cursor)
+		if err != nil {
+			return pagination.Page{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+		page.Cursor = &c
+	}
+	return page, nil
+}
+
+// nextCursor returns the encoded cursor for the following page, or "" on
+// the last page
+func nextCursor(more bool, last func() pagination.Cursor) string {
+	if !more {
+		return ""
+	}
+	return last().Encode()
+}
@@ -2,8 +2,12 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	v1 "devhive-backend/api/v1"
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
 	"devhive-backend/internal/repo"
 
 	"github.com/google/uuid"
@@ -25,6 +29,10 @@ func (s *ProjectServer) GetProject(ctx context.Context, req *v1.GetProjectReques
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
 
+	if _, err := authorize(ctx, s.queries, projectID, permission.ProjectView); err != nil {
+		return nil, err
+	}
+
 	project, err := s.queries.GetProjectByID(ctx, projectID)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "project not found: %v", err)
@@ -37,13 +45,16 @@ func (s *ProjectServer) GetProject(ctx context.Context, req *v1.GetProjectReques
 		Description: getStringValue(project.Description),
 		CreatedAt:   timestamppb.New(project.CreatedAt),
 		UpdatedAt:   timestamppb.New(project.UpdatedAt),
+		Version:     project.Version,
 	}, nil
 }
 
 // CreateProject creates a new project
 func (s *ProjectServer) CreateProject(ctx context.Context, req *v1.CreateProjectRequest) (*v1.Project, error) {
-	// Get user ID from context (you'll need to implement this)
-	userID := uuid.New() // Placeholder - should come from auth context
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	project, err := s.queries.CreateProject(ctx, repo.CreateProjectParams{
 		OwnerID:     userID,
@@ -56,7 +67,7 @@ func (s *ProjectServer) CreateProject(ctx context.Context, req *v1.CreateProject
 
 	// CRITICAL: Insert owner into project_members table for consistency
 	// This ensures owners appear in member lists and all queries work consistently
-	err = s.queries.AddProjectMember(ctx, repo.AddProjectMemberParams{
+	_, err = s.queries.AddProjectMember(ctx, repo.AddProjectMemberParams{
 		ProjectID: project.ID,
 		UserID:    userID,
 		Role:      "owner",
@@ -67,6 +78,15 @@ func (s *ProjectServer) CreateProject(ctx context.Context, req *v1.CreateProject
 		// TODO: Add proper logging
 	}
 
+	activity.Record(ctx, s.queries, activity.Event{
+		ProjectID:  project.ID,
+		ActorID:    userID,
+		Resource:   activity.ResourceProject,
+		ResourceID: project.ID,
+		Action:     activity.ActionCreated,
+		After:      project,
+	})
+
 	return &v1.Project{
 		Id:          project.ID.String(),
 		OwnerId:     project.OwnerID.String(),
@@ -74,6 +94,7 @@ func (s *ProjectServer) CreateProject(ctx context.Context, req *v1.CreateProject
 		Description: getStringValue(project.Description),
 		CreatedAt:   timestamppb.New(project.CreatedAt),
 		UpdatedAt:   timestamppb.New(project.UpdatedAt),
+		Version:     project.Version,
 	}, nil
 }
 
@@ -84,15 +105,39 @@ func (s *ProjectServer) UpdateProject(ctx context.Context, req *v1.UpdateProject
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
 
+	if _, err := authorize(ctx, s.queries, projectID, permission.ProjectUpdate); err != nil {
+		return nil, err
+	}
+
+	current, err := s.queries.GetProjectByID(ctx, projectID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "project not found: %v", err)
+	}
+	if err := checkVersion("project", req.ExpectedVersion, current.Version); err != nil {
+		return nil, err
+	}
+
 	project, err := s.queries.UpdateProject(ctx, repo.UpdateProjectParams{
-		ID:          projectID,
-		Name:        req.Name,
-		Description: &req.Description,
+		ID:              projectID,
+		Name:            req.Name,
+		Description:     &req.Description,
+		ExpectedVersion: req.ExpectedVersion,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update project: %v", err)
+		return nil, versionConflict("project", req.ExpectedVersion, err)
 	}
 
+	actorID, _ := userFromContext(ctx)
+	activity.Record(ctx, s.queries, activity.Event{
+		ProjectID:  projectID,
+		ActorID:    actorID,
+		Resource:   activity.ResourceProject,
+		ResourceID: projectID,
+		Action:     activity.ActionUpdated,
+		Before:     current,
+		After:      project,
+	})
+
 	return &v1.Project{
 		Id:          project.ID.String(),
 		OwnerId:     project.OwnerID.String(),
@@ -100,6 +145,7 @@ func (s *ProjectServer) UpdateProject(ctx context.Context, req *v1.UpdateProject
 		Description: getStringValue(project.Description),
 		CreatedAt:   timestamppb.New(project.CreatedAt),
 		UpdatedAt:   timestamppb.New(project.UpdatedAt),
+		Version:     project.Version,
 	}, nil
 }
 
@@ -110,27 +156,52 @@ func (s *ProjectServer) DeleteProject(ctx context.Context, req *v1.DeleteProject
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
 
-	err = s.queries.DeleteProject(ctx, projectID)
+	if _, err := authorize(ctx, s.queries, projectID, permission.ProjectDelete); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.SoftDeleteProject(ctx, projectID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete project: %v", err)
 	}
+	if rows == 0 {
+		return nil, status.Error(codes.NotFound, "project not found")
+	}
+
+	actorID, _ := userFromContext(ctx)
+	activity.Record(ctx, s.queries, activity.Event{
+		ProjectID:  projectID,
+		ActorID:    actorID,
+		Resource:   activity.ResourceProject,
+		ResourceID: projectID,
+		Action:     activity.ActionDeleted,
+	})
 
 	return &v1.Empty{}, nil
 }
 
 // ListProjects lists projects with pagination
 func (s *ProjectServer) ListProjects(ctx context.Context, req *v1.ListProjectsRequest) (*v1.ListProjectsResponse, error) {
-	// Get user ID from context (you'll need to implement this)
-	userID := uuid.New() // Placeholder - should come from auth context
+	userID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
 
+	page, err := pageFromRequest(req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	cursorID, cursorCreatedAt := page.Keyset()
 	projects, err := s.queries.ListProjectsByUser(ctx, repo.ListProjectsByUserParams{
-		OwnerID: userID,
-		Limit:   req.Limit,
-		Offset:  req.Offset,
+		UserID:          userID,
+		CursorID:        cursorID,
+		CursorCreatedAt: cursorCreatedAt,
+		Limit:           page.FetchLimit(),
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list projects: %v", err)
 	}
+	projects, more := pagination.Trim(projects, page)
 
 	var responseProjects []*v1.Project
 	for _, project := range projects {
@@ -141,12 +212,17 @@ func (s *ProjectServer) ListProjects(ctx context.Context, req *v1.ListProjectsRe
 			Description: getStringValue(project.Description),
 			CreatedAt:   timestamppb.New(project.CreatedAt),
 			UpdatedAt:   timestamppb.New(project.UpdatedAt),
+			Version:     project.Version,
 		})
 	}
 
 	return &v1.ListProjectsResponse{
 		Projects: responseProjects,
 		Total:    int32(len(responseProjects)),
+		NextCursor: nextCursor(more, func() pagination.Cursor {
+			last := projects[len(projects)-1]
+			return pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}),
 	}, nil
 }
 
@@ -162,14 +238,50 @@ func (s *ProjectServer) AddMember(ctx context.Context, req *v1.AddMemberRequest)
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
-	err = s.queries.AddProjectMember(ctx, repo.AddProjectMemberParams{
+	actorRole, err := authorize(ctx, s.queries, projectID, permission.MemberManage)
+	if err != nil {
+		return nil, err
+	}
+	role, err := permission.ParseRole(req.Role)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid role: %v", err)
+	}
+	if !permission.CanAssignRole(actorRole, role) {
+		return nil, status.Errorf(codes.PermissionDenied, "your role (%s) cannot assign role %s", actorRole, role)
+	}
+	current, err := permission.ProjectRole(ctx, s.queries, projectID, userID)
+	if err == nil && !permission.CanAssignRole(actorRole, current) {
+		return nil, status.Errorf(codes.PermissionDenied, "your role (%s) cannot change a member with role %s", actorRole, current)
+	}
+
+	added, err := s.queries.AddProjectMember(ctx, repo.AddProjectMemberParams{
 		ProjectID: projectID,
 		UserID:    userID,
-		Role:      req.Role,
+		Role:      string(role),
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to add member: %v", err)
 	}
+	if added == 0 {
+		// The user became the owner since the role check
+		return nil, status.Error(codes.FailedPrecondition, "the project owner's role cannot be changed")
+	}
+
+	// Re-adding an existing member only changes their role
+	actorID, _ := userFromContext(ctx)
+	memberEvent := activity.Event{
+		ProjectID:  projectID,
+		ActorID:    actorID,
+		Resource:   activity.ResourceMember,
+		ResourceID: userID,
+		Action:     activity.ActionCreated,
+		After:      map[string]string{"role": string(role)},
+	}
+	if current != "" {
+		memberEvent.Action = activity.ActionRoleChanged
+		memberEvent.Before = map[string]string{"role": string(current)}
+	}
+	activity.Record(ctx, s.queries, memberEvent)
 
 	return &v1.Empty{}, nil
 }
@@ -186,13 +298,56 @@ func (s *ProjectServer) RemoveMember(ctx context.Context, req *v1.RemoveMemberRe
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
 
-	err = s.queries.RemoveProjectMember(ctx, repo.RemoveProjectMemberParams{
+	callerID, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	action := permission.MemberManage
+	if userID == callerID {
+		action = permission.ProjectView
+	}
+	actorRole, err := authorize(ctx, s.queries, projectID, action)
+	if err != nil {
+		return nil, err
+	}
+	targetRole, err := permission.ProjectRole(ctx, s.queries, projectID, userID)
+	if errors.Is(err, permission.ErrNotMember) {
+		return nil, status.Error(codes.NotFound, "member not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load member role: %v", err)
+	}
+	if targetRole == permission.RoleOwner {
+		return nil, status.Error(codes.FailedPrecondition, "the project owner cannot be removed")
+	}
+	if userID != callerID && !permission.CanAssignRole(actorRole, targetRole) {
+		return nil, status.Errorf(codes.PermissionDenied, "your role (%s) cannot remove a member with role %s", actorRole, targetRole)
+	}
+
+	removed, err := s.queries.RemoveProjectMember(ctx, repo.RemoveProjectMemberParams{
 		ProjectID: projectID,
 		UserID:    userID,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to remove member: %v", err)
 	}
+	if removed == 0 {
+		// The member became the owner or left since the role check
+		return nil, status.Error(codes.FailedPrecondition, "the member is the project owner or no longer a member")
+	}
+
+	removeAction := activity.ActionRemoved
+	if userID == callerID {
+		removeAction = activity.ActionLeft
+	}
+	activity.Record(ctx, s.queries, activity.Event{
+		ProjectID:  projectID,
+		ActorID:    callerID,
+		Resource:   activity.ResourceMember,
+		ResourceID: userID,
+		Action:     removeAction,
+		Before:     map[string]string{"role": string(targetRole)},
+	})
 
 	return &v1.Empty{}, nil
 }
@@ -204,6 +359,10 @@ func (s *ProjectServer) ListMembers(ctx context.Context, req *v1.ListMembersRequ
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
 
+	if _, err := authorize(ctx, s.queries, projectID, permission.MemberView); err != nil {
+		return nil, err
+	}
+
 	members, err := s.queries.ListProjectMembers(ctx, projectID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list members: %v", err)
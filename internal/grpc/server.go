@@ -21,7 +21,7 @@ type Server struct {
 
 // New creates a new gRPC server
 func New(cfg *config.Config, queries *repo.Queries) *Server {
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(AuthInterceptor(cfg.JWT.SigningKey)))
 
 	// Register services
 	userServer := &UserServer{queries: queries}
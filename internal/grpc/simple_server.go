@@ -20,7 +20,7 @@ type SimpleServer struct {
 
 // NewSimpleServer creates a new simple gRPC server
 func NewSimpleServer(cfg *config.Config, queries *repo.Queries) *SimpleServer {
-	grpcServer := grpc.NewServer()
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(AuthInterceptor(cfg.JWT.SigningKey)))
 
 	// Enable reflection for debugging
 	reflection.Register(grpcServer)
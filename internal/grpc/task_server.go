@@ -2,9 +2,16 @@ package grpc
 
 import (
 	"context"
+	"errors"
 
 	v1 "devhive-backend/api/v1"
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/planning"
 	"devhive-backend/internal/repo"
+	"devhive-backend/internal/tasks"
+	"devhive-backend/internal/workflow"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
@@ -30,26 +37,11 @@ func (s *TaskServer) GetTask(ctx context.Context, req *v1.GetTaskRequest) (*v1.T
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "task not found: %v", err)
 	}
-
-	var sprintID, assigneeID string
-	if task.SprintID.Valid {
-		sprintID = uuid.UUID(task.SprintID.Bytes).String()
-	}
-	if task.AssigneeID.Valid {
-		assigneeID = uuid.UUID(task.AssigneeID.Bytes).String()
+	if _, err := authorize(ctx, s.queries, task.ProjectID, permission.TaskView); err != nil {
+		return nil, err
 	}
 
-	return &v1.Task{
-		Id:          task.ID.String(),
-		ProjectId:   task.ProjectID.String(),
-		SprintId:    sprintID,
-		AssigneeId:  assigneeID,
-		Title:       getStringValue(task.Description), // Use description as title for gRPC compatibility
-		Description: getStringValue(task.Description),
-		Status:      int32(task.Status),
-		CreatedAt:   timestamppb.New(task.CreatedAt),
-		UpdatedAt:   timestamppb.New(task.UpdatedAt),
-	}, nil
+	return s.taskWithLabels(ctx, task)
 }
 
 // CreateTask creates a new task
@@ -58,6 +50,9 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *v1.CreateTaskRequest)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
+	if _, err := authorize(ctx, s.queries, projectID, permission.TaskCreate); err != nil {
+		return nil, err
+	}
 
 	var sprintID pgtype.UUID
 	if req.SprintId != "" {
@@ -83,36 +78,57 @@ func (s *TaskServer) CreateTask(ctx context.Context, req *v1.CreateTaskRequest)
 	// Note: gRPC CreateTaskRequest doesn't include assignee_id, so we leave it null
 	var assigneeID pgtype.UUID
 
-	task, err := s.queries.CreateTask(ctx, repo.CreateTaskParams{
-		ProjectID:   projectID,
-		SprintID:    sprintID,
-		AssigneeID:  assigneeID,
-		Description: &req.Description,
-		Status:      1, // Default status: TODO
+	initialStatus, err := workflow.InitialStatus(ctx, s.queries, projectID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load task workflow: %v", err)
+	}
+
+	// Planning fields
+	var priority int16
+	if req.Priority != "" {
+		if priority, err = planning.ParsePriority(req.Priority); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if err := planning.ValidateStoryPoints(req.StoryPoints); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	dueDate, err := planning.ParseDueDate(&req.DueDate)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	labelIDs, err := planning.ParseLabelIDs(ctx, s.queries, projectID, req.LabelIds)
+	if err != nil {
+		return nil, labelStatus(err)
+	}
+
+	var task repo.CreateTaskRow
+	err = s.queries.InTx(ctx, func(q *repo.Queries) error {
+		var err error
+		task, err = q.CreateTask(ctx, repo.CreateTaskParams{
+			ProjectID:   projectID,
+			SprintID:    sprintID,
+			AssigneeID:  assigneeID,
+			Description: &req.Description,
+			Status:      initialStatus,
+			Priority:    priority,
+			StoryPoints: req.StoryPoints,
+			DueDate:     dueDate,
+		})
+		if err != nil || len(labelIDs) == 0 {
+			return err
+		}
+		return planning.SetTaskLabels(ctx, q, task.ID, labelIDs)
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create task: %v", err)
 	}
 
-	var sprintIDStr, assigneeIDStr string
-	if task.SprintID.Valid {
-		sprintIDStr = uuid.UUID(task.SprintID.Bytes).String()
-	}
-	if task.AssigneeID.Valid {
-		assigneeIDStr = uuid.UUID(task.AssigneeID.Bytes).String()
+	if created, err := s.queries.GetTaskByID(ctx, task.ID); err == nil {
+		s.recordTask(ctx, activity.ActionCreated, created, nil, created)
 	}
 
-	return &v1.Task{
-		Id:          task.ID.String(),
-		ProjectId:   task.ProjectID.String(),
-		SprintId:    sprintIDStr,
-		AssigneeId:  assigneeIDStr,
-		Title:       getStringValue(task.Description), // Use description as title for gRPC compatibility
-		Description: getStringValue(task.Description),
-		Status:      int32(task.Status),
-		CreatedAt:   timestamppb.New(task.CreatedAt),
-		UpdatedAt:   timestamppb.New(task.UpdatedAt),
-	}, nil
+	return s.loadTask(ctx, task.ID)
 }
 
 // UpdateTask updates an existing task
@@ -122,33 +138,68 @@ func (s *TaskServer) UpdateTask(ctx context.Context, req *v1.UpdateTaskRequest)
 		return nil, status.Errorf(codes.InvalidArgument, "invalid task ID: %v", err)
 	}
 
-	task, err := s.queries.UpdateTask(ctx, repo.UpdateTaskParams{
-		ID:          taskID,
-		Description: &req.Description,
-	})
+	current, err := s.queries.GetTaskByID(ctx, taskID)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update task: %v", err)
+		return nil, status.Errorf(codes.NotFound, "task not found: %v", err)
+	}
+	if _, err := authorize(ctx, s.queries, current.ProjectID, permission.TaskUpdate); err != nil {
+		return nil, err
+	}
+	if err := checkVersion("task", req.ExpectedVersion, current.Version); err != nil {
+		return nil, err
 	}
 
-	var sprintID, assigneeID string
-	if task.SprintID.Valid {
-		sprintID = uuid.UUID(task.SprintID.Bytes).String()
+	// Unset optional fields keep their current values
+	params := repo.UpdateTaskParams{
+		ID:              taskID,
+		Description:     &req.Description,
+		AssigneeID:      current.AssigneeID,
+		Priority:        current.Priority,
+		StoryPoints:     current.StoryPoints,
+		DueDate:         current.DueDate,
+		ExpectedVersion: req.ExpectedVersion,
 	}
-	if task.AssigneeID.Valid {
-		assigneeID = uuid.UUID(task.AssigneeID.Bytes).String()
+	if req.Priority != nil {
+		if params.Priority, err = planning.ParsePriority(*req.Priority); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if req.StoryPoints != nil {
+		params.StoryPoints = req.StoryPoints
+		if *req.StoryPoints < 0 {
+			params.StoryPoints = nil
+		}
+		if err := planning.ValidateStoryPoints(params.StoryPoints); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if req.DueDate != nil {
+		if params.DueDate, err = planning.ParseDueDate(req.DueDate); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	var labelIDs []uuid.UUID
+	if req.ReplaceLabels {
+		if labelIDs, err = planning.ParseLabelIDs(ctx, s.queries, current.ProjectID, req.LabelIds); err != nil {
+			return nil, labelStatus(err)
+		}
 	}
 
-	return &v1.Task{
-		Id:          task.ID.String(),
-		ProjectId:   task.ProjectID.String(),
-		SprintId:    sprintID,
-		AssigneeId:  assigneeID,
-		Title:       getStringValue(task.Description), // Use description as title for gRPC compatibility
-		Description: getStringValue(task.Description),
-		Status:      int32(task.Status),
-		CreatedAt:   timestamppb.New(task.CreatedAt),
-		UpdatedAt:   timestamppb.New(task.UpdatedAt),
-	}, nil
+	err = s.queries.InTx(ctx, func(q *repo.Queries) error {
+		if _, err := q.UpdateTask(ctx, params); err != nil || !req.ReplaceLabels {
+			return err
+		}
+		return planning.SetTaskLabels(ctx, q, taskID, labelIDs)
+	})
+	if err != nil {
+		return nil, versionConflict("task", req.ExpectedVersion, err)
+	}
+
+	if updated, err := s.queries.GetTaskByID(ctx, taskID); err == nil {
+		s.recordTask(ctx, activity.ActionUpdated, updated, current, updated)
+	}
+
+	return s.loadTask(ctx, taskID)
 }
 
 // DeleteTask deletes a task
@@ -158,10 +209,23 @@ func (s *TaskServer) DeleteTask(ctx context.Context, req *v1.DeleteTaskRequest)
 		return nil, status.Errorf(codes.InvalidArgument, "invalid task ID: %v", err)
 	}
 
-	err = s.queries.DeleteTask(ctx, taskID)
+	current, err := s.queries.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "task not found: %v", err)
+	}
+	if _, err := authorize(ctx, s.queries, current.ProjectID, permission.TaskDelete); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.queries.SoftDeleteTask(ctx, taskID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to delete task: %v", err)
 	}
+	if rows == 0 {
+		return nil, status.Error(codes.NotFound, "task not found")
+	}
+
+	s.recordTask(ctx, activity.ActionDeleted, current, current, nil)
 
 	return &v1.Empty{}, nil
 }
@@ -172,42 +236,49 @@ func (s *TaskServer) ListTasks(ctx context.Context, req *v1.ListTasksRequest) (*
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid project ID: %v", err)
 	}
+	if _, err := authorize(ctx, s.queries, projectID, permission.TaskView); err != nil {
+		return nil, err
+	}
 
-	tasks, err := s.queries.ListTasksByProject(ctx, repo.ListTasksByProjectParams{
-		ProjectID: projectID,
-		Limit:     req.Limit,
-		Offset:    req.Offset,
-	})
+	filter, err := listFilter(req)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to list tasks: %v", err)
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	page, err := pageFromRequest(req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	params, err := filter.Params(projectID, page)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	var responseTasks []*v1.Task
-	for _, task := range tasks {
-		var sprintID, assigneeID string
-		if task.SprintID.Valid {
-			sprintID = uuid.UUID(task.SprintID.Bytes).String()
-		}
-		if task.AssigneeID.Valid {
-			assigneeID = uuid.UUID(task.AssigneeID.Bytes).String()
-		}
+	rows, err := s.queries.ListTasks(ctx, params)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list tasks: %v", err)
+	}
+	rows, more := pagination.Trim(rows, page)
 
-		responseTasks = append(responseTasks, &v1.Task{
-			Id:          task.ID.String(),
-			ProjectId:   task.ProjectID.String(),
-			SprintId:    sprintID,
-			AssigneeId:  assigneeID,
-			Title:       getStringValue(task.Description), // Use description as title for gRPC compatibility
-			Description: getStringValue(task.Description),
-			Status:      int32(task.Status),
-			CreatedAt:   timestamppb.New(task.CreatedAt),
-			UpdatedAt:   timestamppb.New(task.UpdatedAt),
-		})
+	responseTasks := make([]*v1.Task, 0, len(rows))
+	taskIDs := make([]uuid.UUID, 0, len(rows))
+	for _, task := range rows {
+		responseTasks = append(responseTasks, taskToProto(repo.GetTaskByIDRow(task)))
+		taskIDs = append(taskIDs, task.ID)
+	}
+	labels, err := planning.LabelsByTask(ctx, s.queries, taskIDs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load task labels: %v", err)
+	}
+	for i, id := range taskIDs {
+		responseTasks[i].Labels = labelsToProto(labels[id])
 	}
 
 	return &v1.ListTasksResponse{
 		Tasks: responseTasks,
 		Total: int32(len(responseTasks)),
+		NextCursor: nextCursor(more, func() pagination.Cursor {
+			return filter.Cursor(rows[len(rows)-1])
+		}),
 	}, nil
 }
 
@@ -223,22 +294,32 @@ func (s *TaskServer) AssignTask(ctx context.Context, req *v1.AssignTaskRequest)
 		return nil, status.Errorf(codes.InvalidArgument, "invalid assignee ID: %v", err)
 	}
 
-	// Get current task to preserve description
+	// Get current task to preserve its other fields
 	currentTask, err := s.queries.GetTaskByID(ctx, taskID)
 	if err != nil {
 		return nil, status.Errorf(codes.NotFound, "task not found: %v", err)
 	}
+	if _, err := authorize(ctx, s.queries, currentTask.ProjectID, permission.TaskUpdate); err != nil {
+		return nil, err
+	}
 
 	assigneeUUID := pgtype.UUID{Bytes: assigneeID, Valid: true}
 	_, err = s.queries.UpdateTask(ctx, repo.UpdateTaskParams{
 		ID:          taskID,
 		Description: currentTask.Description,
 		AssigneeID:  assigneeUUID,
+		Priority:    currentTask.Priority,
+		StoryPoints: currentTask.StoryPoints,
+		DueDate:     currentTask.DueDate,
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to assign task: %v", err)
 	}
 
+	if updated, err := s.queries.GetTaskByID(ctx, taskID); err == nil {
+		s.recordTask(ctx, activity.ActionAssigned, updated, currentTask, updated)
+	}
+
 	return &v1.Empty{}, nil
 }
 
@@ -249,13 +330,153 @@ func (s *TaskServer) UpdateTaskStatus(ctx context.Context, req *v1.UpdateTaskSta
 		return nil, status.Errorf(codes.InvalidArgument, "invalid task ID: %v", err)
 	}
 
+	task, err := s.queries.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "task not found: %v", err)
+	}
+	if _, err := authorize(ctx, s.queries, task.ProjectID, permission.TaskUpdate); err != nil {
+		return nil, err
+	}
+	if err := checkVersion("task", req.ExpectedVersion, task.Version); err != nil {
+		return nil, err
+	}
+	if err := workflowStatus(workflow.CheckTransition(ctx, s.queries, task.ProjectID, task.Status, req.Status)); err != nil {
+		return nil, err
+	}
+
+	// The transition was checked against the status just read, so the update
+	// only applies to that version even without an expected version
 	_, err = s.queries.UpdateTaskStatus(ctx, repo.UpdateTaskStatusParams{
-		ID:     taskID,
-		Status: int32(req.Status),
+		ID:              taskID,
+		Status:          req.Status,
+		ExpectedVersion: &task.Version,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update task status: %v", err)
+		return nil, versionConflict("task", &task.Version, err)
+	}
+
+	if updated, err := s.queries.GetTaskByID(ctx, taskID); err == nil {
+		s.recordTask(ctx, activity.ActionStatusChanged, updated, task, updated)
 	}
 
 	return &v1.Empty{}, nil
 }
+
+// recordTask writes a task mutation to the activity log; before or after is
+// nil for creations and deletions
+func (s *TaskServer) recordTask(ctx context.Context, action string, task repo.GetTaskByIDRow, before, after any) {
+	actorID, _ := userFromContext(ctx)
+	activity.Record(ctx, s.queries, activity.Event{
+		ProjectID:  task.ProjectID,
+		ActorID:    actorID,
+		Resource:   activity.ResourceTask,
+		ResourceID: task.ID,
+		Action:     action,
+		Before:     before,
+		After:      after,
+	})
+}
+
+// workflowStatus maps a workflow check error to a gRPC status error
+func workflowStatus(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, workflow.ErrTransitionNotAllowed), errors.Is(err, workflow.ErrUnknownStatus):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Errorf(codes.Internal, "failed to check task workflow: %v", err)
+	}
+}
+
+// listFilter builds the task listing filter from a ListTasks request
+func listFilter(req *v1.ListTasksRequest) (tasks.Filter, error) {
+	var f tasks.Filter
+	if req.Status != nil {
+		f.Statuses = []int32{*req.Status}
+	}
+	if err := f.SetSprint(req.SprintId); err != nil {
+		return f, err
+	}
+	if err := f.SetAssignee(req.AssigneeId); err != nil {
+		return f, err
+	}
+	if err := f.SetLabels(req.LabelIds); err != nil {
+		return f, err
+	}
+	if err := f.SetDueRange(req.DueAfter, req.DueBefore); err != nil {
+		return f, err
+	}
+	f.Search = req.Query
+	return f, f.SetSort(req.Sort, req.Order)
+}
+
+// loadTask fetches a task with its labels
+func (s *TaskServer) loadTask(ctx context.Context, taskID uuid.UUID) (*v1.Task, error) {
+	task, err := s.queries.GetTaskByID(ctx, taskID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load task: %v", err)
+	}
+	return s.taskWithLabels(ctx, task)
+}
+
+// taskWithLabels converts a task row and attaches its labels
+func (s *TaskServer) taskWithLabels(ctx context.Context, task repo.GetTaskByIDRow) (*v1.Task, error) {
+	labels, err := planning.LabelsByTask(ctx, s.queries, []uuid.UUID{task.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load task labels: %v", err)
+	}
+	resp := taskToProto(task)
+	resp.Labels = labelsToProto(labels[task.ID])
+	return resp, nil
+}
+
+// taskToProto converts a task row to its gRPC message, without labels
+func taskToProto(task repo.GetTaskByIDRow) *v1.Task {
+	var sprintID, assigneeID, dueDate string
+	if task.SprintID.Valid {
+		sprintID = uuid.UUID(task.SprintID.Bytes).String()
+	}
+	if task.AssigneeID.Valid {
+		assigneeID = uuid.UUID(task.AssigneeID.Bytes).String()
+	}
+	if d := planning.FormatDueDate(task.DueDate); d != nil {
+		dueDate = *d
+	}
+
+	return &v1.Task{
+		Id:          task.ID.String(),
+		ProjectId:   task.ProjectID.String(),
+		SprintId:    sprintID,
+		AssigneeId:  assigneeID,
+		Title:       getStringValue(task.Description), // Use description as title for gRPC compatibility
+		Description: getStringValue(task.Description),
+		Status:      task.Status,
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		UpdatedAt:   timestamppb.New(task.UpdatedAt),
+		Priority:    planning.PriorityName(task.Priority),
+		Version:     task.Version,
+		StoryPoints: task.StoryPoints,
+		DueDate:     dueDate,
+	}
+}
+
+func labelsToProto(rows []repo.ListLabelsForTasksRow) []*v1.Label {
+	labels := make([]*v1.Label, 0, len(rows))
+	for _, row := range rows {
+		labels = append(labels, &v1.Label{
+			Id:    row.ID.String(),
+			Name:  row.Name,
+			Color: row.Color,
+		})
+	}
+	return labels
+}
+
+// labelStatus maps a label validation error to a gRPC status error
+func labelStatus(err error) error {
+	if errors.Is(err, planning.ErrInvalidLabels) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return status.Errorf(codes.Internal, "failed to check task labels: %v", err)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	v1 "devhive-backend/api/v1"
+	"devhive-backend/internal/pagination"
 	"devhive-backend/internal/repo"
 
 	"github.com/google/uuid"
@@ -82,6 +83,9 @@ func (s *UserServer) UpdateUser(ctx context.Context, req *v1.UpdateUserRequest)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
+	if err := requireSelf(ctx, userID); err != nil {
+		return nil, err
+	}
 
 	user, err := s.queries.UpdateUser(ctx, repo.UpdateUserParams{
 		ID:        userID,
@@ -114,6 +118,9 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *v1.DeleteUserRequest)
 	if err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "invalid user ID: %v", err)
 	}
+	if err := requireSelf(ctx, userID); err != nil {
+		return nil, err
+	}
 
 	err = s.queries.DeactivateUser(ctx, userID)
 	if err != nil {
@@ -125,13 +132,20 @@ func (s *UserServer) DeleteUser(ctx context.Context, req *v1.DeleteUserRequest)
 
 // ListUsers lists users with pagination
 func (s *UserServer) ListUsers(ctx context.Context, req *v1.ListUsersRequest) (*v1.ListUsersResponse, error) {
+	page, err := pageFromRequest(req.Limit, req.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	cursorID, cursorCreatedAt := page.Keyset()
 	users, err := s.queries.ListUsers(ctx, repo.ListUsersParams{
-		Limit:  req.Limit,
-		Offset: req.Offset,
+		CursorID:        cursorID,
+		CursorCreatedAt: cursorCreatedAt,
+		Limit:           page.FetchLimit(),
 	})
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to list users: %v", err)
 	}
+	users, more := pagination.Trim(users, page)
 
 	var responseUsers []*v1.User
 	for _, user := range users {
@@ -151,9 +165,25 @@ func (s *UserServer) ListUsers(ctx context.Context, req *v1.ListUsersRequest) (*
 	return &v1.ListUsersResponse{
 		Users: responseUsers,
 		Total: int32(len(responseUsers)),
+		NextCursor: nextCursor(more, func() pagination.Cursor {
+			last := users[len(users)-1]
+			return pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}),
 	}, nil
 }
 
+// requireSelf rejects calls that act on an account other than the caller's
+func requireSelf(ctx context.Context, userID uuid.UUID) error {
+	callerID, err := userFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if callerID != userID {
+		return status.Error(codes.PermissionDenied, "cannot modify another user's account")
+	}
+	return nil
+}
+
 // Helper functions
 func getStringValue(s *string) string {
 	if s == nil {
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// checkVersion rejects an update whose expected version is not the stored
+// one. ABORTED is the gRPC code for read-modify-write conflicts; clients
+// re-read the resource and retry.
+func checkVersion(resource string, expected *int32, current int32) error {
+	if expected != nil && *expected != current {
+		return status.Errorf(codes.Aborted, "%s was modified: current version is %d", resource, current)
+	}
+	return nil
+}
+
+// versionConflict maps an update that matched no row after an expected
+// version was given to ABORTED, and any other failure to INTERNAL
+func versionConflict(resource string, expected *int32, err error) error {
+	if expected != nil && errors.Is(err, pgx.ErrNoRows) {
+		return status.Errorf(codes.Aborted, "%s was modified concurrently", resource)
+	}
+	return status.Errorf(codes.Internal, "failed to update %s: %v", resource, err)
+}
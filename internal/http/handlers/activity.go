@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+type ActivityHandler struct {
+	queries *repo.Queries
+}
+
+func NewActivityHandler(queries *repo.Queries) *ActivityHandler {
+	return &ActivityHandler{
+		queries: queries,
+	}
+}
+
+// ActivityResponse represents one activity feed entry
+type ActivityResponse struct {
+	ID         string          `json:"id"`
+	ProjectID  string          `json:"projectId"`
+	ActorID    string          `json:"actorId,omitempty"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resourceId,omitempty"`
+	Action     string          `json:"action"`
+	Changes    json.RawMessage `json:"changes"`
+	CreatedAt  string          `json:"createdAt"`
+	Actor      *struct {
+		Username  string `json:"username"`
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	} `json:"actor,omitempty"`
+}
+
+// ListActivity handles listing a project's activity feed, newest first
+func (h *ActivityHandler) ListActivity(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.ProjectView)
+	if !ok {
+		return
+	}
+
+	filter, err := activity.ParseQuery(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
+	}
+
+	events, err := h.queries.ListActivityEvents(r.Context(), filter.Params(projectUUID, page))
+	if err != nil {
+		log.Printf("ListActivity: %v", err)
+		response.InternalServerError(w, "Failed to list activity")
+		return
+	}
+	events, more := pagination.Trim(events, page)
+
+	activityResponses := make([]ActivityResponse, 0, len(events))
+	for _, event := range events {
+		activityResponses = append(activityResponses, buildActivityResponse(event))
+	}
+
+	var next *pagination.Cursor
+	if more {
+		last := events[len(events)-1]
+		next = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	response.Paginated(w, r, "activity", activityResponses, page, next)
+}
+
+// buildActivityResponse converts ListActivityEventsRow to ActivityResponse
+func buildActivityResponse(event repo.ListActivityEventsRow) ActivityResponse {
+	resp := ActivityResponse{
+		ID:        event.ID.String(),
+		ProjectID: event.ProjectID.String(),
+		Resource:  event.Resource,
+		Action:    event.Action,
+		Changes:   json.RawMessage(event.Changes),
+		CreatedAt: event.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if event.ResourceID.Valid {
+		resp.ResourceID = uuid.UUID(event.ResourceID.Bytes).String()
+	}
+	if event.ActorID.Valid {
+		resp.ActorID = uuid.UUID(event.ActorID.Bytes).String()
+	}
+	// The actor is absent once their account has been deleted
+	if event.ActorUsername != nil {
+		resp.Actor = &struct {
+			Username  string `json:"username"`
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+		}{Username: *event.ActorUsername}
+		if event.ActorFirstName != nil {
+			resp.Actor.FirstName = *event.ActorFirstName
+		}
+		if event.ActorLastName != nil {
+			resp.Actor.LastName = *event.ActorLastName
+		}
+	}
+	return resp
+}
+
+// actorID returns the authenticated user for the activity log, or uuid.Nil
+// when the request is unauthenticated
+func actorID(r *http.Request) uuid.UUID {
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	id, _ := uuid.Parse(userID)
+	return id
+}
@@ -3,22 +3,28 @@ package handlers
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
 	"devhive-backend/internal/config"
 	"devhive-backend/internal/http/middleware"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/mail"
 	"devhive-backend/internal/repo"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 	"golang.org/x/oauth2"
@@ -28,12 +34,14 @@ import (
 type AuthHandler struct {
 	cfg     *config.Config
 	queries *repo.Queries
+	mailer  mail.Mailer
 }
 
-func NewAuthHandler(cfg *config.Config, queries *repo.Queries) *AuthHandler {
+func NewAuthHandler(cfg *config.Config, queries *repo.Queries, mailer mail.Mailer) *AuthHandler {
 	return &AuthHandler{
 		cfg:     cfg,
 		queries: queries,
+		mailer:  mailer,
 	}
 }
 
@@ -91,8 +99,14 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check if email is verified (when required)
+	if h.cfg.EmailVerification.Required && !user.EmailVerifiedAt.Valid {
+		response.Problemf(w, http.StatusForbidden, "email_not_verified", "Email address has not been verified")
+		return
+	}
+
 	// Generate access token (short-lived)
-	accessToken, err := h.generateJWT(user.ID.String())
+	accessToken, err := h.generateJWT(user.ID.String(), time.Now())
 	if err != nil {
 		response.InternalServerError(w, "Failed to generate token")
 		return
@@ -183,8 +197,8 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate new access token
-	accessToken, err := h.generateJWT(user.ID.String())
+	// Generate new access token; it does not count as a fresh login
+	accessToken, err := h.generateJWT(user.ID.String(), time.Time{})
 	if err != nil {
 		response.InternalServerError(w, "Failed to generate token")
 		return
@@ -238,6 +252,14 @@ func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// passwordResetRequestedMessage is returned for every reset request so the
+// response does not reveal whether an account exists for the email.
+const passwordResetRequestedMessage = "If the email exists, a reset link has been sent"
+
+// passwordResetResponseTime is how long a reset request takes at least, so
+// the response time does not reveal whether the email belongs to an account
+const passwordResetResponseTime = 3 * time.Second
+
 // RequestPasswordReset handles password reset requests
 func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
 	var req PasswordResetRequest
@@ -245,35 +267,86 @@ func (h *AuthHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// Look the account up and mail it before responding, then pad the
+	// response so it takes as long whether or not the email has an account
+	start := time.Now()
+	h.issuePasswordReset(r.Context(), strings.TrimSpace(req.Email))
+	if wait := passwordResetResponseTime - time.Since(start); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-r.Context().Done():
+		}
+	}
+
+	// Always respond the same way; the outcome only shows up in the mailbox
+	response.JSON(w, http.StatusOK, map[string]string{"message": passwordResetRequestedMessage})
+}
+
+// issuePasswordReset creates a reset token for the account with the given
+// email, if any, and mails the reset link. Failures are logged, not returned.
+func (h *AuthHandler) issuePasswordReset(ctx context.Context, email string) {
 	// Get user by email
-	user, err := h.queries.GetUserByEmail(r.Context(), req.Email)
+	user, err := h.queries.GetUserByEmail(ctx, email)
 	if err != nil {
-		// Don't reveal if email exists or not
-		response.JSON(w, http.StatusOK, map[string]string{"message": "If the email exists, a reset link has been sent"})
 		return
 	}
 
-	// Generate reset token
+	// Limit outstanding tokens per user
+	active, err := h.queries.CountActivePasswordResets(ctx, user.ID)
+	if err != nil {
+		log.Printf("Failed to count password resets for user %s: %v", user.ID, err)
+		return
+	}
+	if active >= int64(h.cfg.PasswordReset.MaxActiveTokens) {
+		log.Printf("Password reset requested for user %s with %d active tokens, skipping", user.ID, active)
+		return
+	}
+
+	// Generate reset token; only its hash is stored
 	token := generateRandomToken(32)
-	expiresAt := time.Now().Add(24 * time.Hour)
+	expiresAt := time.Now().Add(h.cfg.PasswordReset.TokenTTL)
 
-	// Store reset token
-	_, err = h.queries.CreatePasswordReset(r.Context(), repo.CreatePasswordResetParams{
-		UserID:     user.ID,
-		ResetToken: token,
-		ExpiresAt:  expiresAt,
+	_, err = h.queries.CreatePasswordReset(ctx, repo.CreatePasswordResetParams{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
 	})
 	if err != nil {
-		response.InternalServerError(w, "Failed to create reset token")
+		log.Printf("Failed to create password reset for user %s: %v", user.ID, err)
 		return
 	}
 
-	// TODO: Send email with reset link
-	// For now, just return the token (remove this in production)
-	response.JSON(w, http.StatusOK, map[string]string{
-		"message": "Reset token created",
-		"token":   token, // Remove this in production
+	msg, err := mail.Render(mail.TemplatePasswordReset, []string{user.Email}, "Reset your DevHive password", mail.PasswordResetData{
+		Username:  user.Username,
+		ResetURL:  h.passwordResetURL(token),
+		ExpiresIn: formatDuration(h.cfg.PasswordReset.TokenTTL),
 	})
+	if err != nil {
+		log.Printf("Failed to render password reset email for user %s: %v", user.ID, err)
+		return
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send password reset email to user %s: %v", user.ID, err)
+	}
+}
+
+// passwordResetURL builds the frontend link for a reset token
+func (h *AuthHandler) passwordResetURL(token string) string {
+	return h.cfg.FrontendURL + h.cfg.PasswordReset.Path + "?token=" + url.QueryEscape(token)
+}
+
+// formatDuration renders a duration in whole hours or minutes for emails
+func formatDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		if d == time.Hour {
+			return "1 hour"
+		}
+		return fmt.Sprintf("%d hours", int(d/time.Hour))
+	}
+	if d == time.Minute {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", int(d/time.Minute))
 }
 
 // ResetPassword handles password reset
@@ -283,43 +356,54 @@ func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get reset token
-	reset, err := h.queries.GetPasswordResetByToken(r.Context(), req.Token)
-	if err != nil {
-		response.BadRequest(w, "Invalid or expired reset token")
+	if req.Token == "" {
+		response.BadRequest(w, "Reset token is required")
 		return
 	}
-
-	// Check if token is expired
-	if time.Now().After(reset.ExpiresAt) {
-		response.BadRequest(w, "Reset token has expired")
+	if len(req.Password) < 8 {
+		response.BadRequest(w, "New password must be at least 8 characters")
 		return
 	}
 
-	// Hash new password
+	// Hash new password before consuming the token
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
 		response.InternalServerError(w, "Failed to hash password")
 		return
 	}
 
-	// Update user password
+	// Consume the token and set the password together, so a failed update
+	// leaves the token usable
 	passwordStr := string(hashedPassword)
-	err = h.queries.UpdateUserPassword(r.Context(), repo.UpdateUserPasswordParams{
-		ID:        reset.UserID,
-		PasswordH: &passwordStr,
+	err = h.queries.InTx(r.Context(), func(q *repo.Queries) error {
+		// Single-use, fails if expired or already used
+		reset, err := q.ConsumePasswordReset(r.Context(), hashToken(req.Token))
+		if err != nil {
+			return err
+		}
+		if err := q.UpdateUserPassword(r.Context(), repo.UpdateUserPasswordParams{
+			ID:        reset.UserID,
+			PasswordH: &passwordStr,
+		}); err != nil {
+			return err
+		}
+
+		// Invalidate remaining reset tokens and sessions for the user
+		if err := q.InvalidateUserPasswordResets(r.Context(), reset.UserID); err != nil {
+			return err
+		}
+		return q.DeleteUserRefreshTokens(r.Context(), reset.UserID)
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.BadRequest(w, "Invalid or expired reset token")
+		return
+	}
 	if err != nil {
+		log.Printf("Failed to reset password: %v", err)
 		response.InternalServerError(w, "Failed to update password")
 		return
 	}
 
-	// Delete reset token
-	if err := h.queries.DeletePasswordReset(r.Context(), req.Token); err != nil {
-		// Log error but don't fail the request
-		// TODO: Add proper logging
-	}
-
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Password updated successfully"})
 }
 
@@ -473,8 +557,65 @@ func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Password updated successfully"})
 }
 
-// generateJWT generates a JWT token for the user
-func (h *AuthHandler) generateJWT(userID string) (string, error) {
+// ReauthenticateRequest represents a password confirmation by a signed-in user
+type ReauthenticateRequest struct {
+	Password string `json:"password"`
+}
+
+// Reauthenticate confirms the signed-in user's password and returns a fresh
+// access token marked as just authenticated. Admin endpoints require one.
+// Google accounts have no password and sign in with Google again instead.
+func (h *AuthHandler) Reauthenticate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req ReauthenticateRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+
+	user, err := h.queries.GetUserByIDWithPassword(r.Context(), userUUID)
+	if err != nil {
+		response.Unauthorized(w, "Invalid credentials")
+		return
+	}
+	if user.PasswordH == nil {
+		response.Unauthorized(w, "Invalid credentials - OAuth user")
+		return
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(*user.PasswordH), []byte(req.Password)); err != nil {
+		response.Unauthorized(w, "Invalid credentials")
+		return
+	}
+	if !user.Active {
+		response.Unauthorized(w, "Account is deactivated")
+		return
+	}
+
+	accessToken, err := h.generateJWT(user.ID.String(), time.Now())
+	if err != nil {
+		response.InternalServerError(w, "Failed to generate token")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, LoginResponse{
+		Token:  accessToken,
+		UserID: user.ID.String(),
+	})
+}
+
+// generateJWT generates a JWT token for the user. authTime is when the user
+// entered their credentials, or zero for tokens issued by a refresh.
+func (h *AuthHandler) generateJWT(userID string, authTime time.Time) (string, error) {
 	now := time.Now()
 	claims := jwt.MapClaims{
 		"sub": userID,
@@ -483,6 +624,9 @@ func (h *AuthHandler) generateJWT(userID string) (string, error) {
 		"iss": h.cfg.JWT.Issuer,
 		"aud": h.cfg.JWT.Audience,
 	}
+	if !authTime.IsZero() {
+		claims["auth_time"] = authTime.Unix()
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(h.cfg.JWT.SigningKey))
@@ -495,6 +639,12 @@ func generateRandomToken(length int) string {
 	return hex.EncodeToString(bytes)
 }
 
+// hashToken returns the hex-encoded SHA-256 of a token for storage
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 // ValidateToken handles token validation requests
 // Returns token validity and expiration info without requiring refresh
 func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
@@ -726,7 +876,7 @@ func (h *AuthHandler) GoogleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate DevHive access token
-	accessToken, err := h.generateJWT(userID.String())
+	accessToken, err := h.generateJWT(userID.String(), time.Now())
 	if err != nil {
 		response.InternalServerError(w, "Failed to generate access token")
 		return
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/broadcast"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/planning"
+	"devhive-backend/internal/repo"
+	"devhive-backend/internal/workflow"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const defaultLabelColor = "#6B7280"
+
+type LabelHandler struct {
+	queries *repo.Queries
+}
+
+func NewLabelHandler(queries *repo.Queries) *LabelHandler {
+	return &LabelHandler{
+		queries: queries,
+	}
+}
+
+// LabelRequest represents the label create and update request
+type LabelRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// LabelInfo is the label summary embedded in task responses
+type LabelInfo struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// LabelResponse represents a label response
+type LabelResponse struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"projectId"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+// ListLabels handles listing a project's labels
+func (h *LabelHandler) ListLabels(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.TaskView)
+	if !ok {
+		return
+	}
+
+	labels, err := h.queries.ListProjectLabels(r.Context(), projectUUID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to list labels")
+		return
+	}
+
+	labelResponses := make([]LabelResponse, 0, len(labels))
+	for _, label := range labels {
+		labelResponses = append(labelResponses, buildLabelResponse(label))
+	}
+
+	response.JSON(w, http.StatusOK, map[string]interface{}{
+		"labels": labelResponses,
+		"count":  len(labelResponses),
+	})
+}
+
+// CreateLabel handles creating a project label
+func (h *LabelHandler) CreateLabel(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.TaskCreate)
+	if !ok {
+		return
+	}
+
+	var req LabelRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	name, color, ok := validateLabelRequest(w, req)
+	if !ok {
+		return
+	}
+
+	label, err := h.queries.CreateLabel(r.Context(), repo.CreateLabelParams{
+		ProjectID: projectUUID,
+		Name:      name,
+		Color:     color,
+	})
+	if isUniqueViolation(err) {
+		response.Conflict(w, "A label with this name already exists")
+		return
+	}
+	if err != nil {
+		log.Printf("CreateLabel: %v", err)
+		response.InternalServerError(w, "Failed to create label")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    actorID(r),
+		Resource:   activity.ResourceLabel,
+		ResourceID: label.ID,
+		Action:     activity.ActionCreated,
+		After:      label,
+	})
+
+	response.JSON(w, http.StatusCreated, buildLabelResponse(label))
+}
+
+// UpdateLabel handles renaming or recoloring a project label
+func (h *LabelHandler) UpdateLabel(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.TaskUpdate)
+	if !ok {
+		return
+	}
+	label, ok := h.loadLabel(w, r, projectUUID)
+	if !ok {
+		return
+	}
+
+	var req LabelRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	if req.Color == "" {
+		req.Color = label.Color
+	}
+	name, color, ok := validateLabelRequest(w, req)
+	if !ok {
+		return
+	}
+
+	updated, err := h.queries.UpdateLabel(r.Context(), repo.UpdateLabelParams{
+		ID:    label.ID,
+		Name:  name,
+		Color: color,
+	})
+	if isUniqueViolation(err) {
+		response.Conflict(w, "A label with this name already exists")
+		return
+	}
+	if err != nil {
+		log.Printf("UpdateLabel: %v", err)
+		response.InternalServerError(w, "Failed to update label")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    actorID(r),
+		Resource:   activity.ResourceLabel,
+		ResourceID: label.ID,
+		Action:     activity.ActionUpdated,
+		Before:     label,
+		After:      updated,
+	})
+
+	// Tasks embed label names and colors, so clients must refetch them
+	broadcast.Send(r.Context(), projectUUID.String(), broadcast.EventCacheInvalidate, map[string]any{
+		"resource":  "labels",
+		"action":    "UPDATE",
+		"id":        updated.ID.String(),
+		"projectId": projectUUID.String(),
+	})
+
+	response.JSON(w, http.StatusOK, buildLabelResponse(updated))
+}
+
+// DeleteLabel handles deleting a project label; it is removed from all tasks
+func (h *LabelHandler) DeleteLabel(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.TaskDelete)
+	if !ok {
+		return
+	}
+	label, ok := h.loadLabel(w, r, projectUUID)
+	if !ok {
+		return
+	}
+
+	if err := h.queries.DeleteLabel(r.Context(), label.ID); err != nil {
+		log.Printf("DeleteLabel: %v", err)
+		response.InternalServerError(w, "Failed to delete label")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    actorID(r),
+		Resource:   activity.ResourceLabel,
+		ResourceID: label.ID,
+		Action:     activity.ActionDeleted,
+		Before:     label,
+	})
+
+	broadcast.Send(r.Context(), projectUUID.String(), broadcast.EventCacheInvalidate, map[string]any{
+		"resource":  "labels",
+		"action":    "DELETE",
+		"id":        label.ID.String(),
+		"projectId": projectUUID.String(),
+	})
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Label deleted successfully"})
+}
+
+// loadLabel loads the {labelId} route parameter, which must belong to the project
+func (h *LabelHandler) loadLabel(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) (repo.Label, bool) {
+	labelUUID, err := uuid.Parse(chi.URLParam(r, "labelId"))
+	if err != nil {
+		response.BadRequest(w, "Invalid label ID")
+		return repo.Label{}, false
+	}
+	label, err := h.queries.GetLabelByID(r.Context(), labelUUID)
+	if err != nil || label.ProjectID != projectID {
+		response.NotFound(w, "Label not found")
+		return repo.Label{}, false
+	}
+	return label, true
+}
+
+// validateLabelRequest trims and checks a label request, writing a 400 when invalid
+func validateLabelRequest(w http.ResponseWriter, req LabelRequest) (string, string, bool) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 50 {
+		response.BadRequest(w, "Label name must be 1-50 characters")
+		return "", "", false
+	}
+	color := req.Color
+	if color == "" {
+		color = defaultLabelColor
+	}
+	if !workflow.ValidColor(color) {
+		response.BadRequest(w, "Label color must look like #RRGGBB")
+		return "", "", false
+	}
+	return name, color, true
+}
+
+// writeLabelError writes the response for a failed label lookup.
+// It returns true when err is nil and the request may continue.
+func writeLabelError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, planning.ErrInvalidLabels):
+		response.BadRequest(w, "Labels must be IDs of labels in this project")
+	default:
+		log.Printf("Label check failed: %v", err)
+		response.InternalServerError(w, "Failed to check task labels")
+	}
+	return false
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func buildLabelResponse(label repo.Label) LabelResponse {
+	return LabelResponse{
+		ID:        label.ID.String(),
+		ProjectID: label.ProjectID.String(),
+		Name:      label.Name,
+		Color:     label.Color,
+		CreatedAt: label.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt: label.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
@@ -1,19 +1,24 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
+	"strings"
 
 	"devhive-backend/internal/config"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/mail"
 )
 
 type MailHandler struct {
-	cfg *config.Config
+	cfg    *config.Config
+	mailer mail.Mailer
 }
 
-func NewMailHandler(cfg *config.Config) *MailHandler {
+func NewMailHandler(cfg *config.Config, mailer mail.Mailer) *MailHandler {
 	return &MailHandler{
-		cfg: cfg,
+		cfg:    cfg,
+		mailer: mailer,
 	}
 }
 
@@ -21,8 +26,7 @@ func NewMailHandler(cfg *config.Config) *MailHandler {
 type SendEmailRequest struct {
 	To      string `json:"to"`
 	Subject string `json:"subject"`
-	Body    string `json:"body"`
-	HTML    string `json:"html,omitempty"`
+	Body    string `json:"body"` // Plain text, placed in the generic template
 }
 
 // SendEmail handles sending emails
@@ -32,8 +36,31 @@ func (h *MailHandler) SendEmail(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// TODO: Implement actual email sending using Mailgun or another service
-	// For now, just return a success response
+	req.To = strings.TrimSpace(req.To)
+	if req.To == "" || !isValidEmail(req.To) {
+		response.BadRequest(w, "A valid recipient email is required")
+		return
+	}
+	if strings.TrimSpace(req.Subject) == "" || strings.TrimSpace(req.Body) == "" {
+		response.BadRequest(w, "Subject and body are required")
+		return
+	}
+
+	msg, err := mail.Render(mail.TemplateGeneric, []string{req.To}, req.Subject, mail.GenericData{
+		Subject: req.Subject,
+		Body:    req.Body,
+	})
+	if err != nil {
+		response.InternalServerError(w, "Failed to render email")
+		return
+	}
+
+	if err := h.mailer.Send(r.Context(), msg); err != nil {
+		log.Printf("Failed to send email to %s: %v", req.To, err)
+		response.InternalServerError(w, "Failed to send email")
+		return
+	}
+
 	response.JSON(w, http.StatusOK, map[string]string{
 		"message": "Email sent successfully",
 		"to":      req.To,
@@ -6,10 +6,13 @@ import (
 	"strconv"
 	"strings"
 
+	"devhive-backend/internal/activity"
 	"devhive-backend/internal/broadcast"
 	"devhive-backend/internal/config"
 	"devhive-backend/internal/http/middleware"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
 	"devhive-backend/internal/repo"
 	"devhive-backend/internal/ws"
 
@@ -89,29 +92,13 @@ func (h *MessageHandler) ListMessagesByProject(w http.ResponseWriter, r *http.Re
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.MessageView) {
 		return
 	}
 
-	// Parse pagination parameters
-	limit := 20
-	offset := 0
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
-
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
 	}
 
 	projectUUID2, err := uuid.Parse(projectID)
@@ -119,15 +106,18 @@ func (h *MessageHandler) ListMessagesByProject(w http.ResponseWriter, r *http.Re
 		response.BadRequest(w, "Invalid project ID")
 		return
 	}
+	cursorID, cursorCreatedAt := page.Keyset()
 	messages, err := h.queries.ListMessagesByProject(r.Context(), repo.ListMessagesByProjectParams{
-		ProjectID: projectUUID2,
-		Limit:     int32(limit),
-		Offset:    int32(offset),
+		ProjectID:       projectUUID2,
+		CursorID:        cursorID,
+		CursorCreatedAt: cursorCreatedAt,
+		Limit:           page.FetchLimit(),
 	})
 	if err != nil {
 		response.InternalServerError(w, "Failed to list messages")
 		return
 	}
+	messages, more := pagination.Trim(messages, page)
 
 	// Convert to response format
 	var messageResponses []MessageResponse
@@ -166,11 +156,12 @@ func (h *MessageHandler) ListMessagesByProject(w http.ResponseWriter, r *http.Re
 		messageResponses = append(messageResponses, messageResp)
 	}
 
-	response.JSON(w, http.StatusOK, map[string]interface{}{
-		"messages": messageResponses,
-		"limit":    limit,
-		"offset":   offset,
-	})
+	var next *pagination.Cursor
+	if more {
+		last := messages[len(messages)-1]
+		next = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	response.Paginated(w, r, "messages", messageResponses, page, next)
 }
 
 // CreateMessage handles message creation
@@ -198,12 +189,7 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.MessageCreate) {
 		return
 	}
 
@@ -250,6 +236,15 @@ func (h *MessageHandler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID3,
+		ActorID:    userUUID2,
+		Resource:   activity.ResourceMessage,
+		ResourceID: message.ID,
+		Action:     activity.ActionCreated,
+		After:      message,
+	})
+
 	// Get sender details for full response
 	sender, _ := h.queries.GetUserByID(r.Context(), userUUID2)
 
@@ -325,12 +320,7 @@ func (h *MessageHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.MessageView) {
 		return
 	}
 
@@ -598,21 +588,16 @@ func (h *MessageHandler) GetWebSocketStatus(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	totalClients, matchingClients, userIDs := h.hub.GetProjectConnections(projectID)
-
-	clientDetails := make([]map[string]string, len(userIDs))
-	for i, userID := range userIDs {
-		clientDetails[i] = map[string]string{
-			"userId":    userID,
-			"projectId": projectID,
-		}
-	}
+	totalClients, matchingClients, _ := h.hub.GetProjectConnections(projectID)
 
+	// Per-connection send metrics: a growing queued count or any dropped
+	// messages point at a slow consumer
 	response.JSON(w, http.StatusOK, map[string]interface{}{
 		"projectId":       projectID,
 		"totalClients":    totalClients,
 		"matchingClients": matchingClients,
-		"clients":         clientDetails,
+		"evictedClients":  h.hub.Evictions(),
+		"clients":         h.hub.ProjectStats(projectID),
 	})
 }
 
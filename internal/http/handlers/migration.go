@@ -2,17 +2,24 @@ package handlers
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os/exec"
-	"path/filepath"
+	"regexp"
 	"strings"
 
+	"devhive-backend/cmd/devhive-api/migrations"
+	schema "devhive-backend/db"
 	"devhive-backend/internal/http/response"
 	"devhive-backend/internal/repo"
 )
 
+// migrationName matches a bare NNN_name.sql up migration, so a script name
+// can never reach outside the embedded migrations
+var migrationName = regexp.MustCompile(`^\d+_[A-Za-z0-9_]+\.sql$`)
+
 type MigrationHandler struct {
 	queries *repo.Queries
 	db      *sql.DB
@@ -45,46 +52,47 @@ func (h *MigrationHandler) RunMigration(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if req.ScriptName == "" {
-		response.BadRequest(w, "script name is required")
+	if !h.runMigration(w, r, req.ScriptName) {
 		return
 	}
 
-	// Read the migration script
-	// Try multiple possible paths for migrations directory
-	migrationsDirs := []string{
-		"cmd/devhive-api/migrations",
-		"./cmd/devhive-api/migrations",
-		"migrations",
-		"./migrations",
-	}
+	response.JSON(w, http.StatusOK, MigrationResponse{
+		Success: true,
+		Message: fmt.Sprintf("Successfully executed migration script: %s", req.ScriptName),
+	})
+}
 
-	var scriptContent []byte
-	var err error
-	for _, dir := range migrationsDirs {
-		scriptPath := filepath.Join(dir, req.ScriptName)
-		scriptContent, err = ioutil.ReadFile(scriptPath)
-		if err == nil {
-			break
-		}
+// runMigration applies one embedded migration through the migrator, which
+// holds the migration lock and records it in schema_migrations. It writes
+// the error response and returns false on failure.
+func (h *MigrationHandler) runMigration(w http.ResponseWriter, r *http.Request, name string) bool {
+	if name == "" {
+		response.BadRequest(w, "script name is required")
+		return false
 	}
-
-	if err != nil {
-		response.BadRequest(w, fmt.Sprintf("failed to read script %s (tried paths: %v): %v", req.ScriptName, migrationsDirs, err))
-		return
+	if !migrationName.MatchString(name) {
+		response.BadRequest(w, "script name must be a migration file name like 001_initial_schema.sql")
+		return false
 	}
 
-	// Execute the SQL script
-	_, err = h.db.Exec(string(scriptContent))
+	migrator, err := schema.NewMigrator(h.db, migrations.FS)
 	if err != nil {
+		response.InternalServerError(w, fmt.Sprintf("failed to load migrations: %v", err))
+		return false
+	}
+	err = migrator.Apply(r.Context(), name)
+	switch {
+	case errors.Is(err, schema.ErrUnknownMigration):
+		response.NotFound(w, fmt.Sprintf("migration %s not found", name))
+		return false
+	case errors.Is(err, schema.ErrMigrationApplied):
+		response.Conflict(w, fmt.Sprintf("migration %s is already applied", name))
+		return false
+	case err != nil:
 		response.InternalServerError(w, fmt.Sprintf("failed to execute script: %v", err))
-		return
+		return false
 	}
-
-	response.JSON(w, http.StatusOK, MigrationResponse{
-		Success: true,
-		Message: fmt.Sprintf("Successfully executed migration script: %s", req.ScriptName),
-	})
+	return true
 }
 
 // ResetDatabase handles resetting the entire database
@@ -162,22 +170,7 @@ func (h *MigrationHandler) RunMigrationAndDeploy(w http.ResponseWriter, r *http.
 		return
 	}
 
-	if req.ScriptName == "" {
-		response.BadRequest(w, "script name is required")
-		return
-	}
-
-	// Read and execute the migration script
-	scriptPath := filepath.Join("migrations", req.ScriptName)
-	scriptContent, err := ioutil.ReadFile(scriptPath)
-	if err != nil {
-		response.BadRequest(w, fmt.Sprintf("failed to read script %s: %v", req.ScriptName, err))
-		return
-	}
-
-	_, err = h.db.Exec(string(scriptContent))
-	if err != nil {
-		response.InternalServerError(w, fmt.Sprintf("failed to execute script: %v", err))
+	if !h.runMigration(w, r, req.ScriptName) {
 		return
 	}
 
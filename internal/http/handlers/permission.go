@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/permission"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// authorize checks that the user's project role grants action, writing the
+// error response and returning false when it does not
+func authorize(w http.ResponseWriter, r *http.Request, lookup permission.RoleLookup, projectID, userID uuid.UUID, action permission.Action) bool {
+	_, err := permission.Authorize(r.Context(), lookup, projectID, userID, action)
+	return middleware.WritePermissionError(w, err)
+}
+
+// authorizeProject parses the {projectId} route parameter and checks that the
+// authenticated user's role there grants action
+func authorizeProject(w http.ResponseWriter, r *http.Request, lookup permission.RoleLookup, action permission.Action) (uuid.UUID, bool) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return uuid.Nil, false
+	}
+	projectUUID, err := uuid.Parse(chi.URLParam(r, "projectId"))
+	if err != nil {
+		response.BadRequest(w, "Invalid project ID")
+		return uuid.Nil, false
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	if !authorize(w, r, lookup, projectUUID, userUUID, action) {
+		return uuid.Nil, false
+	}
+	return projectUUID, true
+}
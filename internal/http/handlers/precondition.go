@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"devhive-backend/internal/http/response"
+
+	"github.com/google/uuid"
+)
+
+// versionETag is the strong ETag of a project, sprint or task; the row
+// version changes on every write
+func versionETag(id uuid.UUID, version int32) string {
+	return `"` + id.String() + `-` + strconv.FormatInt(int64(version), 10) + `"`
+}
+
+// checkIfMatch enforces the request's If-Match header against the resource's
+// current version. It returns the version the update must still find, or nil
+// when the request has no If-Match. On a mismatch it writes 412 with the
+// representation built by current and returns false.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, id uuid.UUID, version int32, current func() (interface{}, error)) (*int32, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return nil, true
+	}
+
+	etag := versionETag(id, version)
+	if !response.VersionTagMatches(ifMatch, etag) {
+		writePreconditionFailed(w, etag, current)
+		return nil, false
+	}
+	return &version, true
+}
+
+// writePreconditionFailed sends 412 with the resource's current representation
+func writePreconditionFailed(w http.ResponseWriter, etag string, current func() (interface{}, error)) {
+	resp, err := current()
+	if err != nil {
+		response.InternalServerError(w, "Failed to load current version")
+		return
+	}
+	response.PreconditionFailed(w, etag, resp)
+}
@@ -2,29 +2,40 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
+	"devhive-backend/internal/activity"
 	"devhive-backend/internal/broadcast"
+	"devhive-backend/internal/config"
 	"devhive-backend/internal/http/middleware"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/mail"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
 	"devhive-backend/internal/repo"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type ProjectHandler struct {
 	queries *repo.Queries
+	cfg     *config.Config
+	mailer  mail.Mailer
 }
 
-func NewProjectHandler(queries *repo.Queries) *ProjectHandler {
+func NewProjectHandler(queries *repo.Queries, cfg *config.Config, mailer mail.Mailer) *ProjectHandler {
 	return &ProjectHandler{
 		queries: queries,
+		cfg:     cfg,
+		mailer:  mailer,
 	}
 }
 
@@ -48,6 +59,7 @@ type ProjectResponse struct {
 	Description string `json:"description"`
 	CreatedAt   string `json:"createdAt"`
 	UpdatedAt   string `json:"updatedAt"`
+	Version     int32  `json:"version"`
 	Owner       struct {
 		ID        string `json:"id"`
 		Username  string `json:"username"`
@@ -55,13 +67,17 @@ type ProjectResponse struct {
 		FirstName string `json:"firstName"`
 		LastName  string `json:"lastName"`
 	} `json:"owner"`
-	UserRole    *string `json:"userRole,omitempty"` // Current user's role: "owner", "admin", "member", "viewer"
-	Permissions struct {
-		CanViewInvites   bool `json:"canViewInvites"`
-		CanCreateInvites bool `json:"canCreateInvites"`
-		CanRevokeInvites bool `json:"canRevokeInvites"`
-		CanManageMembers bool `json:"canManageMembers"`
-	} `json:"permissions,omitempty"`
+	UserRole    *string            `json:"userRole,omitempty"` // Current user's role: "owner", "admin", "member", "viewer"
+	Permissions ProjectPermissions `json:"permissions,omitempty"`
+}
+
+// ProjectPermissions describes what the current user may do in a project
+type ProjectPermissions struct {
+	CanViewInvites   bool                `json:"canViewInvites"`
+	CanCreateInvites bool                `json:"canCreateInvites"`
+	CanRevokeInvites bool                `json:"canRevokeInvites"`
+	CanManageMembers bool                `json:"canManageMembers"`
+	Actions          []permission.Action `json:"actions,omitempty"` // Every action granted by the role, e.g. "task.create"
 }
 
 // ListProjects handles listing projects for a user
@@ -72,20 +88,9 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Parse pagination parameters
-	limit := 20
-	offset := 0
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
-
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
 	}
 
 	userUUID, err := uuid.Parse(userID)
@@ -93,18 +98,21 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
+	cursorID, cursorCreatedAt := page.Keyset()
 	projects, err := h.queries.ListProjectsByUser(r.Context(), repo.ListProjectsByUserParams{
-		OwnerID: userUUID,
-		Limit:   int32(limit),
-		Offset:  int32(offset),
+		UserID:          userUUID,
+		CursorID:        cursorID,
+		CursorCreatedAt: cursorCreatedAt,
+		Limit:           page.FetchLimit(),
 	})
 	if err != nil {
 		response.InternalServerError(w, "Failed to list projects")
 		return
 	}
+	projects, more := pagination.Trim(projects, page)
 
 	// Convert to response format with user role and permissions
-	var projectResponses []ProjectResponse
+	projectResponses := make([]ProjectResponse, 0, len(projects))
 	for _, project := range projects {
 		// Get user's role and permissions for each project
 		userRole, permissions := h.getUserRoleAndPermissions(r.Context(), project.ID, userUUID)
@@ -116,6 +124,7 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 			Description: *project.Description,
 			CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Version:     project.Version,
 			Owner: struct {
 				ID        string `json:"id"`
 				Username  string `json:"username"`
@@ -136,11 +145,12 @@ func (h *ProjectHandler) ListProjects(w http.ResponseWriter, r *http.Request) {
 		projectResponses = append(projectResponses, projectResponse)
 	}
 
-	response.JSON(w, http.StatusOK, map[string]interface{}{
-		"projects": projectResponses,
-		"limit":    limit,
-		"offset":   offset,
-	})
+	var next *pagination.Cursor
+	if more {
+		last := projects[len(projects)-1]
+		next = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	response.Paginated(w, r, "projects", projectResponses, page, next)
 }
 
 // CreateProject handles project creation
@@ -174,7 +184,7 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 	// CRITICAL: Insert owner into project_members table (canonical model)
 	// This ensures owners appear in member lists and all queries work consistently
 	// Idempotent: AddProjectMember uses ON CONFLICT, so this is safe to call multiple times
-	err = h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
+	_, err = h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
 		ProjectID: project.ID,
 		UserID:    userUUID,
 		Role:      "owner",
@@ -187,6 +197,15 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  project.ID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceProject,
+		ResourceID: project.ID,
+		Action:     activity.ActionCreated,
+		After:      project,
+	})
+
 	// Get user's role and permissions for the response
 	userRole, permissions := h.getUserRoleAndPermissions(r.Context(), project.ID, userUUID)
 
@@ -202,6 +221,7 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 			Description: *project.Description,
 			CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Version:     project.Version,
 			UserRole:    userRole,
 			Permissions: permissions,
 		})
@@ -215,6 +235,7 @@ func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
 		Description: *project.Description,
 		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     project.Version,
 		Owner: struct {
 			ID        string `json:"id"`
 			Username  string `json:"username"`
@@ -258,12 +279,7 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.ProjectView) {
 		return
 	}
 
@@ -273,37 +289,37 @@ func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get user's role and permissions
-	userRole, permissions := h.getUserRoleAndPermissions(r.Context(), projectUUID, userUUID)
+	// Add the user's role and permissions
+	projectResp := buildProjectResponse(project)
+	projectResp.UserRole, projectResp.Permissions = h.getUserRoleAndPermissions(r.Context(), projectUUID, userUUID)
 
-	response.JSON(w, http.StatusOK, ProjectResponse{
+	w.Header().Set("ETag", versionETag(project.ID, project.Version))
+	response.JSON(w, http.StatusOK, projectResp)
+}
+
+// buildProjectResponse converts GetProjectByIDRow to ProjectResponse without
+// the caller's role and permissions
+func buildProjectResponse(project repo.GetProjectByIDRow) ProjectResponse {
+	projectResp := ProjectResponse{
 		ID:          project.ID.String(),
 		OwnerID:     project.OwnerID.String(),
 		Name:        project.Name,
 		Description: *project.Description,
 		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Owner: struct {
-			ID        string `json:"id"`
-			Username  string `json:"username"`
-			Email     string `json:"email"`
-			FirstName string `json:"firstName"`
-			LastName  string `json:"lastName"`
-		}{
-			ID:        project.OwnerID.String(),
-			Username:  project.OwnerUsername,
-			Email:     project.OwnerEmail,
-			FirstName: project.OwnerFirstName,
-			LastName:  project.OwnerLastName,
-		},
-		UserRole:    userRole,
-		Permissions: permissions,
-	})
+		Version:     project.Version,
+	}
+	projectResp.Owner.ID = project.OwnerID.String()
+	projectResp.Owner.Username = project.OwnerUsername
+	projectResp.Owner.Email = project.OwnerEmail
+	projectResp.Owner.FirstName = project.OwnerFirstName
+	projectResp.Owner.LastName = project.OwnerLastName
+	return projectResp
 }
 
 // GetProjectBundle handles getting a project with optional includes (members, owner, etc.)
 func (h *ProjectHandler) GetProjectBundle(w http.ResponseWriter, r *http.Request) {
-	_, ok := middleware.GetUserIDFromContext(r.Context())
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "User ID not found in context")
 		return
@@ -321,12 +337,21 @@ func (h *ProjectHandler) GetProjectBundle(w http.ResponseWriter, r *http.Request
 	includeOwner := strings.Contains(include, "owner")
 	includeSprints := strings.Contains(include, "sprints")
 	includeTasks := strings.Contains(include, "tasks")
+	includeLabels := includeTasks || strings.Contains(include, "labels")
 
 	projectUUID, err := uuid.Parse(projectID)
 	if err != nil {
 		response.BadRequest(w, "Invalid project ID")
 		return
 	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.ProjectView) {
+		return
+	}
 
 	// Get project
 	project, err := h.queries.GetProjectByID(r.Context(), projectUUID)
@@ -344,6 +369,7 @@ func (h *ProjectHandler) GetProjectBundle(w http.ResponseWriter, r *http.Request
 			Description: *project.Description,
 			CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Version:     project.Version,
 		},
 	}
 
@@ -371,7 +397,6 @@ func (h *ProjectHandler) GetProjectBundle(w http.ResponseWriter, r *http.Request
 		sprints, err := h.queries.ListSprintsByProject(r.Context(), repo.ListSprintsByProjectParams{
 			ProjectID: projectUUID,
 			Limit:     50,
-			Offset:    0,
 		})
 		if err == nil {
 			bundle["sprints"] = sprints
@@ -383,16 +408,33 @@ func (h *ProjectHandler) GetProjectBundle(w http.ResponseWriter, r *http.Request
 		tasks, err := h.queries.ListTasksByProject(r.Context(), repo.ListTasksByProjectParams{
 			ProjectID: projectUUID,
 			Limit:     50,
-			Offset:    0,
 		})
 		if err == nil {
-			bundle["tasks"] = tasks
+			taskResponses := make([]TaskResponse, 0, len(tasks))
+			for _, task := range tasks {
+				taskResponses = append(taskResponses, buildTaskResponse(repo.GetTaskByIDRow(task)))
+			}
+			if err := attachTaskLabels(r.Context(), h.queries, taskResponses); err == nil {
+				bundle["tasks"] = taskResponses
+			}
 		}
 	}
 
-	// Set caching headers
+	// Add labels if requested (always with tasks, so label filters can be rendered)
+	if includeLabels {
+		labels, err := h.queries.ListProjectLabels(r.Context(), projectUUID)
+		if err == nil {
+			labelResponses := make([]LabelResponse, 0, len(labels))
+			for _, label := range labels {
+				labelResponses = append(labelResponses, buildLabelResponse(label))
+			}
+			bundle["labels"] = labelResponses
+		}
+	}
+
+	// The bundle's ETag comes from the cache middleware, which hashes the body
+	// so member and label changes also invalidate it
 	w.Header().Set("Cache-Control", "private, max-age=60")
-	w.Header().Set("ETag", `"`+project.ID.String()+`-`+strconv.FormatInt(project.UpdatedAt.Unix(), 10)+`"`)
 
 	response.JSON(w, http.StatusOK, bundle)
 }
@@ -422,12 +464,7 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.ProjectUpdate) {
 		return
 	}
 
@@ -443,6 +480,14 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject writes based on a stale copy of the project
+	expectedVersion, ok := checkIfMatch(w, r, projectUUID, currentProject.Version, func() (interface{}, error) {
+		return buildProjectResponse(currentProject), nil
+	})
+	if !ok {
+		return
+	}
+
 	// Merge updates
 	name := currentProject.Name
 	description := *currentProject.Description
@@ -455,15 +500,30 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	project, err := h.queries.UpdateProject(r.Context(), repo.UpdateProjectParams{
-		ID:          projectUUID,
-		Name:        name,
-		Description: &description,
+		ID:              projectUUID,
+		Name:            name,
+		Description:     &description,
+		ExpectedVersion: expectedVersion,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.writeProjectConflict(w, r, projectUUID)
+		return
+	}
 	if err != nil {
 		response.BadRequest(w, "Failed to update project: "+err.Error())
 		return
 	}
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceProject,
+		ResourceID: projectUUID,
+		Action:     activity.ActionUpdated,
+		Before:     currentProject,
+		After:      project,
+	})
+
 	projectResp := ProjectResponse{
 		ID:          project.ID.String(),
 		OwnerID:     project.OwnerID.String(),
@@ -471,14 +531,29 @@ func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
 		Description: *project.Description,
 		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     project.Version,
 	}
 
 	// Broadcast project updated event
 	broadcast.Send(r.Context(), projectID, broadcast.EventProjectUpdated, projectResp)
 
+	w.Header().Set("ETag", versionETag(project.ID, project.Version))
 	response.JSON(w, http.StatusOK, projectResp)
 }
 
+// writeProjectConflict answers an update that found the project changed or
+// deleted after it was read
+func (h *ProjectHandler) writeProjectConflict(w http.ResponseWriter, r *http.Request, projectID uuid.UUID) {
+	project, err := h.queries.GetProjectByID(r.Context(), projectID)
+	if err != nil {
+		response.NotFound(w, "Project not found")
+		return
+	}
+	writePreconditionFailed(w, versionETag(project.ID, project.Version), func() (interface{}, error) {
+		return buildProjectResponse(project), nil
+	})
+}
+
 // DeleteProject handles project deletion
 func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -504,20 +579,32 @@ func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.ProjectDelete) {
 		return
 	}
 
-	err = h.queries.DeleteProject(r.Context(), projectUUID)
+	// Projects move to the trash and are purged after the retention period
+	rows, err := h.queries.SoftDeleteProject(r.Context(), projectUUID)
 	if err != nil {
 		response.InternalServerError(w, "Failed to delete project")
 		return
 	}
+	if rows == 0 {
+		response.NotFound(w, "Project not found")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceProject,
+		ResourceID: projectUUID,
+		Action:     activity.ActionDeleted,
+	})
+
+	broadcast.Send(r.Context(), projectID, broadcast.EventProjectDeleted, map[string]string{
+		"id": projectID,
+	})
 
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Project deleted successfully"})
 }
@@ -548,12 +635,8 @@ func (h *ProjectHandler) AddMember(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	actorRole, err := permission.Authorize(r.Context(), h.queries, projectUUID, userUUID, permission.MemberManage)
+	if !middleware.WritePermissionError(w, err) {
 		return
 	}
 
@@ -563,7 +646,25 @@ func (h *ProjectHandler) AddMember(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
+	// Validate the requested role and that the caller may grant it
+	newRole, err := permission.ParseRole(role)
+	if err != nil {
+		response.BadRequest(w, "Invalid role")
+		return
+	}
+	if !permission.CanAssignRole(actorRole, newRole) {
+		response.Forbidden(w, "You cannot assign the "+role+" role")
+		return
+	}
+
+	// Adding an existing member updates their role, so the caller must also outrank their current role
+	currentRole, err := permission.ProjectRole(r.Context(), h.queries, projectUUID, memberUUID)
+	if err == nil && !permission.CanAssignRole(actorRole, currentRole) {
+		response.Forbidden(w, "You cannot change the role of a project "+string(currentRole))
+		return
+	}
+
+	added, err := h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
 		ProjectID: projectUUID,
 		UserID:    memberUUID,
 		Role:      role,
@@ -572,6 +673,26 @@ func (h *ProjectHandler) AddMember(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Failed to add member: "+err.Error())
 		return
 	}
+	if added == 0 {
+		// The user became the owner since the role check
+		response.Conflict(w, "The project owner's role cannot be changed; transfer ownership instead")
+		return
+	}
+
+	// Re-adding an existing member only changes their role
+	memberEvent := activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceMember,
+		ResourceID: memberUUID,
+		Action:     activity.ActionCreated,
+		After:      map[string]string{"role": role},
+	}
+	if currentRole != "" {
+		memberEvent.Action = activity.ActionRoleChanged
+		memberEvent.Before = map[string]string{"role": string(currentRole)}
+	}
+	activity.Record(r.Context(), h.queries, memberEvent)
 
 	// Broadcast member added event
 	broadcast.Send(r.Context(), projectID, broadcast.EventMemberAdded, map[string]string{
@@ -615,23 +736,42 @@ func (h *ProjectHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	memberUUID, err := uuid.Parse(memberID)
+	if err != nil {
+		response.BadRequest(w, "Invalid member ID")
 		return
 	}
 
-	memberUUID, err := uuid.Parse(memberID)
+	// Members may leave on their own; removing anyone else requires member.manage
+	// and outranking the member being removed
+	action := permission.MemberManage
+	if memberUUID == userUUID {
+		action = permission.ProjectView
+	}
+	actorRole, err := permission.Authorize(r.Context(), h.queries, projectUUID, userUUID, action)
+	if !middleware.WritePermissionError(w, err) {
+		return
+	}
+	memberRole, err := permission.ProjectRole(r.Context(), h.queries, projectUUID, memberUUID)
+	if errors.Is(err, permission.ErrNotMember) {
+		response.NotFound(w, "Member not found")
+		return
+	}
 	if err != nil {
-		response.BadRequest(w, "Invalid member ID")
+		response.InternalServerError(w, "Failed to get member role")
+		return
+	}
+	if memberRole == permission.RoleOwner {
+		response.Forbidden(w, "The project owner cannot be removed; transfer ownership first")
+		return
+	}
+	if memberUUID != userUUID && !permission.CanAssignRole(actorRole, memberRole) {
+		response.Forbidden(w, "You cannot remove a project "+string(memberRole))
 		return
 	}
 
 	log.Printf("RemoveMember: Removing user %s from project %s", memberUUID.String(), projectUUID.String())
-	err = h.queries.RemoveProjectMember(r.Context(), repo.RemoveProjectMemberParams{
+	removed, err := h.queries.RemoveProjectMember(r.Context(), repo.RemoveProjectMemberParams{
 		ProjectID: projectUUID,
 		UserID:    memberUUID,
 	})
@@ -640,6 +780,24 @@ func (h *ProjectHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Failed to remove member: "+err.Error())
 		return
 	}
+	if removed == 0 {
+		// The member became the owner or left since the role check
+		response.Conflict(w, "The member is the project owner or no longer a member")
+		return
+	}
+
+	removeAction := activity.ActionRemoved
+	if memberUUID == userUUID {
+		removeAction = activity.ActionLeft
+	}
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceMember,
+		ResourceID: memberUUID,
+		Action:     removeAction,
+		Before:     map[string]string{"role": string(memberRole)},
+	})
 
 	// Broadcast member removed event (cache invalidation)
 	payload := map[string]any{
@@ -655,6 +813,236 @@ func (h *ProjectHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Member removed successfully"})
 }
 
+// UpdateMemberRoleRequest represents the request to change a member's role
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// UpdateMemberRole handles changing an existing member's role
+func (h *ProjectHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	memberID := chi.URLParam(r, "userId")
+
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		response.BadRequest(w, "Invalid project ID")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	memberUUID, err := uuid.Parse(memberID)
+	if err != nil {
+		response.BadRequest(w, "Invalid member ID")
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+
+	newRole, err := permission.ParseRole(req.Role)
+	if err != nil {
+		response.BadRequest(w, "Invalid role")
+		return
+	}
+	if newRole == permission.RoleOwner {
+		response.BadRequest(w, "Use transfer-ownership to make a member the project owner")
+		return
+	}
+
+	actorRole, err := permission.Authorize(r.Context(), h.queries, projectUUID, userUUID, permission.MemberManage)
+	if !middleware.WritePermissionError(w, err) {
+		return
+	}
+
+	currentRole, err := permission.ProjectRole(r.Context(), h.queries, projectUUID, memberUUID)
+	if errors.Is(err, permission.ErrNotMember) {
+		response.NotFound(w, "Member not found")
+		return
+	}
+	if err != nil {
+		response.InternalServerError(w, "Failed to get member role")
+		return
+	}
+	if currentRole == permission.RoleOwner {
+		response.Conflict(w, "The project owner's role cannot be changed; transfer ownership first")
+		return
+	}
+	if !permission.CanAssignRole(actorRole, currentRole) || !permission.CanAssignRole(actorRole, newRole) {
+		response.Forbidden(w, "You cannot change a project "+string(currentRole)+" to "+string(newRole))
+		return
+	}
+
+	member, err := h.queries.UpdateProjectMemberRole(r.Context(), repo.UpdateProjectMemberRoleParams{
+		Role:      string(newRole),
+		ProjectID: projectUUID,
+		UserID:    memberUUID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The member left or became owner between the checks above and the update
+		response.Conflict(w, "Member changed concurrently; reload and try again")
+		return
+	}
+	if err != nil {
+		log.Printf("UpdateMemberRole: Failed to update role: %v", err)
+		response.InternalServerError(w, "Failed to update member role")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceMember,
+		ResourceID: memberUUID,
+		Action:     activity.ActionRoleChanged,
+		Before:     map[string]string{"role": string(currentRole)},
+		After:      map[string]string{"role": member.Role},
+	})
+
+	h.broadcastRoleChange(r.Context(), projectID, memberID, string(currentRole), member.Role)
+
+	response.JSON(w, http.StatusOK, map[string]string{
+		"projectId":    projectID,
+		"userId":       memberID,
+		"role":         member.Role,
+		"previousRole": string(currentRole),
+	})
+}
+
+// TransferOwnershipRequest represents the request to transfer project ownership
+type TransferOwnershipRequest struct {
+	UserID string `json:"userId"`
+}
+
+// TransferOwnership hands the project to another member. The current owner
+// becomes an admin; the swap is a single statement so the project always has
+// exactly one owner.
+func (h *ProjectHandler) TransferOwnership(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		response.BadRequest(w, "Invalid project ID")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req TransferOwnershipRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	newOwnerUUID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		response.BadRequest(w, "Invalid new owner ID")
+		return
+	}
+	if newOwnerUUID == userUUID {
+		response.BadRequest(w, "You already own this project")
+		return
+	}
+
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.ProjectTransfer) {
+		return
+	}
+
+	previousRole, err := permission.ProjectRole(r.Context(), h.queries, projectUUID, newOwnerUUID)
+	if errors.Is(err, permission.ErrNotMember) {
+		response.BadRequest(w, "The new owner must already be a project member")
+		return
+	}
+	if err != nil {
+		response.InternalServerError(w, "Failed to get member role")
+		return
+	}
+
+	project, err := h.queries.TransferProjectOwnership(r.Context(), repo.TransferProjectOwnershipParams{
+		NewOwnerID:     newOwnerUUID,
+		ProjectID:      projectUUID,
+		CurrentOwnerID: userUUID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Ownership or membership changed between the checks above and the update
+		response.Conflict(w, "Project ownership changed concurrently; reload and try again")
+		return
+	}
+	if err != nil {
+		log.Printf("TransferOwnership: Failed to transfer project %s: %v", projectID, err)
+		response.InternalServerError(w, "Failed to transfer ownership")
+		return
+	}
+
+	log.Printf("TransferOwnership: Project %s transferred from %s to %s", projectID, userID, req.UserID)
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceProject,
+		ResourceID: projectUUID,
+		Action:     activity.ActionOwnershipTransferred,
+		Before:     map[string]string{"ownerId": userID, "newOwnerRole": string(previousRole)},
+		After:      map[string]string{"ownerId": req.UserID, "previousOwnerRole": string(permission.RoleAdmin)},
+	})
+
+	projectResp := ProjectResponse{
+		ID:          project.ID.String(),
+		OwnerID:     project.OwnerID.String(),
+		Name:        project.Name,
+		Description: *project.Description,
+		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     project.Version,
+	}
+
+	broadcast.Send(r.Context(), projectID, broadcast.EventOwnershipTransferred, map[string]string{
+		"projectId":     projectID,
+		"previousOwner": userID,
+		"newOwner":      req.UserID,
+	})
+	broadcast.Send(r.Context(), projectID, broadcast.EventProjectUpdated, projectResp)
+	h.broadcastRoleChange(r.Context(), projectID, req.UserID, string(previousRole), string(permission.RoleOwner))
+	h.broadcastRoleChange(r.Context(), projectID, userID, string(permission.RoleOwner), string(permission.RoleAdmin))
+
+	response.JSON(w, http.StatusOK, projectResp)
+}
+
+// broadcastRoleChange sends the member_role_changed event and the matching cache invalidation
+func (h *ProjectHandler) broadcastRoleChange(ctx context.Context, projectID, memberID, previousRole, role string) {
+	broadcast.Send(ctx, projectID, broadcast.EventMemberRoleChanged, map[string]string{
+		"userId":       memberID,
+		"projectId":    projectID,
+		"role":         role,
+		"previousRole": previousRole,
+	})
+
+	payload := map[string]any{
+		"resource":  "project_members",
+		"action":    "UPDATE",
+		"id":        fmt.Sprintf("%s:%s", projectID, memberID),
+		"projectId": projectID,
+		"timestamp": time.Now().UTC(),
+	}
+	broadcast.Send(ctx, projectID, broadcast.EventCacheInvalidate, payload)
+}
+
 // ListMembers handles listing project members
 func (h *ProjectHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -680,12 +1068,7 @@ func (h *ProjectHandler) ListMembers(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.MemberView) {
 		return
 	}
 
@@ -792,6 +1175,7 @@ func (h *ProjectHandler) JoinProject(w http.ResponseWriter, r *http.Request) {
 			Description: *project.Description,
 			CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Version:     project.Version,
 			Owner: struct {
 				ID        string `json:"id"`
 				Username  string `json:"username"`
@@ -812,7 +1196,7 @@ func (h *ProjectHandler) JoinProject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Add user as a member
-	err = h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
+	joined, err := h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
 		ProjectID: projectUUID,
 		UserID:    userUUID,
 		Role:      "member",
@@ -821,6 +1205,19 @@ func (h *ProjectHandler) JoinProject(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Failed to join project: "+err.Error())
 		return
 	}
+	if joined == 0 {
+		response.Conflict(w, "You already own this project")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceMember,
+		ResourceID: userUUID,
+		Action:     activity.ActionJoined,
+		After:      map[string]string{"role": "member"},
+	})
 
 	// Broadcast member added event
 	broadcast.Send(r.Context(), req.ProjectID, broadcast.EventMemberAdded, map[string]string{
@@ -856,6 +1253,7 @@ func (h *ProjectHandler) JoinProject(w http.ResponseWriter, r *http.Request) {
 		Description: *project.Description,
 		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     project.Version,
 		Owner: struct {
 			ID        string `json:"id"`
 			Username  string `json:"username"`
@@ -908,13 +1306,35 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if invite has expired
-	// Note: This should be handled by the database query, but we check here too
+	if invite.ExpiresAt.Before(time.Now()) {
+		response.BadRequest(w, "Invite has expired")
+		return
+	}
+
 	// Check max uses
 	if invite.MaxUses != nil && invite.UsedCount >= *invite.MaxUses {
 		response.BadRequest(w, "Invite has reached maximum uses")
 		return
 	}
 
+	// Email invites can only be accepted by the recipient with a verified email;
+	// other invites require a verified email when configured
+	if invite.RecipientEmail != nil || h.cfg.EmailVerification.Required {
+		user, err := h.queries.GetUserByID(r.Context(), userUUID)
+		if err != nil {
+			response.InternalServerError(w, "Failed to get user")
+			return
+		}
+		if !user.EmailVerifiedAt.Valid {
+			response.Problemf(w, http.StatusForbidden, "email_not_verified", "Verify your email address before accepting this invite")
+			return
+		}
+		if invite.RecipientEmail != nil && !strings.EqualFold(user.Email, *invite.RecipientEmail) {
+			response.Forbidden(w, "This invite was sent to a different email address")
+			return
+		}
+	}
+
 	// Check if user is already a member
 	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
 		ProjectID: invite.ProjectID,
@@ -925,12 +1345,8 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if hasAccess {
-		// User is already a member, just increment the use count and return success
-		err = h.queries.IncrementInviteUseCount(r.Context(), invite.ID)
-		if err != nil {
-			// Log but don't fail
-			log.Printf("Failed to increment invite use count: %v", err)
-		}
+		// User is already a member, just record the use and return success
+		h.recordInviteUse(r.Context(), invite, userUUID)
 		project, err := h.queries.GetProjectByID(r.Context(), invite.ProjectID)
 		if err != nil {
 			response.InternalServerError(w, "Failed to get project")
@@ -945,6 +1361,7 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 			Description: *project.Description,
 			CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			Version:     project.Version,
 			Owner: struct {
 				ID        string `json:"id"`
 				Username  string `json:"username"`
@@ -967,7 +1384,7 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 	// Add user as a member
 	log.Printf("AcceptInvite: Adding user %s to project %s", userUUID.String(), invite.ProjectID.String())
 	log.Printf("AcceptInvite: About to execute AddProjectMember query - this should trigger PostgreSQL NOTIFY")
-	err = h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
+	joined, err := h.queries.AddProjectMember(r.Context(), repo.AddProjectMemberParams{
 		ProjectID: invite.ProjectID,
 		UserID:    userUUID,
 		Role:      "member",
@@ -977,8 +1394,30 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Failed to join project: "+err.Error())
 		return
 	}
+	if joined == 0 {
+		response.Conflict(w, "You already own this project")
+		return
+	}
 	log.Printf("AcceptInvite: ✅ Database INSERT/UPDATE completed for user %s in project %s", userUUID.String(), invite.ProjectID.String())
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  invite.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceMember,
+		ResourceID: userUUID,
+		Action:     activity.ActionJoined,
+		After:      map[string]string{"role": "member", "inviteId": invite.ID.String()},
+	})
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  invite.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceInvite,
+		ResourceID: invite.ID,
+		Action:     activity.ActionAccepted,
+		Before:     map[string]any{"usedCount": invite.UsedCount},
+		After:      map[string]any{"usedCount": invite.UsedCount + 1},
+	})
+
 	// Broadcast member added event
 	broadcast.Send(r.Context(), invite.ProjectID.String(), broadcast.EventMemberAdded, map[string]string{
 		"userId":    userID,
@@ -996,12 +1435,8 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 	}
 	broadcast.Send(r.Context(), invite.ProjectID.String(), broadcast.EventCacheInvalidate, payload)
 
-	// Increment invite use count
-	err = h.queries.IncrementInviteUseCount(r.Context(), invite.ID)
-	if err != nil {
-		// Log but don't fail the request
-		log.Printf("Failed to increment invite use count: %v", err)
-	}
+	// Record invite use
+	h.recordInviteUse(r.Context(), invite, userUUID)
 
 	// Get the project to return
 	project, err := h.queries.GetProjectByID(r.Context(), invite.ProjectID)
@@ -1020,6 +1455,7 @@ func (h *ProjectHandler) AcceptInvite(w http.ResponseWriter, r *http.Request) {
 		Description: *project.Description,
 		CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   project.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     project.Version,
 		Owner: struct {
 			ID        string `json:"id"`
 			Username  string `json:"username"`
@@ -1067,13 +1503,15 @@ func (h *ProjectHandler) GetInviteDetails(w http.ResponseWriter, r *http.Request
 
 	response.JSON(w, http.StatusOK, map[string]interface{}{
 		"invite": map[string]interface{}{
-			"id":        invite.ID.String(),
-			"projectId": invite.ProjectID.String(),
-			"token":     invite.InviteToken,
-			"expiresAt": invite.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
-			"maxUses":   invite.MaxUses,
-			"usedCount": invite.UsedCount,
-			"isActive":  invite.IsActive,
+			"id":             invite.ID.String(),
+			"projectId":      invite.ProjectID.String(),
+			"token":          invite.InviteToken,
+			"expiresAt":      invite.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+			"maxUses":        invite.MaxUses,
+			"usedCount":      invite.UsedCount,
+			"isActive":       invite.IsActive,
+			"recipientEmail": invite.RecipientEmail,
+			"status":         inviteStatus(invite),
 		},
 		"project": map[string]interface{}{
 			"id":          project.ID.String(),
@@ -1085,8 +1523,9 @@ func (h *ProjectHandler) GetInviteDetails(w http.ResponseWriter, r *http.Request
 
 // CreateInviteRequest represents the request to create a project invite
 type CreateInviteRequest struct {
-	ExpiresInMinutes *int   `json:"expiresInMinutes"` // Optional, defaults to 30
-	MaxUses          *int32 `json:"maxUses"`          // Optional, nil = unlimited
+	ExpiresInMinutes *int    `json:"expiresInMinutes"` // Optional, defaults to 30 (link) or INVITE_EMAIL_EXPIRATION_HOURS (email)
+	MaxUses          *int32  `json:"maxUses"`          // Optional, nil = unlimited; always 1 for email invites
+	Email            *string `json:"email,omitempty"`  // Optional recipient; the invite link is mailed and only they can accept it
 }
 
 // CreateInvite handles creating a project invite
@@ -1115,13 +1554,8 @@ func (h *ProjectHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is owner or admin
-	isOwnerOrAdmin, err := h.queries.CheckProjectOwnerOrAdmin(r.Context(), repo.CheckProjectOwnerOrAdminParams{
-		ID:      projectUUID,
-		OwnerID: userUUID,
-	})
-	if err != nil || !isOwnerOrAdmin {
-		response.Forbidden(w, "Only project owners and admins can create invites")
+	// Check if user may create invites
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.InviteCreate) {
 		return
 	}
 
@@ -1137,36 +1571,66 @@ func (h *ProjectHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set expiration time (default 30 minutes)
-	expiresInMinutes := 30
+	// Email invites are single-use and addressed to one recipient
+	var recipientEmail *string
+	maxUses := req.MaxUses
+	if req.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		if !isValidEmail(email) {
+			response.BadRequest(w, "Invalid recipient email")
+			return
+		}
+		recipientEmail = &email
+		one := int32(1)
+		maxUses = &one
+	}
+
+	// Set expiration time (default 30 minutes for links, longer for email invites)
+	expiresIn := 30 * time.Minute
+	if recipientEmail != nil {
+		expiresIn = h.cfg.Invites.EmailTTL
+	}
 	if req.ExpiresInMinutes != nil && *req.ExpiresInMinutes > 0 {
-		expiresInMinutes = *req.ExpiresInMinutes
+		expiresIn = time.Duration(*req.ExpiresInMinutes) * time.Minute
 	}
-	expiresAt := time.Now().Add(time.Duration(expiresInMinutes) * time.Minute)
+	expiresAt := time.Now().Add(expiresIn)
 
 	// Create invite
 	invite, err := h.queries.CreateProjectInvite(r.Context(), repo.CreateProjectInviteParams{
-		ProjectID:   projectUUID,
-		CreatedBy:   userUUID,
-		InviteToken: inviteToken.String(),
-		ExpiresAt:   expiresAt,
-		MaxUses:     req.MaxUses,
+		ProjectID:      projectUUID,
+		CreatedBy:      userUUID,
+		InviteToken:    inviteToken.String(),
+		ExpiresAt:      expiresAt,
+		MaxUses:        maxUses,
+		RecipientEmail: recipientEmail,
 	})
 	if err != nil {
 		response.BadRequest(w, "Failed to create invite: "+err.Error())
 		return
 	}
 
-	response.JSON(w, http.StatusCreated, map[string]interface{}{
-		"id":        invite.ID.String(),
-		"projectId": invite.ProjectID.String(),
-		"token":     invite.InviteToken,
-		"expiresAt": invite.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
-		"maxUses":   invite.MaxUses,
-		"usedCount": invite.UsedCount,
-		"isActive":  invite.IsActive,
-		"createdAt": invite.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceInvite,
+		ResourceID: invite.ID,
+		Action:     activity.ActionCreated,
+		After:      inviteActivity(invite),
 	})
+
+	// Mail the invite link to the recipient
+	resp := inviteResponse(invite)
+	if recipientEmail != nil {
+		sent, err := h.sendInviteEmail(r.Context(), invite, userUUID, invite.ExpiresAt)
+		if err != nil {
+			log.Printf("Failed to send invite %s to %s: %v", invite.ID, *recipientEmail, err)
+		} else {
+			resp = inviteResponse(sent)
+		}
+		resp["emailSent"] = err == nil
+	}
+
+	response.JSON(w, http.StatusCreated, resp)
 }
 
 // ListInvites handles listing project invites
@@ -1196,12 +1660,7 @@ func (h *ProjectHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if user has access to project (any project member can view invites)
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.InviteView) {
 		return
 	}
 
@@ -1215,16 +1674,7 @@ func (h *ProjectHandler) ListInvites(w http.ResponseWriter, r *http.Request) {
 	// Convert to response format
 	var inviteResponses []map[string]interface{}
 	for _, invite := range invites {
-		inviteResponses = append(inviteResponses, map[string]interface{}{
-			"id":        invite.ID.String(),
-			"projectId": invite.ProjectID.String(),
-			"token":     invite.InviteToken,
-			"expiresAt": invite.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
-			"maxUses":   invite.MaxUses,
-			"usedCount": invite.UsedCount,
-			"isActive":  invite.IsActive,
-			"createdAt": invite.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		})
+		inviteResponses = append(inviteResponses, inviteResponse(invite))
 	}
 
 	// Ensure invites is always an array, never null
@@ -1265,13 +1715,8 @@ func (h *ProjectHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if user is owner or admin
-	isOwnerOrAdmin, err := h.queries.CheckProjectOwnerOrAdmin(r.Context(), repo.CheckProjectOwnerOrAdminParams{
-		ID:      projectUUID,
-		OwnerID: userUUID,
-	})
-	if err != nil || !isOwnerOrAdmin {
-		response.Forbidden(w, "Only project owners and admins can revoke invites")
+	// Check if user may revoke invites
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.InviteRevoke) {
 		return
 	}
 
@@ -1300,80 +1745,219 @@ func (h *ProjectHandler) RevokeInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceInvite,
+		ResourceID: inviteUUID,
+		Action:     activity.ActionRevoked,
+		Before:     map[string]any{"isActive": invite.IsActive},
+		After:      map[string]any{"isActive": false},
+	})
+
 	response.JSON(w, http.StatusOK, map[string]string{"message": "Invite revoked successfully"})
 }
 
-// getUserRoleAndPermissions gets the user's role and calculates permissions for a project
-func (h *ProjectHandler) getUserRoleAndPermissions(ctx context.Context, projectID, userID uuid.UUID) (*string, struct {
-	CanViewInvites   bool `json:"canViewInvites"`
-	CanCreateInvites bool `json:"canCreateInvites"`
-	CanRevokeInvites bool `json:"canRevokeInvites"`
-	CanManageMembers bool `json:"canManageMembers"`
-}) {
-	// Check if user is owner
-	isOwner, err := h.queries.CheckProjectOwner(ctx, repo.CheckProjectOwnerParams{
-		ID:      projectID,
-		OwnerID: userID,
+// ResendInvite handles re-sending an email invite and extending its expiration
+func (h *ProjectHandler) ResendInvite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	inviteID := chi.URLParam(r, "inviteId")
+	if projectID == "" || inviteID == "" {
+		response.BadRequest(w, "Project ID and Invite ID are required")
+		return
+	}
+
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		response.BadRequest(w, "Invalid project ID")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	inviteUUID, err := uuid.Parse(inviteID)
+	if err != nil {
+		response.BadRequest(w, "Invalid invite ID")
+		return
+	}
+
+	// Check if user may resend invites
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.InviteCreate) {
+		return
+	}
+
+	// Verify the invite belongs to this project and can be resent
+	invite, err := h.queries.GetProjectInviteByID(r.Context(), inviteUUID)
+	if err != nil {
+		response.NotFound(w, "Invite not found")
+		return
+	}
+	if invite.ProjectID != projectUUID {
+		response.Forbidden(w, "Invite does not belong to this project")
+		return
+	}
+	if invite.RecipientEmail == nil {
+		response.BadRequest(w, "Only email invites can be resent")
+		return
+	}
+	switch inviteStatus(invite) {
+	case "accepted":
+		response.BadRequest(w, "Invite has already been accepted")
+		return
+	case "revoked":
+		response.BadRequest(w, "Invite has been revoked")
+		return
+	}
+
+	sent, err := h.sendInviteEmail(r.Context(), invite, userUUID, time.Now().Add(h.cfg.Invites.EmailTTL))
+	if err != nil {
+		log.Printf("Failed to resend invite %s to %s: %v", invite.ID, *invite.RecipientEmail, err)
+		response.InternalServerError(w, "Failed to send invite email")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceInvite,
+		ResourceID: inviteUUID,
+		Action:     activity.ActionResent,
+		Before:     inviteActivity(invite),
+		After:      inviteActivity(sent),
 	})
+
+	response.JSON(w, http.StatusOK, inviteResponse(sent))
+}
+
+// sendInviteEmail mails the invite link to the invite's recipient and records
+// the delivery, moving the expiration to expiresAt
+func (h *ProjectHandler) sendInviteEmail(ctx context.Context, invite repo.ProjectInvite, inviterID uuid.UUID, expiresAt time.Time) (repo.ProjectInvite, error) {
+	project, err := h.queries.GetProjectByID(ctx, invite.ProjectID)
 	if err != nil {
-		// On error, return nil role and no permissions
-		return nil, struct {
-			CanViewInvites   bool `json:"canViewInvites"`
-			CanCreateInvites bool `json:"canCreateInvites"`
-			CanRevokeInvites bool `json:"canRevokeInvites"`
-			CanManageMembers bool `json:"canManageMembers"`
-		}{}
-	}
-
-	var userRole string
-	var permissions struct {
-		CanViewInvites   bool `json:"canViewInvites"`
-		CanCreateInvites bool `json:"canCreateInvites"`
-		CanRevokeInvites bool `json:"canRevokeInvites"`
-		CanManageMembers bool `json:"canManageMembers"`
-	}
-
-	if isOwner {
-		userRole = "owner"
-		permissions.CanViewInvites = true
-		permissions.CanCreateInvites = true
-		permissions.CanRevokeInvites = true
-		permissions.CanManageMembers = true
-	} else {
-		// Get user's role from project_members
-		roleResult, err := h.queries.GetUserProjectRole(ctx, repo.GetUserProjectRoleParams{
-			ID:      projectID,
-			OwnerID: userID,
-		})
-		if err != nil {
-			// Default to member if query fails
-			userRole = "member"
-		} else {
-			// Convert interface{} to string
-			// The query returns NULL if user is not a member, or a string role
-			if roleStr, ok := roleResult.(string); ok && roleStr != "" {
-				userRole = roleStr
-			} else {
-				// Default to member if role not found or NULL
-				userRole = "member"
-			}
-		}
+		return invite, fmt.Errorf("get project: %w", err)
+	}
 
-		// Set permissions based on role
-		if userRole == "admin" {
-			permissions.CanViewInvites = true
-			permissions.CanCreateInvites = true
-			permissions.CanRevokeInvites = true
-			permissions.CanManageMembers = true
-		} else {
-			// member or viewer - can view invites but cannot create/revoke
-			// Note: ListInvites handler allows all members to view invites
-			permissions.CanViewInvites = true    // All members can view invites
-			permissions.CanCreateInvites = false // Only owners/admins can create
-			permissions.CanRevokeInvites = false // Only owners/admins can revoke
-			permissions.CanManageMembers = false
+	inviterName := ""
+	if inviter, err := h.queries.GetUserByID(ctx, inviterID); err == nil {
+		inviterName = strings.TrimSpace(inviter.FirstName + " " + inviter.LastName)
+		if inviterName == "" {
+			inviterName = inviter.Username
 		}
 	}
 
-	return &userRole, permissions
+	msg, err := mail.Render(mail.TemplateProjectInvite, []string{*invite.RecipientEmail}, "You're invited to "+project.Name+" on DevHive", mail.ProjectInviteData{
+		ProjectName: project.Name,
+		InviterName: inviterName,
+		InviteURL:   h.cfg.FrontendURL + h.cfg.Invites.Path + "/" + invite.InviteToken,
+		ExpiresAt:   expiresAt.UTC().Format("January 2, 2006 15:04 MST"),
+	})
+	if err != nil {
+		return invite, err
+	}
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		return invite, err
+	}
+
+	return h.queries.RecordInviteSent(ctx, repo.RecordInviteSentParams{
+		ID:        invite.ID,
+		ExpiresAt: expiresAt,
+	})
+}
+
+// recordInviteUse marks email invites accepted and increments the use count of link invites
+func (h *ProjectHandler) recordInviteUse(ctx context.Context, invite repo.ProjectInvite, userID uuid.UUID) {
+	var err error
+	if invite.RecipientEmail != nil {
+		err = h.queries.MarkInviteAccepted(ctx, repo.MarkInviteAcceptedParams{
+			ID:         invite.ID,
+			AcceptedBy: pgtype.UUID{Bytes: userID, Valid: true},
+		})
+	} else {
+		err = h.queries.IncrementInviteUseCount(ctx, invite.ID)
+	}
+	if err != nil {
+		// Log but don't fail the request
+		log.Printf("Failed to record invite use: %v", err)
+	}
+}
+
+// inviteActivity is the invite state recorded in the activity log; the
+// token is left out because it grants access to the project
+func inviteActivity(invite repo.ProjectInvite) map[string]any {
+	return map[string]any{
+		"recipientEmail": invite.RecipientEmail,
+		"expiresAt":      invite.ExpiresAt,
+		"maxUses":        invite.MaxUses,
+		"sendCount":      invite.SendCount,
+	}
+}
+
+// inviteStatus reports an invite's state: pending, accepted, expired or revoked
+func inviteStatus(invite repo.ProjectInvite) string {
+	switch {
+	case invite.AcceptedAt.Valid:
+		return "accepted"
+	case !invite.IsActive:
+		return "revoked"
+	case invite.ExpiresAt.Before(time.Now()):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+// inviteResponse converts an invite to its JSON representation
+func inviteResponse(invite repo.ProjectInvite) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":             invite.ID.String(),
+		"projectId":      invite.ProjectID.String(),
+		"token":          invite.InviteToken,
+		"expiresAt":      invite.ExpiresAt.Format("2006-01-02T15:04:05Z07:00"),
+		"maxUses":        invite.MaxUses,
+		"usedCount":      invite.UsedCount,
+		"isActive":       invite.IsActive,
+		"createdAt":      invite.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"recipientEmail": invite.RecipientEmail,
+		"status":         inviteStatus(invite),
+		"sendCount":      invite.SendCount,
+		"lastSentAt":     nil,
+		"acceptedAt":     nil,
+		"acceptedBy":     nil,
+	}
+	if invite.LastSentAt.Valid {
+		resp["lastSentAt"] = invite.LastSentAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if invite.AcceptedAt.Valid {
+		resp["acceptedAt"] = invite.AcceptedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if invite.AcceptedBy.Valid {
+		resp["acceptedBy"] = uuid.UUID(invite.AcceptedBy.Bytes).String()
+	}
+	return resp
+}
+
+// getUserRoleAndPermissions gets the user's role and calculates permissions for a project
+func (h *ProjectHandler) getUserRoleAndPermissions(ctx context.Context, projectID, userID uuid.UUID) (*string, ProjectPermissions) {
+	role, err := permission.ProjectRole(ctx, h.queries, projectID, userID)
+	if err != nil {
+		// On error, return nil role and no permissions
+		return nil, ProjectPermissions{}
+	}
+
+	userRole := string(role)
+	return &userRole, ProjectPermissions{
+		CanViewInvites:   permission.Can(role, permission.InviteView),
+		CanCreateInvites: permission.Can(role, permission.InviteCreate),
+		CanRevokeInvites: permission.Can(role, permission.InviteRevoke),
+		CanManageMembers: permission.Can(role, permission.MemberManage),
+		Actions:          permission.Actions(role),
+	}
 }
@@ -1,18 +1,23 @@
 package handlers
 
 import (
+	"errors"
 	"log"
 	"net/http"
-	"strconv"
 	"time"
 
+	"devhive-backend/internal/activity"
 	"devhive-backend/internal/broadcast"
 	"devhive-backend/internal/http/middleware"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
 	"devhive-backend/internal/repo"
+	"devhive-backend/internal/sprints"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type SprintHandler struct {
@@ -43,23 +48,34 @@ type UpdateSprintRequest struct {
 
 // UpdateSprintStatusRequest represents the sprint status update request
 type UpdateSprintStatusRequest struct {
-	IsStarted   bool `json:"isStarted"`
-	IsCompleted bool `json:"isCompleted"`
+	State       string `json:"state,omitempty"`       // planned, active or completed
+	IsStarted   bool   `json:"isStarted"`             // Used when state is omitted
+	IsCompleted bool   `json:"isCompleted"`           // Used when state is omitted
+	CarryOverTo string `json:"carryOverTo,omitempty"` // On completion, the sprint that gets unfinished tasks; omitted for the backlog
+}
+
+// SprintCarryOver lists the unfinished tasks moved out of a completed sprint
+type SprintCarryOver struct {
+	SprintID string   `json:"sprintId,omitempty"` // Omitted when they went to the backlog
+	TaskIDs  []string `json:"taskIds"`
 }
 
 // SprintResponse represents a sprint response
 type SprintResponse struct {
-	ID          string    `json:"id"`
-	ProjectID   string    `json:"projectId"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	StartDate   string    `json:"startDate"`
-	EndDate     string    `json:"endDate"`
-	IsCompleted bool      `json:"isCompleted"`
-	IsStarted   bool      `json:"isStarted"`
-	CreatedAt   string    `json:"createdAt"`
-	UpdatedAt   string    `json:"updatedAt"`
-	Owner       OwnerInfo `json:"owner"`
+	ID          string           `json:"id"`
+	ProjectID   string           `json:"projectId"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	StartDate   string           `json:"startDate"`
+	EndDate     string           `json:"endDate"`
+	IsCompleted bool             `json:"isCompleted"`
+	IsStarted   bool             `json:"isStarted"`
+	State       string           `json:"state"` // planned, active or completed
+	CreatedAt   string           `json:"createdAt"`
+	UpdatedAt   string           `json:"updatedAt"`
+	Version     int32            `json:"version"`
+	Owner       OwnerInfo        `json:"owner"`
+	CarryOver   *SprintCarryOver `json:"carryOver,omitempty"` // Only in the response to completing the sprint
 }
 
 // OwnerInfo represents project owner information
@@ -96,44 +112,37 @@ func (h *SprintHandler) ListSprintsByProject(w http.ResponseWriter, r *http.Requ
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.SprintView) {
 		return
 	}
 
-	// Parse pagination parameters
-	limit := 20
-	offset := 0
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
 	}
 
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
+	// Sprints are ordered by start date, which the cursor key carries
+	params := repo.ListSprintsByProjectParams{
+		ProjectID: projectUUID,
+		Limit:     page.FetchLimit(),
+	}
+	if page.Cursor != nil {
+		if params.CursorStartDate, err = pagination.ParseTimeKey(page.Cursor.Key); err != nil {
+			response.Problemf(w, http.StatusBadRequest, "invalid_cursor", "Cursor is malformed or expired")
+			return
 		}
+		params.CursorID, params.CursorCreatedAt = page.Keyset()
 	}
-
-	sprints, err := h.queries.ListSprintsByProject(r.Context(), repo.ListSprintsByProjectParams{
-		ProjectID: projectUUID,
-		Limit:     int32(limit),
-		Offset:    int32(offset),
-	})
+	sprints, err := h.queries.ListSprintsByProject(r.Context(), params)
 	if err != nil {
 		response.InternalServerError(w, "Failed to list sprints")
 		return
 	}
+	sprints, more := pagination.Trim(sprints, page)
 
 	// Log sprint list query for debugging
-	log.Printf("📋 ListSprintsByProject: user_id=%s, project_id=%s, sprints_returned=%d, limit=%d, offset=%d",
-		userUUID.String(), projectUUID.String(), len(sprints), limit, offset)
+	log.Printf("📋 ListSprintsByProject: user_id=%s, project_id=%s, sprints_returned=%d, limit=%d",
+		userUUID.String(), projectUUID.String(), len(sprints), page.Limit)
 	if len(sprints) > 0 {
 		// Log status breakdown
 		activeCount := 0
@@ -149,39 +158,17 @@ func (h *SprintHandler) ListSprintsByProject(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Convert to response format
-	var sprintResponses []SprintResponse
+	sprintResponses := make([]SprintResponse, 0, len(sprints))
 	for _, sprint := range sprints {
-		description := ""
-		if sprint.Description != nil {
-			description = *sprint.Description
-		}
+		sprintResponses = append(sprintResponses, buildSprintResponse(repo.GetSprintByIDRow(sprint)))
+	}
 
-		sprintResponses = append(sprintResponses, SprintResponse{
-			ID:          sprint.ID.String(),
-			ProjectID:   sprint.ProjectID.String(),
-			Name:        sprint.Name,
-			Description: description,
-			StartDate:   sprint.StartDate.Format("2006-01-02T15:04:05Z07:00"),
-			EndDate:     sprint.EndDate.Format("2006-01-02T15:04:05Z07:00"),
-			IsCompleted: sprint.IsCompleted,
-			IsStarted:   sprint.IsStarted,
-			CreatedAt:   sprint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:   sprint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			Owner: OwnerInfo{
-				ID:        sprint.OwnerID.String(),
-				Username:  sprint.OwnerUsername,
-				Email:     sprint.OwnerEmail,
-				FirstName: sprint.OwnerFirstName,
-				LastName:  sprint.OwnerLastName,
-			},
-		})
-	}
-
-	response.JSON(w, http.StatusOK, map[string]interface{}{
-		"sprints": sprintResponses,
-		"limit":   limit,
-		"offset":  offset,
-	})
+	var next *pagination.Cursor
+	if more {
+		last := sprints[len(sprints)-1]
+		next = &pagination.Cursor{CreatedAt: last.CreatedAt, ID: last.ID, Key: pagination.TimeKey(last.StartDate)}
+	}
+	response.Paginated(w, r, "sprints", sprintResponses, page, next)
 }
 
 // CreateSprint handles sprint creation
@@ -209,16 +196,7 @@ func (h *SprintHandler) CreateSprint(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil {
-		response.InternalServerError(w, "Failed to verify project access")
-		return
-	}
-	if !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.SprintCreate) {
 		return
 	}
 
@@ -270,30 +248,16 @@ func (h *SprintHandler) CreateSprint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	descriptionResp := ""
-	if fullSprint.Description != nil {
-		descriptionResp = *fullSprint.Description
-	}
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceSprint,
+		ResourceID: fullSprint.ID,
+		Action:     activity.ActionCreated,
+		After:      fullSprint,
+	})
 
-	sprintResp := SprintResponse{
-		ID:          fullSprint.ID.String(),
-		ProjectID:   fullSprint.ProjectID.String(),
-		Name:        fullSprint.Name,
-		Description: descriptionResp,
-		StartDate:   fullSprint.StartDate.Format("2006-01-02T15:04:05Z07:00"),
-		EndDate:     fullSprint.EndDate.Format("2006-01-02T15:04:05Z07:00"),
-		IsCompleted: fullSprint.IsCompleted,
-		IsStarted:   fullSprint.IsStarted,
-		CreatedAt:   fullSprint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   fullSprint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Owner: OwnerInfo{
-			ID:        fullSprint.OwnerID.String(),
-			Username:  fullSprint.OwnerUsername,
-			Email:     fullSprint.OwnerEmail,
-			FirstName: fullSprint.OwnerFirstName,
-			LastName:  fullSprint.OwnerLastName,
-		},
-	}
+	sprintResp := buildSprintResponse(fullSprint)
 
 	// Broadcast sprint created event
 	broadcast.Send(r.Context(), projectID, broadcast.EventSprintCreated, sprintResp)
@@ -332,39 +296,12 @@ func (h *SprintHandler) GetSprint(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: sprint.ProjectID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to sprint")
+	if !authorize(w, r, h.queries, sprint.ProjectID, userUUID, permission.SprintView) {
 		return
 	}
 
-	description := ""
-	if sprint.Description != nil {
-		description = *sprint.Description
-	}
-
-	response.JSON(w, http.StatusOK, SprintResponse{
-		ID:          sprint.ID.String(),
-		ProjectID:   sprint.ProjectID.String(),
-		Name:        sprint.Name,
-		Description: description,
-		StartDate:   sprint.StartDate.Format("2006-01-02T15:04:05Z07:00"),
-		EndDate:     sprint.EndDate.Format("2006-01-02T15:04:05Z07:00"),
-		IsCompleted: sprint.IsCompleted,
-		IsStarted:   sprint.IsStarted,
-		CreatedAt:   sprint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   sprint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Owner: OwnerInfo{
-			ID:        sprint.OwnerID.String(),
-			Username:  sprint.OwnerUsername,
-			Email:     sprint.OwnerEmail,
-			FirstName: sprint.OwnerFirstName,
-			LastName:  sprint.OwnerLastName,
-		},
-	})
+	w.Header().Set("ETag", versionETag(sprint.ID, sprint.Version))
+	response.JSON(w, http.StatusOK, buildSprintResponse(sprint))
 }
 
 // UpdateSprint handles sprint updates
@@ -399,12 +336,15 @@ func (h *SprintHandler) UpdateSprint(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: currentSprint.ProjectID,
-		UserID:    userUUID,
+	if !authorize(w, r, h.queries, currentSprint.ProjectID, userUUID, permission.SprintUpdate) {
+		return
+	}
+
+	// Reject writes based on a stale copy of the sprint
+	expectedVersion, ok := checkIfMatch(w, r, sprintUUID, currentSprint.Version, func() (interface{}, error) {
+		return buildSprintResponse(currentSprint), nil
 	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to sprint")
+	if !ok {
 		return
 	}
 
@@ -443,12 +383,17 @@ func (h *SprintHandler) UpdateSprint(w http.ResponseWriter, r *http.Request) {
 	}
 
 	_, err = h.queries.UpdateSprint(r.Context(), repo.UpdateSprintParams{
-		ID:          sprintUUID,
-		Name:        name,
-		Description: &description,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		ID:              sprintUUID,
+		Name:            name,
+		Description:     &description,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		ExpectedVersion: expectedVersion,
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.writeSprintConflict(w, r, sprintUUID)
+		return
+	}
 	if err != nil {
 		response.BadRequest(w, "Failed to update sprint: "+err.Error())
 		return
@@ -461,30 +406,17 @@ func (h *SprintHandler) UpdateSprint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	descriptionResp := ""
-	if fullSprint.Description != nil {
-		descriptionResp = *fullSprint.Description
-	}
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  fullSprint.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceSprint,
+		ResourceID: sprintUUID,
+		Action:     activity.ActionUpdated,
+		Before:     currentSprint,
+		After:      fullSprint,
+	})
 
-	sprintResp := SprintResponse{
-		ID:          fullSprint.ID.String(),
-		ProjectID:   fullSprint.ProjectID.String(),
-		Name:        fullSprint.Name,
-		Description: descriptionResp,
-		StartDate:   fullSprint.StartDate.Format("2006-01-02T15:04:05Z07:00"),
-		EndDate:     fullSprint.EndDate.Format("2006-01-02T15:04:05Z07:00"),
-		IsCompleted: fullSprint.IsCompleted,
-		IsStarted:   fullSprint.IsStarted,
-		CreatedAt:   fullSprint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   fullSprint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Owner: OwnerInfo{
-			ID:        fullSprint.OwnerID.String(),
-			Username:  fullSprint.OwnerUsername,
-			Email:     fullSprint.OwnerEmail,
-			FirstName: fullSprint.OwnerFirstName,
-			LastName:  fullSprint.OwnerLastName,
-		},
-	}
+	sprintResp := buildSprintResponse(fullSprint)
 
 	// Broadcast sprint updated event
 	broadcast.Send(r.Context(), fullSprint.ProjectID.String(), broadcast.EventSprintUpdated, sprintResp)
@@ -524,20 +456,28 @@ func (h *SprintHandler) DeleteSprint(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: currentSprint.ProjectID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to sprint")
+	if !authorize(w, r, h.queries, currentSprint.ProjectID, userUUID, permission.SprintDelete) {
 		return
 	}
 
-	err = h.queries.DeleteSprint(r.Context(), sprintUUID)
+	rows, err := h.queries.SoftDeleteSprint(r.Context(), sprintUUID)
 	if err != nil {
 		response.InternalServerError(w, "Failed to delete sprint")
 		return
 	}
+	if rows == 0 {
+		response.NotFound(w, "Sprint not found")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  currentSprint.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceSprint,
+		ResourceID: sprintUUID,
+		Action:     activity.ActionDeleted,
+		Before:     currentSprint,
+	})
 
 	// Broadcast sprint deleted event
 	broadcast.Send(r.Context(), currentSprint.ProjectID.String(), broadcast.EventSprintDeleted, map[string]string{
@@ -581,12 +521,15 @@ func (h *SprintHandler) UpdateSprintStatus(w http.ResponseWriter, r *http.Reques
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: currentSprint.ProjectID,
-		UserID:    userUUID,
+	if !authorize(w, r, h.queries, currentSprint.ProjectID, userUUID, permission.SprintUpdate) {
+		return
+	}
+
+	// Reject writes based on a stale copy of the sprint
+	expectedVersion, ok := checkIfMatch(w, r, sprintUUID, currentSprint.Version, func() (interface{}, error) {
+		return buildSprintResponse(currentSprint), nil
 	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to sprint")
+	if !ok {
 		return
 	}
 
@@ -595,14 +538,47 @@ func (h *SprintHandler) UpdateSprintStatus(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	// Update sprint status
-	updatedSprint, err := h.queries.UpdateSprintStatus(r.Context(), repo.UpdateSprintStatusParams{
-		ID:          sprintUUID,
-		IsStarted:   req.IsStarted,
-		IsCompleted: req.IsCompleted,
-	})
-	if err != nil {
-		response.BadRequest(w, "Failed to update sprint status: "+err.Error())
+	// Older clients send the flags; state takes precedence when given
+	target := sprints.StateOf(req.IsStarted, req.IsCompleted)
+	if req.State != "" {
+		target, err = sprints.ParseState(req.State)
+		if err != nil {
+			response.BadRequest(w, "State must be planned, active or completed")
+			return
+		}
+	}
+	var nextSprint uuid.UUID
+	if req.CarryOverTo != "" {
+		nextSprint, err = uuid.Parse(req.CarryOverTo)
+		if err != nil {
+			response.BadRequest(w, "Invalid carry-over sprint ID")
+			return
+		}
+	}
+
+	// Transition rechecks the version and state under a lock; re-sending the
+	// current state changes nothing
+	change, err := sprints.Transition(r.Context(), h.queries, currentSprint, target, nextSprint, expectedVersion, userUUID)
+	var notAllowed *sprints.TransitionError
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		h.writeSprintConflict(w, r, sprintUUID)
+		return
+	case errors.As(err, &notAllowed):
+		response.Problemf(w, http.StatusUnprocessableEntity, "sprint_transition_not_allowed",
+			"A sprint can only move from planned to active and from active to completed; this one is "+string(notAllowed.From))
+		return
+	case errors.Is(err, sprints.ErrActiveSprintExists):
+		response.Problemf(w, http.StatusConflict, "active_sprint_exists",
+			"Another sprint of this project is already active; complete it first")
+		return
+	case errors.Is(err, sprints.ErrInvalidCarryOver):
+		response.Problemf(w, http.StatusUnprocessableEntity, "invalid_carry_over",
+			"Unfinished tasks can only carry over on completion, to another planned or active sprint of this project")
+		return
+	case err != nil:
+		log.Printf("UpdateSprintStatus: %v", err)
+		response.InternalServerError(w, "Failed to update sprint status")
 		return
 	}
 
@@ -613,33 +589,71 @@ func (h *SprintHandler) UpdateSprintStatus(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	descriptionResp := ""
-	if fullSprint.Description != nil {
-		descriptionResp = *fullSprint.Description
+	sprintResp := buildSprintResponse(fullSprint)
+	w.Header().Set("ETag", versionETag(fullSprint.ID, fullSprint.Version))
+	if change.Unchanged {
+		response.JSON(w, http.StatusOK, sprintResp)
+		return
 	}
 
-	sprintResp := SprintResponse{
-		ID:          fullSprint.ID.String(),
-		ProjectID:   fullSprint.ProjectID.String(),
-		Name:        fullSprint.Name,
-		Description: descriptionResp,
-		StartDate:   fullSprint.StartDate.Format("2006-01-02T15:04:05Z07:00"),
-		EndDate:     fullSprint.EndDate.Format("2006-01-02T15:04:05Z07:00"),
-		IsCompleted: updatedSprint.IsCompleted,
-		IsStarted:   updatedSprint.IsStarted,
-		CreatedAt:   fullSprint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt:   fullSprint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		Owner: OwnerInfo{
-			ID:        fullSprint.OwnerID.String(),
-			Username:  fullSprint.OwnerUsername,
-			Email:     fullSprint.OwnerEmail,
-			FirstName: fullSprint.OwnerFirstName,
-			LastName:  fullSprint.OwnerLastName,
-		},
+	if target == sprints.StateCompleted {
+		carryOver := &SprintCarryOver{TaskIDs: make([]string, len(change.CarriedOver))}
+		for i, id := range change.CarriedOver {
+			carryOver.TaskIDs[i] = id.String()
+		}
+		if change.CarriedTo != uuid.Nil {
+			carryOver.SprintID = change.CarriedTo.String()
+		}
+		sprintResp.CarryOver = carryOver
 	}
 
+	activity.AnnounceBatch(r.Context(), fullSprint.ProjectID, change.ActivityIDs)
+
 	// Broadcast sprint status updated event
 	broadcast.Send(r.Context(), fullSprint.ProjectID.String(), broadcast.EventSprintUpdated, sprintResp)
 
 	response.JSON(w, http.StatusOK, sprintResp)
 }
+
+// buildSprintResponse converts GetSprintByIDRow to SprintResponse
+func buildSprintResponse(sprint repo.GetSprintByIDRow) SprintResponse {
+	description := ""
+	if sprint.Description != nil {
+		description = *sprint.Description
+	}
+
+	return SprintResponse{
+		ID:          sprint.ID.String(),
+		ProjectID:   sprint.ProjectID.String(),
+		Name:        sprint.Name,
+		Description: description,
+		StartDate:   sprint.StartDate.Format("2006-01-02T15:04:05Z07:00"),
+		EndDate:     sprint.EndDate.Format("2006-01-02T15:04:05Z07:00"),
+		IsCompleted: sprint.IsCompleted,
+		IsStarted:   sprint.IsStarted,
+		State:       string(sprints.StateOf(sprint.IsStarted, sprint.IsCompleted)),
+		CreatedAt:   sprint.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:   sprint.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     sprint.Version,
+		Owner: OwnerInfo{
+			ID:        sprint.OwnerID.String(),
+			Username:  sprint.OwnerUsername,
+			Email:     sprint.OwnerEmail,
+			FirstName: sprint.OwnerFirstName,
+			LastName:  sprint.OwnerLastName,
+		},
+	}
+}
+
+// writeSprintConflict answers an update that found the sprint changed or
+// deleted after it was read
+func (h *SprintHandler) writeSprintConflict(w http.ResponseWriter, r *http.Request, sprintID uuid.UUID) {
+	sprint, err := h.queries.GetSprintByID(r.Context(), sprintID)
+	if err != nil {
+		response.NotFound(w, "Sprint not found")
+		return
+	}
+	writePreconditionFailed(w, versionETag(sprint.ID, sprint.Version), func() (interface{}, error) {
+		return buildSprintResponse(sprint), nil
+	})
+}
@@ -1,16 +1,26 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
-	"strconv"
 
+	"devhive-backend/internal/activity"
 	"devhive-backend/internal/broadcast"
 	"devhive-backend/internal/http/middleware"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/planning"
 	"devhive-backend/internal/repo"
+	"devhive-backend/internal/tasks"
+	"devhive-backend/internal/workflow"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -26,16 +36,45 @@ func NewTaskHandler(queries *repo.Queries) *TaskHandler {
 
 // CreateTaskRequest represents the task creation request
 type CreateTaskRequest struct {
-	Description string `json:"description"`
-	SprintID    string `json:"sprintId,omitempty"`
-	AssigneeID  string `json:"assigneeId,omitempty"`
-	Status      int32  `json:"status"`
+	Description string   `json:"description"`
+	SprintID    string   `json:"sprintId,omitempty"`
+	AssigneeID  string   `json:"assigneeId,omitempty"`
+	Status      *int32   `json:"status,omitempty"`   // Defaults to the workflow's initial state
+	Priority    string   `json:"priority,omitempty"` // none, low, medium, high, urgent
+	StoryPoints *int32   `json:"storyPoints,omitempty"`
+	DueDate     *string  `json:"dueDate,omitempty"` // YYYY-MM-DD
+	LabelIDs    []string `json:"labelIds,omitempty"`
 }
 
 // UpdateTaskRequest represents the task update request
 type UpdateTaskRequest struct {
-	Description *string `json:"description,omitempty"`
-	AssigneeID  *string `json:"assigneeId,omitempty"`
+	Description *string          `json:"description,omitempty"`
+	AssigneeID  *string          `json:"assigneeId,omitempty"`
+	Priority    *string          `json:"priority,omitempty"`
+	StoryPoints Nullable[int32]  `json:"storyPoints"`        // null clears the estimate
+	DueDate     Nullable[string] `json:"dueDate"`            // null clears the due date
+	LabelIDs    *[]string        `json:"labelIds,omitempty"` // Replaces all labels; [] clears them
+}
+
+// Nullable distinguishes a JSON field that was omitted from one set to null
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// UnmarshalJSON records that the field was present and decodes its value
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.Value = &v
+	return nil
 }
 
 // UpdateTaskStatusRequest represents the task status update request
@@ -45,14 +84,19 @@ type UpdateTaskStatusRequest struct {
 
 // TaskResponse represents a task response
 type TaskResponse struct {
-	ID          string `json:"id"`
-	ProjectID   string `json:"projectId"`
-	SprintID    string `json:"sprintId,omitempty"`
-	AssigneeID  string `json:"assigneeId,omitempty"`
-	Description string `json:"description"`
-	Status      int32  `json:"status"`
-	CreatedAt   string `json:"createdAt"`
-	UpdatedAt   string `json:"updatedAt"`
+	ID          string      `json:"id"`
+	ProjectID   string      `json:"projectId"`
+	SprintID    string      `json:"sprintId,omitempty"`
+	AssigneeID  string      `json:"assigneeId,omitempty"`
+	Description string      `json:"description"`
+	Status      int32       `json:"status"`
+	Priority    string      `json:"priority"`
+	StoryPoints *int32      `json:"storyPoints"`
+	DueDate     *string     `json:"dueDate"`
+	Labels      []LabelInfo `json:"labels"`
+	CreatedAt   string      `json:"createdAt"`
+	UpdatedAt   string      `json:"updatedAt"`
+	Version     int32       `json:"version"`
 	Assignee    *struct {
 		Username  string `json:"username"`
 		FirstName string `json:"firstName"`
@@ -86,113 +130,22 @@ func (h *TaskHandler) ListTasksByProject(w http.ResponseWriter, r *http.Request)
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.TaskView) {
 		return
 	}
 
-	// Parse pagination parameters
-	limit := 20
-	offset := 0
-
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
-
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
-	}
-
-	tasks, err := h.queries.ListTasksByProject(r.Context(), repo.ListTasksByProjectParams{
-		ProjectID: projectUUID,
-		Limit:     int32(limit),
-		Offset:    int32(offset),
-	})
+	filter, err := tasks.ParseQuery(r.URL.Query())
 	if err != nil {
-		response.InternalServerError(w, "Failed to list tasks")
+		response.BadRequest(w, err.Error())
 		return
 	}
 
-	// Convert to response format
-	var taskResponses []TaskResponse
-	for _, task := range tasks {
-		description := ""
-		if task.Description != nil {
-			description = *task.Description
-		}
-
-		taskResp := TaskResponse{
-			ID:          task.ID.String(),
-			ProjectID:   task.ProjectID.String(),
-			Description: description,
-			Status:      task.Status,
-			CreatedAt:   task.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:   task.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			Owner: OwnerInfo{
-				ID:        task.OwnerID.String(),
-				Username:  task.OwnerUsername,
-				Email:     task.OwnerEmail,
-				FirstName: task.OwnerFirstName,
-				LastName:  task.OwnerLastName,
-			},
-		}
-
-		if task.SprintID.Valid {
-			sprintUUID := uuid.UUID(task.SprintID.Bytes)
-			taskResp.SprintID = sprintUUID.String()
-		}
-		if task.AssigneeID.Valid {
-			assigneeUUID := uuid.UUID(task.AssigneeID.Bytes)
-			taskResp.AssigneeID = assigneeUUID.String()
-			assigneeUsername := ""
-			if task.AssigneeUsername != nil {
-				assigneeUsername = *task.AssigneeUsername
-			}
-			assigneeFirstName := ""
-			if task.AssigneeFirstName != nil {
-				assigneeFirstName = *task.AssigneeFirstName
-			}
-			assigneeLastName := ""
-			if task.AssigneeLastName != nil {
-				assigneeLastName = *task.AssigneeLastName
-			}
-			taskResp.Assignee = &struct {
-				Username  string `json:"username"`
-				FirstName string `json:"firstName"`
-				LastName  string `json:"lastName"`
-			}{
-				Username:  assigneeUsername,
-				FirstName: assigneeFirstName,
-				LastName:  assigneeLastName,
-			}
-		}
-
-		taskResponses = append(taskResponses, taskResp)
-	}
-
-	// Ensure tasks is always an array, never null
-	if taskResponses == nil {
-		taskResponses = []TaskResponse{}
-	}
-
-	response.JSON(w, http.StatusOK, map[string]interface{}{
-		"tasks":  taskResponses,
-		"limit":  limit,
-		"offset": offset,
-	})
+	h.listTasks(w, r, projectUUID, filter)
 }
 
 // ListTasksBySprint handles listing tasks for a sprint
 func (h *TaskHandler) ListTasksBySprint(w http.ResponseWriter, r *http.Request) {
-	_, ok := middleware.GetUserIDFromContext(r.Context())
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
 		response.Unauthorized(w, "User ID not found in context")
 		return
@@ -204,105 +157,75 @@ func (h *TaskHandler) ListTasksBySprint(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Parse pagination parameters
-	limit := 20
-	offset := 0
+	sprintUUID, err := uuid.Parse(sprintID)
+	if err != nil {
+		response.BadRequest(w, "Invalid sprint ID")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
 
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
+	// Check that the user may view tasks in the sprint's project
+	sprint, err := h.queries.GetSprintByID(r.Context(), sprintUUID)
+	if err != nil {
+		response.NotFound(w, "Sprint not found")
+		return
+	}
+	if !authorize(w, r, h.queries, sprint.ProjectID, userUUID, permission.TaskView) {
+		return
 	}
 
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	// The sprint is fixed by the route; the other filters still apply
+	filter, err := tasks.ParseQuery(r.URL.Query())
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
 	}
+	filter.SprintID = pgtype.UUID{Bytes: sprintUUID, Valid: true}
+	filter.Backlog = false
 
-	sprintUUID, err := uuid.Parse(sprintID)
+	h.listTasks(w, r, sprint.ProjectID, filter)
+}
+
+// listTasks writes one page of a project's tasks matching the filter
+func (h *TaskHandler) listTasks(w http.ResponseWriter, r *http.Request, projectID uuid.UUID, filter tasks.Filter) {
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
+	}
+	params, err := filter.Params(projectID, page)
 	if err != nil {
-		response.BadRequest(w, "Invalid sprint ID")
+		response.BadRequest(w, err.Error())
 		return
 	}
 
-	tasks, err := h.queries.ListTasksBySprint(r.Context(), repo.ListTasksBySprintParams{
-		SprintID: pgtype.UUID{Bytes: sprintUUID, Valid: true},
-		Limit:    int32(limit),
-		Offset:   int32(offset),
-	})
+	rows, err := h.queries.ListTasks(r.Context(), params)
 	if err != nil {
+		log.Printf("ListTasks: %v", err)
 		response.InternalServerError(w, "Failed to list tasks")
 		return
 	}
+	rows, more := pagination.Trim(rows, page)
 
 	// Convert to response format
-	var taskResponses []TaskResponse
-	for _, task := range tasks {
-		description := ""
-		if task.Description != nil {
-			description = *task.Description
-		}
-
-		taskResp := TaskResponse{
-			ID:          task.ID.String(),
-			ProjectID:   task.ProjectID.String(),
-			Description: description,
-			Status:      task.Status,
-			CreatedAt:   task.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			UpdatedAt:   task.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
-			Owner: OwnerInfo{
-				ID:        task.OwnerID.String(),
-				Username:  task.OwnerUsername,
-				Email:     task.OwnerEmail,
-				FirstName: task.OwnerFirstName,
-				LastName:  task.OwnerLastName,
-			},
-		}
-
-		if task.SprintID.Valid {
-			sprintUUID := uuid.UUID(task.SprintID.Bytes)
-			taskResp.SprintID = sprintUUID.String()
-		}
-		if task.AssigneeID.Valid {
-			assigneeUUID := uuid.UUID(task.AssigneeID.Bytes)
-			taskResp.AssigneeID = assigneeUUID.String()
-			assigneeUsername := ""
-			if task.AssigneeUsername != nil {
-				assigneeUsername = *task.AssigneeUsername
-			}
-			assigneeFirstName := ""
-			if task.AssigneeFirstName != nil {
-				assigneeFirstName = *task.AssigneeFirstName
-			}
-			assigneeLastName := ""
-			if task.AssigneeLastName != nil {
-				assigneeLastName = *task.AssigneeLastName
-			}
-			taskResp.Assignee = &struct {
-				Username  string `json:"username"`
-				FirstName string `json:"firstName"`
-				LastName  string `json:"lastName"`
-			}{
-				Username:  assigneeUsername,
-				FirstName: assigneeFirstName,
-				LastName:  assigneeLastName,
-			}
-		}
-
-		taskResponses = append(taskResponses, taskResp)
+	taskResponses := make([]TaskResponse, 0, len(rows))
+	for _, task := range rows {
+		taskResponses = append(taskResponses, buildTaskResponse(repo.GetTaskByIDRow(task)))
 	}
-
-	// Ensure tasks is always an array, never null
-	if taskResponses == nil {
-		taskResponses = []TaskResponse{}
+	if err := attachTaskLabels(r.Context(), h.queries, taskResponses); err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
 	}
 
-	response.JSON(w, http.StatusOK, map[string]interface{}{
-		"tasks":  taskResponses,
-		"limit":  limit,
-		"offset": offset,
-	})
+	var next *pagination.Cursor
+	if more {
+		c := filter.Cursor(rows[len(rows)-1])
+		next = &c
+	}
+	response.Paginated(w, r, "tasks", taskResponses, page, next)
 }
 
 // CreateTask handles task creation
@@ -330,16 +253,7 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: projectUUID,
-		UserID:    userUUID,
-	})
-	if err != nil {
-		response.InternalServerError(w, "Failed to verify project access")
-		return
-	}
-	if !hasAccess {
-		response.Forbidden(w, "Access denied to project")
+	if !authorize(w, r, h.queries, projectUUID, userUUID, permission.TaskCreate) {
 		return
 	}
 
@@ -348,9 +262,43 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set default status if not provided
-	if req.Status == 0 {
-		req.Status = 0 // Default status
+	// Default to the workflow's initial state; an explicit status must exist in the workflow
+	var status int32
+	if req.Status != nil {
+		status = *req.Status
+		if !writeWorkflowError(w, workflow.ValidateStatus(r.Context(), h.queries, projectUUID, status)) {
+			return
+		}
+	} else {
+		status, err = workflow.InitialStatus(r.Context(), h.queries, projectUUID)
+		if err != nil {
+			log.Printf("CreateTask: %v", err)
+			response.InternalServerError(w, "Failed to load task workflow")
+			return
+		}
+	}
+
+	// Parse planning fields
+	var priority int16
+	if req.Priority != "" {
+		priority, err = planning.ParsePriority(req.Priority)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+	}
+	if err := planning.ValidateStoryPoints(req.StoryPoints); err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	dueDate, err := planning.ParseDueDate(req.DueDate)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	labelIDs, err := planning.ParseLabelIDs(r.Context(), h.queries, projectUUID, req.LabelIDs)
+	if !writeLabelError(w, err) {
+		return
 	}
 
 	// Parse optional fields
@@ -363,20 +311,20 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, "Invalid sprint ID format")
 			return
 		}
-		
+
 		// Validate that sprint exists and belongs to the project
 		sprint, err := h.queries.GetSprintByID(r.Context(), sprintID)
 		if err != nil {
 			response.BadRequest(w, "Sprint not found")
 			return
 		}
-		
+
 		// Verify sprint belongs to the same project
 		if sprint.ProjectID != projectUUID {
 			response.BadRequest(w, "Sprint does not belong to this project")
 			return
 		}
-		
+
 		sprintUUID = pgtype.UUID{Bytes: sprintID, Valid: true}
 	}
 
@@ -386,7 +334,7 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, "Invalid assignee ID format")
 			return
 		}
-		
+
 		// Validate that assignee is a member of the project
 		hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
 			ProjectID: projectUUID,
@@ -400,16 +348,27 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 			response.BadRequest(w, "Assignee is not a member of this project")
 			return
 		}
-		
+
 		assigneeUUID = pgtype.UUID{Bytes: assigneeID, Valid: true}
 	}
 
-	task, err := h.queries.CreateTask(r.Context(), repo.CreateTaskParams{
-		ProjectID:   projectUUID,
-		SprintID:    sprintUUID,
-		AssigneeID:  assigneeUUID,
-		Description: &req.Description,
-		Status:      req.Status,
+	var task repo.CreateTaskRow
+	err = h.queries.InTx(r.Context(), func(q *repo.Queries) error {
+		var err error
+		task, err = q.CreateTask(r.Context(), repo.CreateTaskParams{
+			ProjectID:   projectUUID,
+			SprintID:    sprintUUID,
+			AssigneeID:  assigneeUUID,
+			Description: &req.Description,
+			Status:      status,
+			Priority:    priority,
+			StoryPoints: req.StoryPoints,
+			DueDate:     dueDate,
+		})
+		if err != nil || len(labelIDs) == 0 {
+			return err
+		}
+		return planning.SetTaskLabels(r.Context(), q, task.ID, labelIDs)
 	})
 	if err != nil {
 		response.BadRequest(w, "Failed to create task: "+err.Error())
@@ -423,8 +382,21 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceTask,
+		ResourceID: fullTask.ID,
+		Action:     activity.ActionCreated,
+		After:      fullTask,
+	})
+
 	// Build complete TaskResponse with Assignee object
-	taskResp := buildTaskResponse(fullTask)
+	taskResp, err := taskResponseWithLabels(r.Context(), h.queries, fullTask)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
 
 	// Broadcast task created event
 	broadcast.Send(r.Context(), projectID, broadcast.EventTaskCreated, taskResp)
@@ -463,17 +435,17 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: task.ProjectID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to task")
+	if !authorize(w, r, h.queries, task.ProjectID, userUUID, permission.TaskView) {
 		return
 	}
 
 	// Build complete TaskResponse using helper function
-	taskResp := buildTaskResponse(task)
+	taskResp, err := taskResponseWithLabels(r.Context(), h.queries, task)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
+	w.Header().Set("ETag", versionETag(task.ID, task.Version))
 	response.JSON(w, http.StatusOK, taskResp)
 }
 
@@ -489,8 +461,13 @@ func buildTaskResponse(task repo.GetTaskByIDRow) TaskResponse {
 		ProjectID:   task.ProjectID.String(),
 		Description: description,
 		Status:      task.Status,
+		Priority:    planning.PriorityName(task.Priority),
+		StoryPoints: task.StoryPoints,
+		DueDate:     planning.FormatDueDate(task.DueDate),
+		Labels:      []LabelInfo{},
 		CreatedAt:   task.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   task.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Version:     task.Version,
 		Owner: OwnerInfo{
 			ID:        task.OwnerID.String(),
 			Username:  task.OwnerUsername,
@@ -533,6 +510,42 @@ func buildTaskResponse(task repo.GetTaskByIDRow) TaskResponse {
 	return taskResp
 }
 
+// taskResponseWithLabels builds a single task response including its labels
+func taskResponseWithLabels(ctx context.Context, queries *repo.Queries, task repo.GetTaskByIDRow) (TaskResponse, error) {
+	tasks := []TaskResponse{buildTaskResponse(task)}
+	if err := attachTaskLabels(ctx, queries, tasks); err != nil {
+		return TaskResponse{}, err
+	}
+	return tasks[0], nil
+}
+
+// attachTaskLabels loads the labels for all tasks with one query
+func attachTaskLabels(ctx context.Context, queries *repo.Queries, tasks []TaskResponse) error {
+	taskIDs := make([]uuid.UUID, 0, len(tasks))
+	for _, task := range tasks {
+		id, err := uuid.Parse(task.ID)
+		if err != nil {
+			return err
+		}
+		taskIDs = append(taskIDs, id)
+	}
+
+	labels, err := planning.LabelsByTask(ctx, queries, taskIDs)
+	if err != nil {
+		return err
+	}
+	for i, id := range taskIDs {
+		for _, label := range labels[id] {
+			tasks[i].Labels = append(tasks[i].Labels, LabelInfo{
+				ID:    label.ID.String(),
+				Name:  label.Name,
+				Color: label.Color,
+			})
+		}
+	}
+	return nil
+}
+
 // UpdateTask handles task updates
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -565,12 +578,15 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: currentTask.ProjectID,
-		UserID:    userUUID,
+	if !authorize(w, r, h.queries, currentTask.ProjectID, userUUID, permission.TaskUpdate) {
+		return
+	}
+
+	// Reject writes based on a stale copy of the task
+	expectedVersion, ok := checkIfMatch(w, r, taskUUID, currentTask.Version, func() (interface{}, error) {
+		return taskResponseWithLabels(r.Context(), h.queries, currentTask)
 	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to task")
+	if !ok {
 		return
 	}
 
@@ -578,14 +594,50 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	if !response.Decode(w, r, &req) {
 		return
 	}
+	previousResp, err := taskResponseWithLabels(r.Context(), h.queries, currentTask)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
 
 	// Merge updates
 	description := *currentTask.Description
 	assigneeID := currentTask.AssigneeID
+	priority := currentTask.Priority
+	storyPoints := currentTask.StoryPoints
+	dueDate := currentTask.DueDate
 
 	if req.Description != nil {
 		description = *req.Description
 	}
+	if req.Priority != nil {
+		priority, err = planning.ParsePriority(*req.Priority)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+	}
+	if req.StoryPoints.Set {
+		if err := planning.ValidateStoryPoints(req.StoryPoints.Value); err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+		storyPoints = req.StoryPoints.Value
+	}
+	if req.DueDate.Set {
+		dueDate, err = planning.ParseDueDate(req.DueDate.Value)
+		if err != nil {
+			response.BadRequest(w, err.Error())
+			return
+		}
+	}
+	var labelIDs []uuid.UUID
+	if req.LabelIDs != nil {
+		labelIDs, err = planning.ParseLabelIDs(r.Context(), h.queries, currentTask.ProjectID, *req.LabelIDs)
+		if !writeLabelError(w, err) {
+			return
+		}
+	}
 	if req.AssigneeID != nil {
 		assigneeIDParsed, err := uuid.Parse(*req.AssigneeID)
 		if err != nil {
@@ -593,16 +645,30 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		assigneeID = pgtype.UUID{Bytes: assigneeIDParsed, Valid: true}
-	} else if req.AssigneeID == nil && req.Description == nil {
-		// If both are nil, allow clearing assignee
+	} else if req.Description == nil && req.Priority == nil && !req.StoryPoints.Set && !req.DueDate.Set && req.LabelIDs == nil {
+		// If no other field is set, allow clearing assignee
 		assigneeID = pgtype.UUID{Valid: false}
 	}
 
-	_, err = h.queries.UpdateTask(r.Context(), repo.UpdateTaskParams{
-		ID:          taskUUID,
-		Description: &description,
-		AssigneeID:  assigneeID,
+	err = h.queries.InTx(r.Context(), func(q *repo.Queries) error {
+		_, err := q.UpdateTask(r.Context(), repo.UpdateTaskParams{
+			ID:              taskUUID,
+			Description:     &description,
+			AssigneeID:      assigneeID,
+			Priority:        priority,
+			StoryPoints:     storyPoints,
+			DueDate:         dueDate,
+			ExpectedVersion: expectedVersion,
+		})
+		if err != nil || req.LabelIDs == nil {
+			return err
+		}
+		return planning.SetTaskLabels(r.Context(), q, taskUUID, labelIDs)
 	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.writeTaskConflict(w, r, taskUUID)
+		return
+	}
 	if err != nil {
 		response.BadRequest(w, "Failed to update task: "+err.Error())
 		return
@@ -616,14 +682,44 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Build complete TaskResponse
-	taskResp := buildTaskResponse(fullTask)
+	taskResp, err := taskResponseWithLabels(r.Context(), h.queries, fullTask)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  fullTask.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceTask,
+		ResourceID: taskUUID,
+		Action:     taskUpdateAction(previousResp, taskResp),
+		Before:     currentTask,
+		After:      fullTask,
+	})
 
 	// Broadcast task updated event
 	broadcast.Send(r.Context(), fullTask.ProjectID.String(), broadcast.EventTaskUpdated, taskResp)
 
+	w.Header().Set("ETag", versionETag(fullTask.ID, fullTask.Version))
 	response.JSON(w, http.StatusOK, taskResp)
 }
 
+// taskUpdateAction returns the activity action for an update: a change of
+// assignee alone is an assignment, anything else an update
+func taskUpdateAction(before, after TaskResponse) string {
+	changes, err := activity.Diff(before, after)
+	if err != nil {
+		return activity.ActionUpdated
+	}
+	delete(changes, "version")
+	delete(changes, "assignee")
+	if _, ok := changes["assigneeId"]; ok && len(changes) == 1 {
+		return activity.ActionAssigned
+	}
+	return activity.ActionUpdated
+}
+
 // UpdateTaskStatus handles task status updates
 func (h *TaskHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -656,12 +752,15 @@ func (h *TaskHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: currentTask.ProjectID,
-		UserID:    userUUID,
+	if !authorize(w, r, h.queries, currentTask.ProjectID, userUUID, permission.TaskUpdate) {
+		return
+	}
+
+	// Reject writes based on a stale copy of the task
+	expectedVersion, ok := checkIfMatch(w, r, taskUUID, currentTask.Version, func() (interface{}, error) {
+		return taskResponseWithLabels(r.Context(), h.queries, currentTask)
 	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to task")
+	if !ok {
 		return
 	}
 
@@ -670,10 +769,26 @@ func (h *TaskHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject moves the project's workflow does not allow
+	if !writeWorkflowError(w, workflow.CheckTransition(r.Context(), h.queries, currentTask.ProjectID, currentTask.Status, req.Status)) {
+		return
+	}
+
+	// The transition was checked against the status just read, so the update
+	// only applies to that version even when the client sent no If-Match
 	_, err = h.queries.UpdateTaskStatus(r.Context(), repo.UpdateTaskStatusParams{
-		ID:     taskUUID,
-		Status: req.Status,
+		ID:              taskUUID,
+		Status:          req.Status,
+		ExpectedVersion: &currentTask.Version,
 	})
+	if errors.Is(err, pgx.ErrNoRows) && expectedVersion == nil {
+		response.Conflict(w, "The task was modified while its status was being changed; retry the request")
+		return
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		h.writeTaskConflict(w, r, taskUUID)
+		return
+	}
 	if err != nil {
 		response.BadRequest(w, "Failed to update task status: "+err.Error())
 		return
@@ -686,15 +801,43 @@ func (h *TaskHandler) UpdateTaskStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  fullTask.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceTask,
+		ResourceID: taskUUID,
+		Action:     activity.ActionStatusChanged,
+		Before:     currentTask,
+		After:      fullTask,
+	})
+
 	// Build complete TaskResponse
-	taskResp := buildTaskResponse(fullTask)
+	taskResp, err := taskResponseWithLabels(r.Context(), h.queries, fullTask)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
 
 	// Broadcast task status updated event
 	broadcast.Send(r.Context(), fullTask.ProjectID.String(), broadcast.EventTaskUpdated, taskResp)
 
+	w.Header().Set("ETag", versionETag(fullTask.ID, fullTask.Version))
 	response.JSON(w, http.StatusOK, taskResp)
 }
 
+// writeTaskConflict answers an update that found the task changed or deleted
+// after it was read
+func (h *TaskHandler) writeTaskConflict(w http.ResponseWriter, r *http.Request, taskID uuid.UUID) {
+	task, err := h.queries.GetTaskByID(r.Context(), taskID)
+	if err != nil {
+		response.NotFound(w, "Task not found")
+		return
+	}
+	writePreconditionFailed(w, versionETag(task.ID, task.Version), func() (interface{}, error) {
+		return taskResponseWithLabels(r.Context(), h.queries, task)
+	})
+}
+
 // DeleteTask handles task deletion
 func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
@@ -727,20 +870,28 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "Invalid user ID")
 		return
 	}
-	hasAccess, err := h.queries.CheckProjectAccess(r.Context(), repo.CheckProjectAccessParams{
-		ProjectID: currentTask.ProjectID,
-		UserID:    userUUID,
-	})
-	if err != nil || !hasAccess {
-		response.Forbidden(w, "Access denied to task")
+	if !authorize(w, r, h.queries, currentTask.ProjectID, userUUID, permission.TaskDelete) {
 		return
 	}
 
-	err = h.queries.DeleteTask(r.Context(), taskUUID)
+	rows, err := h.queries.SoftDeleteTask(r.Context(), taskUUID)
 	if err != nil {
 		response.InternalServerError(w, "Failed to delete task")
 		return
 	}
+	if rows == 0 {
+		response.NotFound(w, "Task not found")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  currentTask.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceTask,
+		ResourceID: taskUUID,
+		Action:     activity.ActionDeleted,
+		Before:     currentTask,
+	})
 
 	// Broadcast task deleted event
 	broadcast.Send(r.Context(), currentTask.ProjectID.String(), broadcast.EventTaskDeleted, map[string]string{
@@ -0,0 +1,669 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/broadcast"
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/planning"
+	"devhive-backend/internal/repo"
+	"devhive-backend/internal/workflow"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MaxBulkTaskOperations caps a bulk request. It also keeps the coalesced
+// NOTIFY payload, which lists every changed task, under PostgreSQL's limit.
+const MaxBulkTaskOperations = 100
+
+// Bulk request modes
+const (
+	BulkAtomic     = "atomic"      // Every operation applies or none do
+	BulkBestEffort = "best_effort" // Failed operations are skipped and the rest apply
+)
+
+// Bulk operations
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpMove   = "move"
+	BulkOpAssign = "assign"
+	BulkOpStatus = "status"
+	BulkOpDelete = "delete"
+)
+
+// BulkTaskRequest is a batch of task operations applied in one transaction
+type BulkTaskRequest struct {
+	Mode       string              `json:"mode,omitempty"` // atomic (default) or best_effort
+	Operations []BulkTaskOperation `json:"operations"`
+}
+
+// BulkTaskOperation is one change in a bulk request; which fields it uses depends on Op
+type BulkTaskOperation struct {
+	Op         string             `json:"op"`
+	TaskID     string             `json:"taskId,omitempty"`  // Every operation but create
+	Version    *int32             `json:"version,omitempty"` // Like If-Match: fails the operation if the task has changed
+	Task       *CreateTaskRequest `json:"task,omitempty"`    // create
+	Changes    *UpdateTaskRequest `json:"changes,omitempty"` // update
+	SprintID   Nullable[string]   `json:"sprintId"`          // move; null moves the task to the backlog
+	AssigneeID Nullable[string]   `json:"assigneeId"`        // assign; null unassigns the task
+	Status     *int32             `json:"status,omitempty"`  // status
+}
+
+// BulkTaskResult is the outcome of one operation
+type BulkTaskResult struct {
+	Index  int               `json:"index"`
+	Op     string            `json:"op"`
+	Status int               `json:"status"` // The status the operation would have had as its own request
+	TaskID string            `json:"taskId,omitempty"`
+	Task   *TaskResponse     `json:"task,omitempty"`
+	Error  *response.Problem `json:"error,omitempty"`
+}
+
+// BulkTaskResponse reports every operation of a bulk request, in request order
+type BulkTaskResponse struct {
+	Mode    string           `json:"mode"`
+	Applied int              `json:"applied"`
+	Failed  int              `json:"failed"`
+	Results []BulkTaskResult `json:"results"`
+}
+
+// bulkError is why an operation failed
+type bulkError struct {
+	status int
+	typ    string
+	detail string
+}
+
+func (e *bulkError) Error() string {
+	return e.detail
+}
+
+func (e *bulkError) problem() *response.Problem {
+	return &response.Problem{Type: e.typ, Title: http.StatusText(e.status), Status: e.status, Detail: e.detail}
+}
+
+func bulkBadRequest(detail string) *bulkError {
+	return &bulkError{status: http.StatusBadRequest, typ: "bad_request", detail: detail}
+}
+
+// errTaskChanged fails an operation whose version no longer matches
+var errTaskChanged = &bulkError{status: http.StatusPreconditionFailed, typ: "precondition_failed", detail: "Task has changed since the given version"}
+
+// bulkChange is what an applied operation did
+type bulkChange struct {
+	action string               // Activity action
+	before *repo.GetTaskByIDRow // nil for creations
+	after  *repo.GetTaskByIDRow // nil for deletions
+}
+
+func (c *bulkChange) taskID() uuid.UUID {
+	if c.after != nil {
+		return c.after.ID
+	}
+	return c.before.ID
+}
+
+// event is the activity entry for the change
+func (c *bulkChange) event(projectID, actorID uuid.UUID) activity.Event {
+	event := activity.Event{
+		ProjectID:  projectID,
+		ActorID:    actorID,
+		Resource:   activity.ResourceTask,
+		ResourceID: c.taskID(),
+		Action:     c.action,
+	}
+	if c.before != nil {
+		event.Before = *c.before
+	}
+	if c.after != nil {
+		event.After = *c.after
+	}
+	return event
+}
+
+// BulkTasks applies create, update, move, assign, status and delete
+// operations to many tasks of a project in one transaction. In atomic mode
+// the first failure rolls everything back and the response takes its status;
+// in best_effort mode each operation runs in its own savepoint, failures are
+// skipped and the response is 207 if any failed. Activity is written in the
+// same transaction, and clients get one realtime event of each kind for the
+// whole batch instead of one per task.
+func (h *TaskHandler) BulkTasks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	projectUUID, err := uuid.Parse(chi.URLParam(r, "projectId"))
+	if err != nil {
+		response.BadRequest(w, "Invalid project ID")
+		return
+	}
+
+	var req BulkTaskRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = BulkAtomic
+	}
+	if req.Mode != BulkAtomic && req.Mode != BulkBestEffort {
+		response.BadRequest(w, "mode must be atomic or best_effort")
+		return
+	}
+	if len(req.Operations) == 0 || len(req.Operations) > MaxBulkTaskOperations {
+		response.BadRequest(w, fmt.Sprintf("operations must hold between 1 and %d items", MaxBulkTaskOperations))
+		return
+	}
+
+	ctx := r.Context()
+	changes := make([]*bulkChange, len(req.Operations))
+	failures := make([]*bulkError, len(req.Operations))
+	aborted := -1
+	var activityIDs []uuid.UUID
+	err = h.queries.InTx(ctx, func(q *repo.Queries) error {
+		// The batch is announced once, below, rather than per task
+		if err := q.DeferTaskNotifications(ctx); err != nil {
+			return err
+		}
+
+		for i, op := range req.Operations {
+			err := q.InTx(ctx, func(q *repo.Queries) error {
+				var err error
+				changes[i], err = applyBulkOp(ctx, q, projectUUID, userUUID, op)
+				return err
+			})
+			if err == nil {
+				continue
+			}
+			changes[i] = nil
+			failures[i] = toBulkError(err)
+			if req.Mode == BulkAtomic {
+				aborted = i
+				return failures[i]
+			}
+		}
+
+		var taskIDs []uuid.UUID
+		var events []activity.Event
+		seen := make(map[uuid.UUID]bool)
+		for _, change := range changes {
+			if change == nil {
+				continue
+			}
+			if !seen[change.taskID()] {
+				seen[change.taskID()] = true
+				taskIDs = append(taskIDs, change.taskID())
+			}
+			events = append(events, change.event(projectUUID, userUUID))
+		}
+		if len(taskIDs) == 0 {
+			return nil
+		}
+		var err error
+		if activityIDs, err = activity.RecordBatch(ctx, q, projectUUID, events); err != nil {
+			return err
+		}
+		return q.NotifyTasksChanged(ctx, repo.NotifyTasksChangedParams{ProjectID: projectUUID, TaskIds: taskIDs})
+	})
+	if aborted >= 0 {
+		if failures[aborted].status >= http.StatusInternalServerError {
+			response.InternalServerError(w, "Failed to apply bulk task operations")
+			return
+		}
+		for i := range changes {
+			changes[i] = nil
+		}
+	} else if err != nil {
+		log.Printf("BulkTasks: %v", err)
+		response.InternalServerError(w, "Failed to apply bulk task operations")
+		return
+	}
+
+	// Build the responses of the tasks that still exist, loading all their labels at once
+	var tasks []TaskResponse
+	taskIndex := make(map[int]int)
+	for i, change := range changes {
+		if change != nil && change.after != nil {
+			taskIndex[i] = len(tasks)
+			tasks = append(tasks, buildTaskResponse(*change.after))
+		}
+	}
+	if err := attachTaskLabels(ctx, h.queries, tasks); err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
+
+	resp := BulkTaskResponse{Mode: req.Mode, Results: make([]BulkTaskResult, len(req.Operations))}
+	var changedIDs, deletedIDs []string
+	for i, op := range req.Operations {
+		result := BulkTaskResult{Index: i, Op: op.Op, TaskID: op.TaskID}
+		change := changes[i]
+		switch {
+		case failures[i] != nil:
+			result.Status = failures[i].status
+			result.Error = failures[i].problem()
+		case change == nil:
+			skipped := &bulkError{status: http.StatusFailedDependency, typ: "bulk_aborted", detail: fmt.Sprintf("Not applied because operation %d failed", aborted)}
+			result.Status = skipped.status
+			result.Error = skipped.problem()
+		default:
+			resp.Applied++
+			result.Status = http.StatusOK
+			result.TaskID = change.taskID().String()
+			if change.action == activity.ActionCreated {
+				result.Status = http.StatusCreated
+			}
+			if j, ok := taskIndex[i]; ok {
+				result.Task = &tasks[j]
+				changedIDs = append(changedIDs, result.TaskID)
+			} else {
+				deletedIDs = append(deletedIDs, result.TaskID)
+			}
+		}
+		resp.Results[i] = result
+	}
+	resp.Failed = len(req.Operations) - resp.Applied
+
+	// Only IDs, so the event fits a NOTIFY payload; clients refetch the tasks
+	if resp.Applied > 0 {
+		broadcast.Send(ctx, projectUUID.String(), broadcast.EventTasksBulk, map[string]interface{}{
+			"projectId": projectUUID.String(),
+			"taskIds":   changedIDs,
+			"deleted":   deletedIDs,
+		})
+		activity.AnnounceBatch(ctx, projectUUID, activityIDs)
+	}
+
+	switch {
+	case aborted >= 0:
+		response.JSON(w, failures[aborted].status, resp)
+	case resp.Failed > 0:
+		response.JSON(w, http.StatusMultiStatus, resp)
+	default:
+		response.JSON(w, http.StatusOK, resp)
+	}
+}
+
+// applyBulkOp performs one operation inside the bulk transaction
+func applyBulkOp(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	switch op.Op {
+	case BulkOpCreate:
+		return bulkCreate(ctx, q, projectID, userID, op)
+	case BulkOpUpdate:
+		return bulkUpdate(ctx, q, projectID, userID, op)
+	case BulkOpMove:
+		return bulkMove(ctx, q, projectID, userID, op)
+	case BulkOpAssign:
+		return bulkAssign(ctx, q, projectID, userID, op)
+	case BulkOpStatus:
+		return bulkStatus(ctx, q, projectID, userID, op)
+	case BulkOpDelete:
+		return bulkDelete(ctx, q, projectID, userID, op)
+	default:
+		return nil, bulkBadRequest("op must be one of create, update, move, assign, status, delete")
+	}
+}
+
+func bulkCreate(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	if _, err := permission.Authorize(ctx, q, projectID, userID, permission.TaskCreate); err != nil {
+		return nil, err
+	}
+	req := op.Task
+	if req == nil {
+		return nil, bulkBadRequest("create requires task")
+	}
+
+	// Default to the workflow's initial state; an explicit status must exist in the workflow
+	var status int32
+	var err error
+	if req.Status != nil {
+		status = *req.Status
+		if err := workflow.ValidateStatus(ctx, q, projectID, status); err != nil {
+			return nil, err
+		}
+	} else if status, err = workflow.InitialStatus(ctx, q, projectID); err != nil {
+		return nil, err
+	}
+
+	var priority int16
+	if req.Priority != "" {
+		if priority, err = planning.ParsePriority(req.Priority); err != nil {
+			return nil, err
+		}
+	}
+	if err := planning.ValidateStoryPoints(req.StoryPoints); err != nil {
+		return nil, err
+	}
+	dueDate, err := planning.ParseDueDate(req.DueDate)
+	if err != nil {
+		return nil, err
+	}
+	labelIDs, err := planning.ParseLabelIDs(ctx, q, projectID, req.LabelIDs)
+	if err != nil {
+		return nil, err
+	}
+	sprintID, err := bulkSprint(ctx, q, projectID, req.SprintID)
+	if err != nil {
+		return nil, err
+	}
+	assigneeID, err := bulkAssignee(ctx, q, projectID, req.AssigneeID)
+	if err != nil {
+		return nil, err
+	}
+
+	task, err := q.CreateTask(ctx, repo.CreateTaskParams{
+		ProjectID:   projectID,
+		SprintID:    sprintID,
+		AssigneeID:  assigneeID,
+		Description: &req.Description,
+		Status:      status,
+		Priority:    priority,
+		StoryPoints: req.StoryPoints,
+		DueDate:     dueDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(labelIDs) > 0 {
+		if err := planning.SetTaskLabels(ctx, q, task.ID, labelIDs); err != nil {
+			return nil, err
+		}
+	}
+	after, err := q.GetTaskByID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &bulkChange{action: activity.ActionCreated, after: &after}, nil
+}
+
+func bulkUpdate(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	current, expected, err := loadBulkTask(ctx, q, projectID, userID, permission.TaskUpdate, op)
+	if err != nil {
+		return nil, err
+	}
+	req := op.Changes
+	if req == nil {
+		return nil, bulkBadRequest("update requires changes")
+	}
+
+	previous, err := taskResponseWithLabels(ctx, q, current)
+	if err != nil {
+		return nil, err
+	}
+
+	params := updateParams(current, expected)
+	if req.Description != nil {
+		params.Description = req.Description
+	}
+	if req.Priority != nil {
+		if params.Priority, err = planning.ParsePriority(*req.Priority); err != nil {
+			return nil, err
+		}
+	}
+	if req.StoryPoints.Set {
+		if err := planning.ValidateStoryPoints(req.StoryPoints.Value); err != nil {
+			return nil, err
+		}
+		params.StoryPoints = req.StoryPoints.Value
+	}
+	if req.DueDate.Set {
+		if params.DueDate, err = planning.ParseDueDate(req.DueDate.Value); err != nil {
+			return nil, err
+		}
+	}
+	if req.AssigneeID != nil {
+		if params.AssigneeID, err = bulkAssignee(ctx, q, projectID, *req.AssigneeID); err != nil {
+			return nil, err
+		}
+	}
+	var labelIDs []uuid.UUID
+	if req.LabelIDs != nil {
+		if labelIDs, err = planning.ParseLabelIDs(ctx, q, projectID, *req.LabelIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := q.UpdateTask(ctx, params); err != nil {
+		return nil, err
+	}
+	if req.LabelIDs != nil {
+		if err := planning.SetTaskLabels(ctx, q, current.ID, labelIDs); err != nil {
+			return nil, err
+		}
+	}
+	change, err := bulkResult(ctx, q, activity.ActionUpdated, current)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := taskResponseWithLabels(ctx, q, *change.after)
+	if err != nil {
+		return nil, err
+	}
+	change.action = taskUpdateAction(previous, updated)
+	return change, nil
+}
+
+func bulkMove(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	current, expected, err := loadBulkTask(ctx, q, projectID, userID, permission.TaskUpdate, op)
+	if err != nil {
+		return nil, err
+	}
+	if !op.SprintID.Set {
+		return nil, bulkBadRequest("move requires sprintId (null for the backlog)")
+	}
+	var sprint string
+	if op.SprintID.Value != nil {
+		sprint = *op.SprintID.Value
+	}
+	sprintID, err := bulkSprint(ctx, q, projectID, sprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := q.MoveTaskToSprint(ctx, repo.MoveTaskToSprintParams{
+		ID:              current.ID,
+		SprintID:        sprintID,
+		ExpectedVersion: expected,
+	}); err != nil {
+		return nil, err
+	}
+	return bulkResult(ctx, q, activity.ActionUpdated, current)
+}
+
+func bulkAssign(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	current, expected, err := loadBulkTask(ctx, q, projectID, userID, permission.TaskUpdate, op)
+	if err != nil {
+		return nil, err
+	}
+	if !op.AssigneeID.Set {
+		return nil, bulkBadRequest("assign requires assigneeId (null to unassign)")
+	}
+	var assignee string
+	if op.AssigneeID.Value != nil {
+		assignee = *op.AssigneeID.Value
+	}
+
+	params := updateParams(current, expected)
+	if params.AssigneeID, err = bulkAssignee(ctx, q, projectID, assignee); err != nil {
+		return nil, err
+	}
+	if _, err := q.UpdateTask(ctx, params); err != nil {
+		return nil, err
+	}
+	return bulkResult(ctx, q, activity.ActionAssigned, current)
+}
+
+func bulkStatus(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	current, expected, err := loadBulkTask(ctx, q, projectID, userID, permission.TaskUpdate, op)
+	if err != nil {
+		return nil, err
+	}
+	if op.Status == nil {
+		return nil, bulkBadRequest("status requires status")
+	}
+	if err := workflow.CheckTransition(ctx, q, projectID, current.Status, *op.Status); err != nil {
+		return nil, err
+	}
+
+	if _, err := q.UpdateTaskStatus(ctx, repo.UpdateTaskStatusParams{
+		ID:              current.ID,
+		Status:          *op.Status,
+		ExpectedVersion: expected,
+	}); err != nil {
+		return nil, err
+	}
+	return bulkResult(ctx, q, activity.ActionStatusChanged, current)
+}
+
+func bulkDelete(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, op BulkTaskOperation) (*bulkChange, error) {
+	current, _, err := loadBulkTask(ctx, q, projectID, userID, permission.TaskDelete, op)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.SoftDeleteTask(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, &bulkError{status: http.StatusNotFound, typ: "not_found", detail: "Task not found"}
+	}
+	return &bulkChange{action: activity.ActionDeleted, before: &current}, nil
+}
+
+// loadBulkTask checks the user may perform action and loads the operation's
+// task, which must belong to the project and, if a version is given, still
+// be at that version. It returns the version read, which the operation's
+// write must expect: checks made against the loaded task, such as workflow
+// transitions, only hold for that version.
+func loadBulkTask(ctx context.Context, q *repo.Queries, projectID, userID uuid.UUID, action permission.Action, op BulkTaskOperation) (repo.GetTaskByIDRow, *int32, error) {
+	if _, err := permission.Authorize(ctx, q, projectID, userID, action); err != nil {
+		return repo.GetTaskByIDRow{}, nil, err
+	}
+	taskID, err := uuid.Parse(op.TaskID)
+	if err != nil {
+		return repo.GetTaskByIDRow{}, nil, bulkBadRequest("Invalid task ID")
+	}
+	task, err := q.GetTaskByID(ctx, taskID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && task.ProjectID != projectID) {
+		return repo.GetTaskByIDRow{}, nil, &bulkError{status: http.StatusNotFound, typ: "not_found", detail: "Task not found"}
+	}
+	if err != nil {
+		return repo.GetTaskByIDRow{}, nil, err
+	}
+	if op.Version != nil && *op.Version != task.Version {
+		return repo.GetTaskByIDRow{}, nil, errTaskChanged
+	}
+	return task, &task.Version, nil
+}
+
+// updateParams starts an UpdateTask from the task's current values
+func updateParams(task repo.GetTaskByIDRow, expected *int32) repo.UpdateTaskParams {
+	return repo.UpdateTaskParams{
+		ID:              task.ID,
+		Description:     task.Description,
+		AssigneeID:      task.AssigneeID,
+		Priority:        task.Priority,
+		StoryPoints:     task.StoryPoints,
+		DueDate:         task.DueDate,
+		ExpectedVersion: expected,
+	}
+}
+
+// bulkResult reloads a changed task
+func bulkResult(ctx context.Context, q *repo.Queries, action string, before repo.GetTaskByIDRow) (*bulkChange, error) {
+	after, err := q.GetTaskByID(ctx, before.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &bulkChange{action: action, before: &before, after: &after}, nil
+}
+
+// bulkSprint parses an optional sprint ID, which must name a sprint of the project
+func bulkSprint(ctx context.Context, q *repo.Queries, projectID uuid.UUID, id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	sprintID, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, bulkBadRequest("Invalid sprint ID format")
+	}
+	sprint, err := q.GetSprintByID(ctx, sprintID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return pgtype.UUID{}, bulkBadRequest("Sprint not found")
+	}
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if sprint.ProjectID != projectID {
+		return pgtype.UUID{}, bulkBadRequest("Sprint does not belong to this project")
+	}
+	return pgtype.UUID{Bytes: sprintID, Valid: true}, nil
+}
+
+// bulkAssignee parses an optional assignee ID, which must name a project member
+func bulkAssignee(ctx context.Context, q *repo.Queries, projectID uuid.UUID, id string) (pgtype.UUID, error) {
+	if id == "" {
+		return pgtype.UUID{}, nil
+	}
+	assigneeID, err := uuid.Parse(id)
+	if err != nil {
+		return pgtype.UUID{}, bulkBadRequest("Invalid assignee ID format")
+	}
+	hasAccess, err := q.CheckProjectAccess(ctx, repo.CheckProjectAccessParams{
+		ProjectID: projectID,
+		UserID:    assigneeID,
+	})
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+	if !hasAccess {
+		return pgtype.UUID{}, bulkBadRequest("Assignee is not a member of this project")
+	}
+	return pgtype.UUID{Bytes: assigneeID, Valid: true}, nil
+}
+
+// toBulkError maps an operation's error to the status and problem type the
+// single-task endpoints would have answered with
+func toBulkError(err error) *bulkError {
+	var failure *bulkError
+	var denied *permission.DeniedError
+	switch {
+	case errors.As(err, &failure):
+		return failure
+	case errors.Is(err, pgx.ErrNoRows):
+		// An update's version check found the task changed
+		return errTaskChanged
+	case errors.Is(err, permission.ErrNotMember):
+		return &bulkError{status: http.StatusForbidden, typ: "forbidden", detail: "Access denied to project"}
+	case errors.As(err, &denied):
+		return &bulkError{status: http.StatusForbidden, typ: "forbidden", detail: "Your role (" + string(denied.Role) + ") does not allow " + string(denied.Action)}
+	case errors.Is(err, workflow.ErrTransitionNotAllowed):
+		return &bulkError{status: http.StatusUnprocessableEntity, typ: "transition_not_allowed", detail: err.Error()}
+	case errors.Is(err, workflow.ErrUnknownStatus):
+		return &bulkError{status: http.StatusUnprocessableEntity, typ: "unknown_status", detail: err.Error()}
+	case errors.Is(err, planning.ErrInvalidLabels):
+		return bulkBadRequest("Labels must be IDs of labels in this project")
+	case errors.Is(err, planning.ErrInvalidPriority), errors.Is(err, planning.ErrInvalidStoryPoints), errors.Is(err, planning.ErrInvalidDueDate):
+		return bulkBadRequest(err.Error())
+	default:
+		log.Printf("BulkTasks: %v", err)
+		return &bulkError{status: http.StatusInternalServerError, typ: "internal_server_error", detail: "Failed to apply operation"}
+	}
+}
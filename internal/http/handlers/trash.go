@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/broadcast"
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/repo"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type TrashHandler struct {
+	queries *repo.Queries
+}
+
+func NewTrashHandler(queries *repo.Queries) *TrashHandler {
+	return &TrashHandler{
+		queries: queries,
+	}
+}
+
+// TrashItemResponse represents a deleted sprint or task awaiting purge
+type TrashItemResponse struct {
+	Resource  string `json:"resource"` // "sprint" or "task"
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	TaskCount int64  `json:"taskCount,omitempty"` // Tasks deleted with the sprint
+	DeletedAt string `json:"deletedAt"`
+}
+
+// DeletedProjectResponse represents a project in its owner's trash
+type DeletedProjectResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	CreatedAt   string `json:"createdAt"`
+	DeletedAt   string `json:"deletedAt"`
+}
+
+// ListProjectTrash handles listing a project's deleted sprints and tasks
+func (h *TrashHandler) ListProjectTrash(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.ProjectView)
+	if !ok {
+		return
+	}
+
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
+	}
+
+	cursorID, cursorDeletedAt := page.Keyset()
+	items, err := h.queries.ListProjectTrash(r.Context(), repo.ListProjectTrashParams{
+		ProjectID:       projectUUID,
+		CursorID:        cursorID,
+		CursorDeletedAt: cursorDeletedAt,
+		Limit:           page.FetchLimit(),
+	})
+	if err != nil {
+		log.Printf("ListProjectTrash: %v", err)
+		response.InternalServerError(w, "Failed to list trash")
+		return
+	}
+	items, more := pagination.Trim(items, page)
+
+	itemResponses := make([]TrashItemResponse, 0, len(items))
+	for _, item := range items {
+		itemResponses = append(itemResponses, TrashItemResponse{
+			Resource:  item.Resource,
+			ID:        item.ID.String(),
+			Title:     item.Title,
+			TaskCount: item.TaskCount,
+			DeletedAt: item.DeletedAt.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	var next *pagination.Cursor
+	if more {
+		last := items[len(items)-1]
+		next = &pagination.Cursor{CreatedAt: last.DeletedAt, ID: last.ID}
+	}
+	response.Paginated(w, r, "items", itemResponses, page, next)
+}
+
+// ListDeletedProjects handles listing the projects the user owns that are in the trash
+func (h *TrashHandler) ListDeletedProjects(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	page, ok := response.ParsePage(w, r)
+	if !ok {
+		return
+	}
+
+	cursorID, cursorDeletedAt := page.Keyset()
+	projects, err := h.queries.ListDeletedProjectsByOwner(r.Context(), repo.ListDeletedProjectsByOwnerParams{
+		OwnerID:         userUUID,
+		CursorID:        cursorID,
+		CursorDeletedAt: cursorDeletedAt,
+		Limit:           page.FetchLimit(),
+	})
+	if err != nil {
+		log.Printf("ListDeletedProjects: %v", err)
+		response.InternalServerError(w, "Failed to list deleted projects")
+		return
+	}
+	projects, more := pagination.Trim(projects, page)
+
+	projectResponses := make([]DeletedProjectResponse, 0, len(projects))
+	for _, project := range projects {
+		description := ""
+		if project.Description != nil {
+			description = *project.Description
+		}
+		projectResponses = append(projectResponses, DeletedProjectResponse{
+			ID:          project.ID.String(),
+			Name:        project.Name,
+			Description: description,
+			CreatedAt:   project.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			DeletedAt:   project.DeletedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+		})
+	}
+
+	var next *pagination.Cursor
+	if more {
+		last := projects[len(projects)-1]
+		next = &pagination.Cursor{CreatedAt: last.DeletedAt.Time, ID: last.ID}
+	}
+	response.Paginated(w, r, "projects", projectResponses, page, next)
+}
+
+// RestoreProject handles restoring a deleted project. Trashed projects grant
+// no roles, so only the owner recorded on the project may restore it.
+func (h *TrashHandler) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	projectID := chi.URLParam(r, "projectId")
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		response.BadRequest(w, "Invalid project ID")
+		return
+	}
+
+	_, err = h.queries.RestoreProject(r.Context(), repo.RestoreProjectParams{
+		ID:      projectUUID,
+		OwnerID: userUUID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.NotFound(w, "Deleted project not found")
+		return
+	}
+	if err != nil {
+		log.Printf("RestoreProject: %v", err)
+		response.InternalServerError(w, "Failed to restore project")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceProject,
+		ResourceID: projectUUID,
+		Action:     activity.ActionRestored,
+	})
+
+	broadcast.Send(r.Context(), projectID, broadcast.EventProjectRestored, map[string]string{
+		"id": projectID,
+	})
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Project restored successfully", "id": projectID})
+}
+
+// RestoreSprint handles restoring a deleted sprint along with the tasks that
+// were deleted with it
+func (h *TrashHandler) RestoreSprint(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	sprintID := chi.URLParam(r, "sprintId")
+	sprintUUID, err := uuid.Parse(sprintID)
+	if err != nil {
+		response.BadRequest(w, "Invalid sprint ID")
+		return
+	}
+
+	deleted, err := h.queries.GetDeletedSprint(r.Context(), sprintUUID)
+	if err != nil {
+		response.NotFound(w, "Deleted sprint not found")
+		return
+	}
+	if !authorize(w, r, h.queries, deleted.ProjectID, userUUID, permission.SprintDelete) {
+		return
+	}
+
+	sprint, err := h.queries.RestoreSprint(r.Context(), sprintUUID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.NotFound(w, "Deleted sprint not found")
+		return
+	}
+	if isUniqueViolation(err) {
+		response.Conflict(w, "Another sprint of this project is already active")
+		return
+	}
+	if err != nil {
+		log.Printf("RestoreSprint: %v", err)
+		response.InternalServerError(w, "Failed to restore sprint")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  sprint.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceSprint,
+		ResourceID: sprintUUID,
+		Action:     activity.ActionRestored,
+		After:      sprint,
+	})
+
+	broadcast.Send(r.Context(), sprint.ProjectID.String(), broadcast.EventSprintRestored, map[string]string{
+		"id":        sprintID,
+		"projectId": sprint.ProjectID.String(),
+	})
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Sprint restored successfully", "id": sprintID})
+}
+
+// RestoreTask handles restoring a deleted task
+func (h *TrashHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+	taskID := chi.URLParam(r, "taskId")
+	taskUUID, err := uuid.Parse(taskID)
+	if err != nil {
+		response.BadRequest(w, "Invalid task ID")
+		return
+	}
+
+	deleted, err := h.queries.GetDeletedTask(r.Context(), taskUUID)
+	if err != nil {
+		response.NotFound(w, "Deleted task not found")
+		return
+	}
+	if !authorize(w, r, h.queries, deleted.ProjectID, userUUID, permission.TaskDelete) {
+		return
+	}
+
+	// A task can't come back into a sprint that is still in the trash
+	rows, err := h.queries.RestoreTask(r.Context(), taskUUID)
+	if err != nil {
+		log.Printf("RestoreTask: %v", err)
+		response.InternalServerError(w, "Failed to restore task")
+		return
+	}
+	if rows == 0 {
+		response.Conflict(w, "The task's sprint is deleted; restore the sprint first")
+		return
+	}
+
+	task, err := h.queries.GetTaskByID(r.Context(), taskUUID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load restored task")
+		return
+	}
+	taskResp, err := taskResponseWithLabels(r.Context(), h.queries, task)
+	if err != nil {
+		response.InternalServerError(w, "Failed to load task labels")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  task.ProjectID,
+		ActorID:    userUUID,
+		Resource:   activity.ResourceTask,
+		ResourceID: taskUUID,
+		Action:     activity.ActionRestored,
+		After:      task,
+	})
+
+	broadcast.Send(r.Context(), task.ProjectID.String(), broadcast.EventTaskRestored, taskResp)
+
+	response.JSON(w, http.StatusOK, taskResp)
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"strings"
 
+	"devhive-backend/internal/config"
 	"devhive-backend/internal/http/middleware"
 	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/mail"
 	"devhive-backend/internal/repo"
 
 	"github.com/go-chi/chi/v5"
@@ -15,11 +17,15 @@ import (
 
 type UserHandler struct {
 	queries *repo.Queries
+	cfg     *config.Config
+	mailer  mail.Mailer
 }
 
-func NewUserHandler(queries *repo.Queries) *UserHandler {
+func NewUserHandler(queries *repo.Queries, cfg *config.Config, mailer mail.Mailer) *UserHandler {
 	return &UserHandler{
 		queries: queries,
+		cfg:     cfg,
+		mailer:  mailer,
 	}
 }
 
@@ -34,15 +40,16 @@ type CreateUserRequest struct {
 
 // UserResponse represents a user response
 type UserResponse struct {
-	ID        string `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	FirstName string `json:"firstName"`
-	LastName  string `json:"lastName"`
-	Active    bool   `json:"active"`
-	AvatarURL string `json:"avatarUrl,omitempty"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID            string `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	FirstName     string `json:"firstName"`
+	LastName      string `json:"lastName"`
+	Active        bool   `json:"active"`
+	AvatarURL     string `json:"avatarUrl,omitempty"`
+	EmailVerified bool   `json:"emailVerified"`
+	CreatedAt     string `json:"createdAt"`
+	UpdatedAt     string `json:"updatedAt"`
 }
 
 // CreateUser handles user creation
@@ -87,21 +94,25 @@ func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Send verification email
+	h.sendVerificationEmail(r.Context(), user.ID, user.Username, user.Email)
+
 	avatarURL := ""
 	if user.AvatarUrl != nil {
 		avatarURL = *user.AvatarUrl
 	}
 
 	response.JSON(w, http.StatusCreated, UserResponse{
-		ID:        user.ID.String(),
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Active:    user.Active,
-		AvatarURL: avatarURL,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:            user.ID.String(),
+		Username:      user.Username,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Active:        user.Active,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		AvatarURL:     avatarURL,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
@@ -139,15 +150,16 @@ func (h *UserHandler) GetMe(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response.JSON(w, http.StatusOK, UserResponse{
-		ID:        user.ID.String(),
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Active:    user.Active,
-		AvatarURL: avatarURL,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:            user.ID.String(),
+		Username:      user.Username,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Active:        user.Active,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		AvatarURL:     avatarURL,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
@@ -223,21 +235,27 @@ func (h *UserHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A changed email address has to be verified again
+	if !strings.EqualFold(currentUser.Email, updatedUser.Email) {
+		h.sendVerificationEmail(r.Context(), updatedUser.ID, updatedUser.Username, updatedUser.Email)
+	}
+
 	avatarURLStr := ""
 	if updatedUser.AvatarUrl != nil {
 		avatarURLStr = *updatedUser.AvatarUrl
 	}
 
 	response.JSON(w, http.StatusOK, UserResponse{
-		ID:        updatedUser.ID.String(),
-		Username:  updatedUser.Username,
-		Email:     updatedUser.Email,
-		FirstName: updatedUser.FirstName,
-		LastName:  updatedUser.LastName,
-		Active:    updatedUser.Active,
-		AvatarURL: avatarURLStr,
-		CreatedAt: updatedUser.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: updatedUser.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:            updatedUser.ID.String(),
+		Username:      updatedUser.Username,
+		Email:         updatedUser.Email,
+		FirstName:     updatedUser.FirstName,
+		LastName:      updatedUser.LastName,
+		Active:        updatedUser.Active,
+		EmailVerified: updatedUser.EmailVerifiedAt.Valid,
+		AvatarURL:     avatarURLStr,
+		CreatedAt:     updatedUser.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     updatedUser.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
@@ -267,14 +285,15 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	response.JSON(w, http.StatusOK, UserResponse{
-		ID:        user.ID.String(),
-		Username:  user.Username,
-		Email:     user.Email,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		Active:    user.Active,
-		AvatarURL: avatarURL,
-		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:            user.ID.String(),
+		Username:      user.Username,
+		Email:         user.Email,
+		FirstName:     user.FirstName,
+		LastName:      user.LastName,
+		Active:        user.Active,
+		EmailVerified: user.EmailVerifiedAt.Valid,
+		AvatarURL:     avatarURL,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:     user.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/mail"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// VerifyEmailRequest represents the email verification request
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyEmail handles confirming an email address with a verification token
+func (h *UserHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if !response.Decode(w, r, &req) {
+		return
+	}
+
+	if req.Token == "" {
+		response.BadRequest(w, "Verification token is required")
+		return
+	}
+
+	// Consume verification token (single-use, fails if expired or already used)
+	verification, err := h.queries.ConsumeEmailVerification(r.Context(), hashToken(req.Token))
+	if err != nil {
+		response.BadRequest(w, "Invalid or expired verification token")
+		return
+	}
+
+	// Mark the email verified, unless the user has since changed it
+	updated, err := h.queries.MarkEmailVerified(r.Context(), repo.MarkEmailVerifiedParams{
+		ID:    verification.UserID,
+		Email: verification.Email,
+	})
+	if err != nil {
+		response.InternalServerError(w, "Failed to verify email")
+		return
+	}
+	if updated == 0 {
+		user, err := h.queries.GetUserByID(r.Context(), verification.UserID)
+		if err != nil || !user.EmailVerifiedAt.Valid || !strings.EqualFold(user.Email, verification.Email) {
+			response.BadRequest(w, "Invalid or expired verification token")
+			return
+		}
+	}
+
+	// Remaining tokens for the user are no longer needed
+	if err := h.queries.InvalidateUserEmailVerifications(r.Context(), verification.UserID); err != nil {
+		log.Printf("Failed to invalidate email verifications for user %s: %v", verification.UserID, err)
+	}
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Email verified successfully"})
+}
+
+// ResendVerificationEmail handles sending a new verification email to the current user
+func (h *UserHandler) ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Unauthorized(w, "User ID not found in context")
+		return
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	user, err := h.queries.GetUserByID(r.Context(), userUUID)
+	if err != nil {
+		response.NotFound(w, "User not found")
+		return
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		response.BadRequest(w, "Email is already verified")
+		return
+	}
+
+	// Limit outstanding tokens per user
+	active, err := h.queries.CountActiveEmailVerifications(r.Context(), user.ID)
+	if err != nil {
+		response.InternalServerError(w, "Failed to check verification tokens")
+		return
+	}
+	if active >= int64(h.cfg.EmailVerification.MaxActiveTokens) {
+		response.Problemf(w, http.StatusTooManyRequests, "too_many_requests", "Too many verification emails requested, please check your inbox or try again later")
+		return
+	}
+
+	h.sendVerificationEmail(r.Context(), user.ID, user.Username, user.Email)
+
+	response.JSON(w, http.StatusOK, map[string]string{"message": "Verification email sent"})
+}
+
+// sendVerificationEmail creates a verification token for email and mails the
+// verification link. Failures are logged, not returned.
+func (h *UserHandler) sendVerificationEmail(ctx context.Context, userID uuid.UUID, username, email string) {
+	// Generate verification token; only its hash is stored
+	token := generateRandomToken(32)
+	expiresAt := time.Now().Add(h.cfg.EmailVerification.TokenTTL)
+
+	_, err := h.queries.CreateEmailVerification(ctx, repo.CreateEmailVerificationParams{
+		UserID:    userID,
+		Email:     email,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		log.Printf("Failed to create email verification for user %s: %v", userID, err)
+		return
+	}
+
+	msg, err := mail.Render(mail.TemplateEmailVerification, []string{email}, "Verify your DevHive email address", mail.EmailVerificationData{
+		Username:  username,
+		VerifyURL: h.cfg.FrontendURL + h.cfg.EmailVerification.Path + "?token=" + url.QueryEscape(token),
+		ExpiresIn: formatDuration(h.cfg.EmailVerification.TokenTTL),
+	})
+	if err != nil {
+		log.Printf("Failed to render verification email for user %s: %v", userID, err)
+		return
+	}
+
+	// Send before responding: the Lambda entrypoint freezes once the handler
+	// returns, so background sends would be lost
+	if err := h.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", userID, err)
+	}
+}
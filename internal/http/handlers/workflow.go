@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/broadcast"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/permission"
+	"devhive-backend/internal/repo"
+	"devhive-backend/internal/workflow"
+)
+
+type WorkflowHandler struct {
+	queries *repo.Queries
+}
+
+func NewWorkflowHandler(queries *repo.Queries) *WorkflowHandler {
+	return &WorkflowHandler{
+		queries: queries,
+	}
+}
+
+// WorkflowResponse represents a project's task workflow
+type WorkflowResponse struct {
+	ProjectID string `json:"projectId"`
+	workflow.Definition
+}
+
+// GetWorkflow handles getting a project's workflow states and transitions
+func (h *WorkflowHandler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.TaskView)
+	if !ok {
+		return
+	}
+
+	def, err := workflow.Load(r.Context(), h.queries, projectUUID)
+	if err != nil {
+		log.Printf("GetWorkflow: %v", err)
+		response.InternalServerError(w, "Failed to load workflow")
+		return
+	}
+
+	response.JSON(w, http.StatusOK, WorkflowResponse{ProjectID: projectUUID.String(), Definition: def})
+}
+
+// UpdateWorkflow handles replacing a project's workflow. States still used
+// by tasks cannot be removed.
+func (h *WorkflowHandler) UpdateWorkflow(w http.ResponseWriter, r *http.Request) {
+	projectUUID, ok := authorizeProject(w, r, h.queries, permission.ProjectUpdate)
+	if !ok {
+		return
+	}
+
+	var req workflow.Definition
+	if !response.Decode(w, r, &req) {
+		return
+	}
+
+	previous, err := workflow.Load(r.Context(), h.queries, projectUUID)
+	if err != nil {
+		log.Printf("UpdateWorkflow: %v", err)
+		response.InternalServerError(w, "Failed to load workflow")
+		return
+	}
+
+	def, err := workflow.Replace(r.Context(), h.queries, projectUUID, req)
+	if errors.Is(err, workflow.ErrInvalidDefinition) {
+		response.Problemf(w, http.StatusUnprocessableEntity, "invalid_workflow", err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("UpdateWorkflow: %v", err)
+		response.InternalServerError(w, "Failed to update workflow")
+		return
+	}
+
+	activity.Record(r.Context(), h.queries, activity.Event{
+		ProjectID:  projectUUID,
+		ActorID:    actorID(r),
+		Resource:   activity.ResourceWorkflow,
+		ResourceID: projectUUID,
+		Action:     activity.ActionUpdated,
+		Before:     previous,
+		After:      def,
+	})
+
+	resp := WorkflowResponse{ProjectID: projectUUID.String(), Definition: def}
+
+	// Broadcast workflow change so boards can re-render their columns
+	broadcast.Send(r.Context(), projectUUID.String(), broadcast.EventWorkflowUpdated, resp)
+
+	response.JSON(w, http.StatusOK, resp)
+}
+
+// writeWorkflowError writes the response for a failed status check.
+// It returns true when err is nil and the request may continue.
+func writeWorkflowError(w http.ResponseWriter, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, workflow.ErrTransitionNotAllowed):
+		response.Problemf(w, http.StatusUnprocessableEntity, "transition_not_allowed", err.Error())
+	case errors.Is(err, workflow.ErrUnknownStatus):
+		response.Problemf(w, http.StatusUnprocessableEntity, "unknown_status", err.Error())
+	default:
+		log.Printf("Workflow check failed: %v", err)
+		response.InternalServerError(w, "Failed to check task workflow")
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxAuditBody is how much of a request body the admin audit log keeps
+const maxAuditBody = 4096
+
+// AdminStore is the subset of repo.Queries used by RequireSystemAdmin
+type AdminStore interface {
+	IsSystemAdmin(ctx context.Context, userID uuid.UUID) (bool, error)
+	CreateAdminAuditEntry(ctx context.Context, arg repo.CreateAdminAuditEntryParams) error
+}
+
+// RequireSystemAdmin creates middleware that only lets system admins through,
+// and only if they entered their credentials within reauthWindow (see
+// AuthHandler.Reauthenticate). It must run after RequireAuth. Every request
+// is recorded in the admin audit log with its response status, including
+// refused ones.
+func RequireSystemAdmin(store AdminStore, reauthWindow time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, "User ID not found in context")
+				return
+			}
+			userUUID, err := uuid.Parse(userID)
+			if err != nil {
+				response.BadRequest(w, "Invalid user ID")
+				return
+			}
+
+			body := auditBody(r)
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				audit(store, r, userUUID, body, sw.status)
+			}()
+
+			isAdmin, err := store.IsSystemAdmin(r.Context(), userUUID)
+			if err != nil {
+				log.Printf("System admin check failed for user %s: %v", userID, err)
+				response.InternalServerError(sw, "Failed to check admin access")
+				return
+			}
+			if !isAdmin {
+				log.Printf("WARN: User %s tried to use admin endpoint %s %s", userID, r.Method, r.URL.Path)
+				response.Forbidden(sw, "System admin access required")
+				return
+			}
+
+			authTime, ok := GetAuthTimeFromContext(r.Context())
+			if !ok || time.Since(authTime) > reauthWindow {
+				response.Problemf(sw, http.StatusUnauthorized, "reauthentication_required", "Confirm your password at /auth/reauthenticate to use admin endpoints")
+				return
+			}
+
+			next.ServeHTTP(sw, r)
+		})
+	}
+}
+
+// AllowDestructive creates middleware that refuses the route unless enabled
+// is set. It guards admin endpoints that can destroy data or redeploy the
+// service, which stay off unless ADMIN_ALLOW_DESTRUCTIVE is set.
+func AllowDestructive(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !enabled {
+				response.Problemf(w, http.StatusForbidden, "destructive_actions_disabled", "This endpoint is disabled; set ADMIN_ALLOW_DESTRUCTIVE to enable it")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// auditBody reads the start of the request body for the audit log and puts
+// the full body back for the handler
+func auditBody(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	head, err := io.ReadAll(io.LimitReader(r.Body, maxAuditBody))
+	if err != nil {
+		return ""
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+	return string(head)
+}
+
+// audit appends a request to the admin audit log. It runs after the response
+// is written, so it does not use the request's context.
+func audit(store AdminStore, r *http.Request, userID uuid.UUID, body string, status int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := store.CreateAdminAuditEntry(ctx, repo.CreateAdminAuditEntryParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Status:      int32(status),
+		RequestBody: body,
+		RemoteAddr:  r.RemoteAddr,
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to write admin audit log for %s %s by %s: %v", r.Method, r.URL.Path, userID, err)
+	}
+}
+
+// statusWriter records the status code of a response
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
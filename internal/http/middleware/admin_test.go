@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+type fakeAdminStore struct {
+	admins  map[uuid.UUID]bool
+	entries []repo.CreateAdminAuditEntryParams
+}
+
+func (s *fakeAdminStore) IsSystemAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return s.admins[userID], nil
+}
+
+func (s *fakeAdminStore) CreateAdminAuditEntry(ctx context.Context, arg repo.CreateAdminAuditEntryParams) error {
+	s.entries = append(s.entries, arg)
+	return nil
+}
+
+func TestRequireSystemAdmin(t *testing.T) {
+	admin, user := uuid.New(), uuid.New()
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		authTime   time.Time // Zero for a refreshed token
+		wantStatus int
+	}{
+		{"fresh admin", admin, now.Add(-time.Minute), http.StatusCreated},
+		{"admin without recent login", admin, now.Add(-time.Hour), http.StatusUnauthorized},
+		{"admin with refreshed token", admin, time.Time{}, http.StatusUnauthorized},
+		{"not an admin", user, now, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeAdminStore{admins: map[uuid.UUID]bool{admin: true}}
+			var gotBody string
+			handler := RequireSystemAdmin(store, 10*time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(http.StatusCreated)
+			}))
+
+			body := `{"scriptName":"001_initial_schema.sql"}`
+			req := httptest.NewRequest(http.MethodPost, "/migrations/run", strings.NewReader(body))
+			ctx := context.WithValue(req.Context(), UserIDKey, tt.userID.String())
+			if !tt.authTime.IsZero() {
+				ctx = context.WithValue(ctx, AuthTimeKey, tt.authTime)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req.WithContext(ctx))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusCreated && gotBody != body {
+				t.Errorf("handler read body %q, want %q", gotBody, body)
+			}
+			if len(store.entries) != 1 {
+				t.Fatalf("got %d audit entries, want 1", len(store.entries))
+			}
+			entry := store.entries[0]
+			if entry.UserID.Bytes != tt.userID || entry.Status != int32(tt.wantStatus) || entry.Path != "/migrations/run" || entry.RequestBody != body {
+				t.Errorf("audit entry = %+v", entry)
+			}
+		})
+	}
+}
+
+func TestAllowDestructive(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, enabled := range []bool{false, true} {
+		rec := httptest.NewRecorder()
+		AllowDestructive(enabled)(ok).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/migrations/reset", nil))
+
+		want := http.StatusForbidden
+		if enabled {
+			want = http.StatusOK
+		}
+		if rec.Code != want {
+			t.Errorf("enabled=%v: status = %d, want %d", enabled, rec.Code, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -12,7 +13,8 @@ import (
 type ContextKey string
 
 const (
-	UserIDKey ContextKey = "userID"
+	UserIDKey   ContextKey = "userID"
+	AuthTimeKey ContextKey = "authTime"
 )
 
 // RequireAuth creates middleware that requires valid JWT authentication
@@ -64,6 +66,12 @@ func RequireAuth(signingKey string) func(http.Handler) http.Handler {
 
 			// Add user ID to request context
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+
+			// auth_time is when the user last entered their credentials;
+			// tokens issued by a refresh do not carry it
+			if authTime, ok := claims["auth_time"].(float64); ok {
+				ctx = context.WithValue(ctx, AuthTimeKey, time.Unix(int64(authTime), 0))
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -74,3 +82,10 @@ func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value(UserIDKey).(string)
 	return userID, ok
 }
+
+// GetAuthTimeFromContext returns when the user last authenticated with their
+// credentials, if the request's token says so
+func GetAuthTimeFromContext(ctx context.Context) (time.Time, bool) {
+	authTime, ok := ctx.Value(AuthTimeKey).(time.Time)
+	return authTime, ok
+}
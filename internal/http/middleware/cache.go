@@ -1,35 +1,72 @@
 package middleware
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/base64"
-	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"devhive-backend/internal/http/response"
 )
 
-// ETag generates an ETag from data
-func generateETag(data interface{}) string {
-	jsonData, err := json.Marshal(data)
-	if err != nil {
-		return ""
-	}
-	hash := sha256.Sum256(jsonData)
-	return `"` + base64.StdEncoding.EncodeToString(hash[:])[:16] + `"`
+// bodyETag generates a strong ETag from a response body
+func bodyETag(body []byte) string {
+	hash := sha256.Sum256(body)
+	return `"` + base64.RawURLEncoding.EncodeToString(hash[:16]) + `"`
 }
 
-// CacheControl adds cache control headers to responses
+// CacheControl adds cache control headers to successful GET responses.
+// A maxAge of 0 lets clients keep the response but makes them revalidate it
+// on every use, which with ETagMiddleware costs a 304. Handlers that set
+// their own Cache-Control keep it.
 func CacheControl(maxAge int) func(http.Handler) http.Handler {
+	value := "private, no-cache"
+	if maxAge > 0 {
+		value = "private, max-age=" + strconv.Itoa(maxAge)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Set cache control header
-			w.Header().Set("Cache-Control", "private, max-age="+string(rune(maxAge)))
-			
-			next.ServeHTTP(w, r)
+			if r.Method != http.MethodGet {
+				next.ServeHTTP(w, r)
+				return
+			}
+			next.ServeHTTP(&cacheControlWriter{ResponseWriter: w, value: value}, r)
 		})
 	}
 }
 
-// ETagMiddleware handles ETag-based conditional requests
+// cacheControlWriter sets Cache-Control when the status is written, so error
+// responses are never cached
+type cacheControlWriter struct {
+	http.ResponseWriter
+	value       string
+	wroteHeader bool
+}
+
+func (w *cacheControlWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if (status == http.StatusOK || status == http.StatusNotModified) && w.Header().Get("Cache-Control") == "" {
+			w.Header().Set("Cache-Control", w.value)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// ETagMiddleware handles ETag-based conditional requests. It buffers GET
+// responses, tags successful ones with a strong ETag - a hash of the body,
+// appended to the ETag the handler set if any - and answers a matching
+// If-None-Match with 304 Not Modified instead of the body.
 func ETagMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Only handle GET requests
@@ -38,35 +75,66 @@ func ETagMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
-		// Check if client sent If-None-Match header
-		ifNoneMatch := r.Header.Get("If-None-Match")
-		if ifNoneMatch != "" {
-			// Store the ETag we'll generate in the response
-			// We'll need to intercept the response to generate ETag
-			// For now, just pass through - handlers will set ETag manually
+		buf := &bufferedWriter{header: w.Header(), status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		if buf.status != http.StatusOK {
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+			return
 		}
 
-		next.ServeHTTP(w, r)
+		// A handler's version ETag stays usable for If-Match, but bodies
+		// also hold data the version does not cover, such as the caller's
+		// role or label names, so the body hash is appended to it
+		etag := bodyETag(buf.body.Bytes())
+		if version := w.Header().Get("ETag"); version != "" {
+			etag = strings.TrimSuffix(version, `"`) + "." + strings.Trim(etag, `"`) + `"`
+		}
+		w.Header().Set("ETag", etag)
+
+		if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && response.ETagMatches(ifNoneMatch, etag, true) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(buf.body.Len()))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.body.Bytes())
 	})
 }
 
-// SetETag sets ETag header and checks If-None-Match for 304 response
-func SetETag(w http.ResponseWriter, r *http.Request, data interface{}) bool {
-	etag := generateETag(data)
-	w.Header().Set("ETag", etag)
-
-	// Check if client has matching ETag
-	ifNoneMatch := r.Header.Get("If-None-Match")
-	if ifNoneMatch == etag {
-		w.WriteHeader(http.StatusNotModified)
-		return true // Response already sent
+// Cacheable combines CacheControl and ETagMiddleware for read routes
+func Cacheable(maxAge int) func(http.Handler) http.Handler {
+	cacheControl := CacheControl(maxAge)
+	return func(next http.Handler) http.Handler {
+		return cacheControl(ETagMiddleware(next))
 	}
-
-	return false // Continue with normal response
 }
 
+// bufferedWriter holds a response until its ETag is known. Headers go
+// straight to the underlying writer's map.
+type bufferedWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
 
+func (w *bufferedWriter) Header() http.Header {
+	return w.header
+}
 
+func (w *bufferedWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+}
 
-
-
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.body.Write(b)
+}
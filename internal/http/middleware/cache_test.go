@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestETagMiddlewareVersionTag(t *testing.T) {
+	role := "viewer"
+	handler := ETagMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The version does not change with the caller's role
+		w.Header().Set("ETag", `"p1-3"`)
+		w.Write([]byte(`{"id":"p1","userRole":"` + role + `"}`))
+	}))
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/projects/p1", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `"p1-3.`) {
+		t.Fatalf("first GET: status %d, ETag %q", first.Code, etag)
+	}
+
+	if rec := get(etag); rec.Code != http.StatusNotModified {
+		t.Errorf("unchanged body: status %d, want 304", rec.Code)
+	}
+	if rec := get(`"p1-3"`); rec.Code != http.StatusOK {
+		t.Errorf("bare version tag: status %d, want 200", rec.Code)
+	}
+
+	role = "admin"
+	rec := get(etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("changed role: status %d, ETag %q, want 200 and a new tag", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/permission"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RequirePermission creates middleware that requires the authenticated user's
+// role in the {projectId} route parameter to grant action. It must run after
+// RequireAuth. The resolved role is stored in the request context so handlers
+// can re-check permissions without another query.
+func RequirePermission(lookup permission.RoleLookup, action permission.Action) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := GetUserIDFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, "User ID not found in context")
+				return
+			}
+			userUUID, err := uuid.Parse(userID)
+			if err != nil {
+				response.BadRequest(w, "Invalid user ID")
+				return
+			}
+			projectUUID, err := uuid.Parse(chi.URLParam(r, "projectId"))
+			if err != nil {
+				response.BadRequest(w, "Invalid project ID")
+				return
+			}
+
+			role, err := permission.Authorize(r.Context(), lookup, projectUUID, userUUID, action)
+			if !WritePermissionError(w, err) {
+				return
+			}
+
+			ctx := permission.WithRole(r.Context(), projectUUID, userUUID, role)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WritePermissionError writes the response for a failed permission check.
+// It returns true when err is nil and the request may continue.
+func WritePermissionError(w http.ResponseWriter, err error) bool {
+	var denied *permission.DeniedError
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, permission.ErrNotMember):
+		response.Forbidden(w, "Access denied to project")
+	case errors.As(err, &denied):
+		response.Forbidden(w, "Your role ("+string(denied.Role)+") does not allow "+string(denied.Action))
+	default:
+		log.Printf("Permission check failed: %v", err)
+		response.InternalServerError(w, "Failed to check project permissions")
+	}
+	return false
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/httprate"
+)
+
+// LimitByUser creates middleware that allows each authenticated user limit
+// requests per window. It must run after RequireAuth.
+func LimitByUser(limit int, window time.Duration) func(http.Handler) http.Handler {
+	return httprate.Limit(limit, window, httprate.WithKeyFuncs(func(r *http.Request) (string, error) {
+		userID, ok := GetUserIDFromContext(r.Context())
+		if !ok {
+			return "", errors.New("user ID not found in context")
+		}
+		return userID, nil
+	}))
+}
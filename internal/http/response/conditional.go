@@ -0,0 +1,52 @@
+package response
+
+import (
+	"net/http"
+	"strings"
+)
+
+// ETagMatches reports whether an If-Match or If-None-Match header value
+// lists etag. "*" matches any representation. If-Match uses strong
+// comparison, where weak tags never match; If-None-Match passes weak to
+// compare tags with any W/ prefix removed.
+func ETagMatches(header, etag string, weak bool) bool {
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if weak {
+			tag = strings.TrimPrefix(tag, "W/")
+		} else if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionTagMatches reports whether an If-Match header lists etag, a
+// version ETag, either as updates return it or with the representation hash
+// that conditional GETs append to it
+func VersionTagMatches(header, etag string) bool {
+	prefix := strings.TrimSuffix(etag, `"`) + "."
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag || (strings.HasPrefix(tag, prefix) && strings.HasSuffix(tag, `"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreconditionFailed sends a 412 Precondition Failed response carrying the
+// resource's current representation and ETag, so the client can merge and retry
+func PreconditionFailed(w http.ResponseWriter, etag string, current interface{}) {
+	w.Header().Set("ETag", etag)
+	JSON(w, http.StatusPreconditionFailed, current)
+}
@@ -0,0 +1,58 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"devhive-backend/internal/pagination"
+)
+
+// ParsePage reads the limit and cursor query parameters, writing a 400 for
+// a malformed cursor
+func ParsePage(w http.ResponseWriter, r *http.Request) (pagination.Page, bool) {
+	page := pagination.Page{Limit: pagination.DefaultLimit}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= pagination.MaxLimit {
+			page.Limit = l
+		}
+	}
+
+	if cursorStr := r.URL.Query().Get("cursor"); cursorStr != "" {
+		c, err := pagination.DecodeCursor(cursorStr)
+		if err != nil {
+			Problemf(w, http.StatusBadRequest, "invalid_cursor", "Cursor is malformed or expired")
+			return pagination.Page{}, false
+		}
+		page.Cursor = &c
+	}
+
+	return page, true
+}
+
+// Paginated sends one page of a list. items is sent under key together with
+// the limit and nextCursor; when next is non-nil an RFC 8288 Link header
+// points at the following page.
+func Paginated(w http.ResponseWriter, r *http.Request, key string, items interface{}, page pagination.Page, next *pagination.Cursor) {
+	body := map[string]interface{}{
+		key:          items,
+		"limit":      page.Limit,
+		"nextCursor": nil,
+	}
+
+	if next != nil {
+		encoded := next.Encode()
+		body["nextCursor"] = encoded
+
+		nextURL := *r.URL
+		query := nextURL.Query()
+		query.Set("cursor", encoded)
+		query.Set("limit", strconv.Itoa(page.Limit))
+		query.Del("offset")
+		nextURL.RawQuery = query.Encode()
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, nextURL.RequestURI()))
+	}
+
+	JSON(w, http.StatusOK, body)
+}
@@ -88,6 +88,11 @@ func NotFound(w http.ResponseWriter, message string) {
 	Problemf(w, http.StatusNotFound, "not_found", message)
 }
 
+// Conflict sends a 409 Conflict response
+func Conflict(w http.ResponseWriter, message string) {
+	Problemf(w, http.StatusConflict, "conflict", message)
+}
+
 // InternalServerError sends a 500 Internal Server Error response
 func InternalServerError(w http.ResponseWriter, message string) {
 	Problemf(w, http.StatusInternalServerError, "internal_server_error", message)
@@ -2,12 +2,16 @@ package router
 
 import (
 	"database/sql"
+	"log"
 	"os"
 	"time"
 
 	"devhive-backend/internal/config"
 	"devhive-backend/internal/http/handlers"
 	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/idempotency"
+	"devhive-backend/internal/mail"
+	"devhive-backend/internal/permission"
 	"devhive-backend/internal/repo"
 	"devhive-backend/internal/ws"
 
@@ -36,8 +40,8 @@ func Setup(cfg *config.Config, queries *repo.Queries, db interface{}, hub *ws.Hu
 		r.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   cfg.CORS.AllowedOrigins,
 			AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
-			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", "Origin", "Cookie"},
-			ExposedHeaders:   []string{"Set-Cookie", "X-Request-Id", "Link"},
+			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-Requested-With", "Origin", "Cookie", "If-Match", "If-None-Match", "Idempotency-Key"},
+			ExposedHeaders:   []string{"Set-Cookie", "X-Request-Id", "Link", "ETag", "Idempotent-Replayed"},
 			AllowCredentials: cfg.CORS.AllowCredentials,
 			MaxAge:           300,
 		}))
@@ -61,16 +65,33 @@ func Setup(cfg *config.Config, queries *repo.Queries, db interface{}, hub *ws.Hu
 func setupV1Routes(cfg *config.Config, queries *repo.Queries, db interface{}, hub *ws.Hub) chi.Router {
 	r := chi.NewRouter()
 
+	// Initialize mailer
+	mailer, err := mail.New(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to initialize mailer: %v", err)
+	}
+
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(cfg, queries)
-	userHandler := handlers.NewUserHandler(queries)
-	projectHandler := handlers.NewProjectHandler(queries)
+	authHandler := handlers.NewAuthHandler(cfg, queries, mailer)
+	userHandler := handlers.NewUserHandler(queries, cfg, mailer)
+	projectHandler := handlers.NewProjectHandler(queries, cfg, mailer)
 	sprintHandler := handlers.NewSprintHandler(queries)
 	taskHandler := handlers.NewTaskHandler(queries)
+	workflowHandler := handlers.NewWorkflowHandler(queries)
+	labelHandler := handlers.NewLabelHandler(queries)
+	trashHandler := handlers.NewTrashHandler(queries)
 	messageHandler := handlers.NewMessageHandler(queries, cfg, hub)
-	mailHandler := handlers.NewMailHandler(cfg)
+	activityHandler := handlers.NewActivityHandler(queries)
+	mailHandler := handlers.NewMailHandler(cfg, mailer)
 	migrationHandler := handlers.NewMigrationHandler(queries, db.(*sql.DB))
 
+	// Project, sprint, task and member reads answer If-None-Match with 304;
+	// clients revalidate on every use since teammates edit the same data
+	readCache := middleware.Cacheable(0)
+
+	// Creates honor Idempotency-Key so clients can retry them safely
+	idempotent := idempotency.Middleware(queries, cfg.Idempotency.TTL, cfg.Idempotency.LockLease)
+
 	// Auth routes (public)
 	r.Route("/auth", func(auth chi.Router) {
 		auth.Post("/login", authHandler.Login)
@@ -79,6 +100,7 @@ func setupV1Routes(cfg *config.Config, queries *repo.Queries, db interface{}, hu
 		auth.Post("/password/reset-request", authHandler.RequestPasswordReset)
 		auth.Post("/password/reset", authHandler.ResetPassword)
 		auth.With(middleware.RequireAuth(cfg.JWT.SigningKey)).Post("/password/change", authHandler.ChangePassword)
+		auth.With(middleware.RequireAuth(cfg.JWT.SigningKey)).Post("/reauthenticate", authHandler.Reauthenticate)
 		auth.Get("/validate-token", authHandler.ValidateToken) // Public endpoint to check token validity
 
 		// Google OAuth routes (public)
@@ -97,6 +119,8 @@ func setupV1Routes(cfg *config.Config, queries *repo.Queries, db interface{}, hu
 		users.Post("/validate-email", userHandler.ValidateEmail)
 		users.Get("/validate-username", userHandler.ValidateUsername)
 		users.Post("/validate-username", userHandler.ValidateUsername)
+		users.Post("/verify-email", userHandler.VerifyEmail)
+		users.With(middleware.RequireAuth(cfg.JWT.SigningKey)).Post("/verify-email/resend", userHandler.ResendVerificationEmail)
 		users.With(middleware.RequireAuth(cfg.JWT.SigningKey)).Get("/me", userHandler.GetMe)
 		users.With(middleware.RequireAuth(cfg.JWT.SigningKey)).Patch("/me", userHandler.UpdateMe)
 		users.With(middleware.RequireAuth(cfg.JWT.SigningKey)).Get("/{userId}", userHandler.GetUser)
@@ -108,39 +132,62 @@ func setupV1Routes(cfg *config.Config, queries *repo.Queries, db interface{}, hu
 	// Project routes
 	r.Route("/projects", func(projects chi.Router) {
 		projects.Use(middleware.RequireAuth(cfg.JWT.SigningKey))
-		projects.Get("/", projectHandler.ListProjects)
-		projects.Post("/", projectHandler.CreateProject)
+		projects.With(readCache).Get("/", projectHandler.ListProjects)
+		projects.With(idempotent).Post("/", projectHandler.CreateProject)
 		// Join by project code/ID (must be defined before /{projectId} routes)
 		projects.Post("/join", projectHandler.JoinProject)
-		projects.Get("/{projectId}", projectHandler.GetProject)
-		projects.Get("/{projectId}/bundle", projectHandler.GetProjectBundle)
-		projects.Patch("/{projectId}", projectHandler.UpdateProject)
-		projects.Delete("/{projectId}", projectHandler.DeleteProject)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectView), readCache).Get("/{projectId}", projectHandler.GetProject)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectView), readCache).Get("/{projectId}/bundle", projectHandler.GetProjectBundle)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectUpdate)).Patch("/{projectId}", projectHandler.UpdateProject)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectDelete)).Delete("/{projectId}", projectHandler.DeleteProject)
+
+		// Trash: deleted projects only grant roles again once restored, so
+		// restoring checks ownership in the handler instead of RequirePermission
+		projects.Get("/trash", trashHandler.ListDeletedProjects)
+		projects.Post("/{projectId}/restore", trashHandler.RestoreProject)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectView)).Get("/{projectId}/trash", trashHandler.ListProjectTrash)
 
 		// Project members
-		projects.Get("/{projectId}/members", projectHandler.ListMembers)
-		projects.Put("/{projectId}/members/{userId}", projectHandler.AddMember)
+		projects.With(middleware.RequirePermission(queries, permission.MemberView), readCache).Get("/{projectId}/members", projectHandler.ListMembers)
+		projects.With(middleware.RequirePermission(queries, permission.MemberManage)).Put("/{projectId}/members/{userId}", projectHandler.AddMember)
+		projects.With(middleware.RequirePermission(queries, permission.MemberManage)).Patch("/{projectId}/members/{userId}", projectHandler.UpdateMemberRole)
 		projects.Delete("/{projectId}/members/{userId}", projectHandler.RemoveMember)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectTransfer)).Post("/{projectId}/transfer-ownership", projectHandler.TransferOwnership)
 
 		// Project invites
-		projects.Post("/{projectId}/invites", projectHandler.CreateInvite)
-		projects.Get("/{projectId}/invites", projectHandler.ListInvites)
-		projects.Delete("/{projectId}/invites/{inviteId}", projectHandler.RevokeInvite)
+		projects.With(middleware.RequirePermission(queries, permission.InviteCreate)).Post("/{projectId}/invites", projectHandler.CreateInvite)
+		projects.With(middleware.RequirePermission(queries, permission.InviteView)).Get("/{projectId}/invites", projectHandler.ListInvites)
+		projects.With(middleware.RequirePermission(queries, permission.InviteRevoke)).Delete("/{projectId}/invites/{inviteId}", projectHandler.RevokeInvite)
+		projects.With(middleware.RequirePermission(queries, permission.InviteCreate)).Post("/{projectId}/invites/{inviteId}/resend", projectHandler.ResendInvite)
 
 		// Project sprints
-		projects.Get("/{projectId}/sprints", sprintHandler.ListSprintsByProject)
-		projects.Post("/{projectId}/sprints", sprintHandler.CreateSprint)
+		projects.With(middleware.RequirePermission(queries, permission.SprintView), readCache).Get("/{projectId}/sprints", sprintHandler.ListSprintsByProject)
+		projects.With(middleware.RequirePermission(queries, permission.SprintCreate), idempotent).Post("/{projectId}/sprints", sprintHandler.CreateSprint)
 
 		// Project tasks
-		projects.Get("/{projectId}/tasks", taskHandler.ListTasksByProject)
-		projects.Post("/{projectId}/tasks", taskHandler.CreateTask)
+		projects.With(middleware.RequirePermission(queries, permission.TaskView), readCache).Get("/{projectId}/tasks", taskHandler.ListTasksByProject)
+		projects.With(middleware.RequirePermission(queries, permission.TaskCreate), idempotent).Post("/{projectId}/tasks", taskHandler.CreateTask)
+		projects.With(middleware.RequirePermission(queries, permission.TaskView), idempotent).Post("/{projectId}/tasks/bulk", taskHandler.BulkTasks)
+
+		// Project labels
+		projects.With(middleware.RequirePermission(queries, permission.TaskView)).Get("/{projectId}/labels", labelHandler.ListLabels)
+		projects.With(middleware.RequirePermission(queries, permission.TaskCreate)).Post("/{projectId}/labels", labelHandler.CreateLabel)
+		projects.With(middleware.RequirePermission(queries, permission.TaskUpdate)).Patch("/{projectId}/labels/{labelId}", labelHandler.UpdateLabel)
+		projects.With(middleware.RequirePermission(queries, permission.TaskDelete)).Delete("/{projectId}/labels/{labelId}", labelHandler.DeleteLabel)
+
+		// Project task workflow
+		projects.With(middleware.RequirePermission(queries, permission.TaskView)).Get("/{projectId}/workflow", workflowHandler.GetWorkflow)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectUpdate)).Put("/{projectId}/workflow", workflowHandler.UpdateWorkflow)
 
 		// Project messages
-		projects.Get("/{projectId}/messages", messageHandler.ListMessagesByProject)
-		projects.Post("/{projectId}/messages", messageHandler.CreateMessage)
+		projects.With(middleware.RequirePermission(queries, permission.MessageView)).Get("/{projectId}/messages", messageHandler.ListMessagesByProject)
+		projects.With(middleware.RequirePermission(queries, permission.MessageCreate), idempotent).Post("/{projectId}/messages", messageHandler.CreateMessage)
+
+		// Project activity feed
+		projects.With(middleware.RequirePermission(queries, permission.ProjectView)).Get("/{projectId}/activity", activityHandler.ListActivity)
 
 		// WebSocket status (for debugging)
-		projects.Get("/{projectId}/ws/status", messageHandler.GetWebSocketStatus)
+		projects.With(middleware.RequirePermission(queries, permission.ProjectView)).Get("/{projectId}/ws/status", messageHandler.GetWebSocketStatus)
 	})
 
 	// Protected invite accept route (auth required)
@@ -152,48 +199,59 @@ func setupV1Routes(cfg *config.Config, queries *repo.Queries, db interface{}, hu
 	// Sprint routes
 	r.Route("/sprints", func(sprints chi.Router) {
 		sprints.Use(middleware.RequireAuth(cfg.JWT.SigningKey))
-		sprints.Get("/{sprintId}", sprintHandler.GetSprint)
+		sprints.With(readCache).Get("/{sprintId}", sprintHandler.GetSprint)
 		sprints.Patch("/{sprintId}", sprintHandler.UpdateSprint)
 		sprints.Patch("/{sprintId}/status", sprintHandler.UpdateSprintStatus)
 		sprints.Delete("/{sprintId}", sprintHandler.DeleteSprint)
-		sprints.Get("/{sprintId}/tasks", taskHandler.ListTasksBySprint)
+		sprints.Post("/{sprintId}/restore", trashHandler.RestoreSprint)
+		sprints.With(readCache).Get("/{sprintId}/tasks", taskHandler.ListTasksBySprint)
 	})
 
 	// Task routes
 	r.Route("/tasks", func(tasks chi.Router) {
 		tasks.Use(middleware.RequireAuth(cfg.JWT.SigningKey))
-		tasks.Get("/{taskId}", taskHandler.GetTask)
+		tasks.With(readCache).Get("/{taskId}", taskHandler.GetTask)
 		tasks.Patch("/{taskId}", taskHandler.UpdateTask)
 		tasks.Patch("/{taskId}/status", taskHandler.UpdateTaskStatus)
 		tasks.Delete("/{taskId}", taskHandler.DeleteTask)
+		tasks.Post("/{taskId}/restore", trashHandler.RestoreTask)
 	})
 
 	// Message routes
 	r.Route("/messages", func(messages chi.Router) {
 		messages.Use(middleware.RequireAuth(cfg.JWT.SigningKey))
-		messages.Post("/", messageHandler.CreateMessage)
+		messages.With(idempotent).Post("/", messageHandler.CreateMessage)
 		messages.Get("/", messageHandler.ListMessages)
 	})
 
 	// WebSocket route - separate route without middleware (auth handled in WebSocketHandler itself)
 	r.Get("/messages/ws", messageHandler.WebSocketHandler)
 
-	// Mail routes
+	// Mail routes (system admins only, rate limited per user; every request
+	// is audited)
 	r.Route("/mail", func(mail chi.Router) {
 		mail.Use(middleware.RequireAuth(cfg.JWT.SigningKey))
+		mail.Use(middleware.RequireSystemAdmin(queries, cfg.Admin.ReauthWindow))
+		mail.Use(middleware.LimitByUser(cfg.Mail.SendLimit, time.Hour))
 		mail.Post("/send", mailHandler.SendEmail)
 	})
 
-	// Migration routes (admin only - no auth for now, but should be protected in production)
+	// Migration routes (system admins who recently confirmed their password;
+	// every request is audited)
 	r.Route("/migrations", func(migrations chi.Router) {
+		migrations.Use(middleware.RequireAuth(cfg.JWT.SigningKey))
+		migrations.Use(middleware.RequireSystemAdmin(queries, cfg.Admin.ReauthWindow))
 		migrations.Post("/run", migrationHandler.RunMigration)
-		migrations.Post("/reset", migrationHandler.ResetDatabase)
 		migrations.Get("/list", migrationHandler.ListMigrations)
 		migrations.Get("/verify", migrationHandler.VerifyMigration)
-		migrations.Post("/rebuild-deploy", migrationHandler.RebuildAndDeploy)
-		migrations.Post("/run-and-deploy", migrationHandler.RunMigrationAndDeploy)
 		migrations.Get("/health", migrationHandler.HealthCheck)
 		migrations.Post("/test-notify", migrationHandler.TestNotifyTrigger)
+
+		// Destructive routes are off unless ADMIN_ALLOW_DESTRUCTIVE is set
+		destructive := middleware.AllowDestructive(cfg.Admin.AllowDestructive)
+		migrations.With(destructive).Post("/reset", migrationHandler.ResetDatabase)
+		migrations.With(destructive).Post("/rebuild-deploy", migrationHandler.RebuildAndDeploy)
+		migrations.With(destructive).Post("/run-and-deploy", migrationHandler.RunMigrationAndDeploy)
 	})
 
 	// Legacy route shims (temporary for backward compatibility)
@@ -208,49 +266,3 @@ func setupV1Routes(cfg *config.Config, queries *repo.Queries, db interface{}, hu
 
 	return r
 }
-
-// setupLegacyRoutes configures legacy API routes for backward compatibility
-func setupLegacyRoutes(cfg *config.Config, queries *repo.Queries) chi.Router {
-	r := chi.NewRouter()
-
-	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(cfg, queries)
-	userHandler := handlers.NewUserHandler(queries)
-	projectHandler := handlers.NewProjectHandler(queries)
-	sprintHandler := handlers.NewSprintHandler(queries)
-	taskHandler := handlers.NewTaskHandler(queries)
-	messageHandler := handlers.NewMessageHandler(queries, cfg, ws.GlobalHub)
-	mailHandler := handlers.NewMailHandler(cfg)
-
-	// Legacy route mappings
-	r.Post("/User/ProcessLogin", authHandler.Login)
-	r.Post("/User/Register", userHandler.CreateUser)
-	r.Get("/User/{id}", userHandler.GetUser)
-	r.Post("/User/RequestPasswordReset", authHandler.RequestPasswordReset)
-	r.Post("/User/ResetPassword", authHandler.ResetPassword)
-
-	r.Post("/Scrum/Project", projectHandler.CreateProject)
-	r.Get("/Scrum/Project/{id}", projectHandler.GetProject)
-	r.Put("/Scrum/Project/{id}", projectHandler.UpdateProject)
-	r.Delete("/Scrum/Project/{id}", projectHandler.DeleteProject)
-	r.Get("/Scrum/Project/{id}/Sprints", sprintHandler.ListSprintsByProject)
-	r.Get("/Scrum/Project/{id}/Tasks", taskHandler.ListTasksByProject)
-
-	r.Post("/Scrum/Task", taskHandler.CreateTask)
-	r.Get("/Scrum/Task/{id}", taskHandler.GetTask)
-	r.Patch("/Scrum/Task/{id}", taskHandler.UpdateTask)
-	r.Patch("/Scrum/Task/{id}/status", taskHandler.UpdateTaskStatus)
-	r.Delete("/Scrum/Task/{id}", taskHandler.DeleteTask)
-
-	r.Get("/Scrum/Sprint/{id}", sprintHandler.GetSprint)
-	r.Put("/Scrum/Sprint/{id}", sprintHandler.UpdateSprint)
-	r.Delete("/Scrum/Sprint/{id}", sprintHandler.DeleteSprint)
-	r.Get("/Scrum/Sprint/{id}/Tasks", taskHandler.ListTasksBySprint)
-
-	r.Post("/Message/Send", messageHandler.CreateMessage)
-	r.Get("/Message/Retrieve/{fromUserId}/{toUserId}/{projectId}", messageHandler.ListMessages)
-
-	r.Post("/Mail/Send", mailHandler.SendEmail)
-
-	return r
-}
@@ -0,0 +1,240 @@
+// Package idempotency makes create requests safe to retry. A client sends an
+// Idempotency-Key header; the first request with a key runs and its response
+// is stored, and retries with the same key and payload get the stored
+// response instead of creating a duplicate.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/http/response"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Header is the request header carrying the client's key
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed from a stored result
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLength bounds the keys clients may send
+const maxKeyLength = 255
+
+// maxBody is the largest request body the middleware will fingerprint
+const maxBody = 1 << 20
+
+// Store is the subset of repo.Queries used by the middleware and purge job
+type Store interface {
+	ClaimIdempotencyKey(ctx context.Context, arg repo.ClaimIdempotencyKeyParams) (int64, error)
+	GetIdempotencyKey(ctx context.Context, arg repo.GetIdempotencyKeyParams) (repo.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, arg repo.CompleteIdempotencyKeyParams) error
+	ReleaseIdempotencyKey(ctx context.Context, arg repo.ReleaseIdempotencyKeyParams) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+// Middleware honors the Idempotency-Key header for the authenticated user;
+// requests without it pass through. It must run after RequireAuth. A key
+// is bound to the method, path and body of its first request:
+//   - a retry with the same payload gets the stored status and body, with
+//     Idempotent-Replayed: true
+//   - the same key with a different payload gets 409
+//   - a retry while the first request is still running gets 409
+//
+// Responses with a 5xx status are not stored, so the request can be retried.
+// Keys are kept for ttl. A running request holds its key for lease, after
+// which a retry may take it over; this frees keys whose server stopped
+// mid-request, so lease must be longer than any request runs.
+func Middleware(store Store, ttl, lease time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(Header)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxKeyLength {
+				response.BadRequest(w, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			userID, ok := middleware.GetUserIDFromContext(r.Context())
+			if !ok {
+				response.Unauthorized(w, "User ID not found in context")
+				return
+			}
+			userUUID, err := uuid.Parse(userID)
+			if err != nil {
+				response.BadRequest(w, "Invalid user ID")
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBody))
+			if err != nil {
+				response.Problemf(w, http.StatusRequestEntityTooLarge, "request_too_large", "Request body is too large for an idempotent request")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			sum := fingerprint(r, body)
+
+			now := time.Now()
+			lockedUntil := now.Add(lease)
+			token := uuid.New()
+			claimed, err := store.ClaimIdempotencyKey(r.Context(), repo.ClaimIdempotencyKeyParams{
+				UserID:         userUUID,
+				IdempotencyKey: key,
+				Fingerprint:    sum,
+				ExpiresAt:      now.Add(ttl),
+				LockedUntil:    &lockedUntil,
+				ClaimToken:     token,
+			})
+			if err != nil {
+				log.Printf("ERROR: Failed to claim idempotency key for user %s: %v", userID, err)
+				response.InternalServerError(w, "Failed to check idempotency key")
+				return
+			}
+			if claimed == 0 {
+				replay(w, r, store, userUUID, key, sum)
+				return
+			}
+
+			run(w, r, next, store, userUUID, key, token)
+		})
+	}
+}
+
+// run serves a request that claimed its key with token and stores the
+// response, or releases the key if the request failed. Both are skipped if
+// the claim was taken over after its lease ran out.
+func run(w http.ResponseWriter, r *http.Request, next http.Handler, store Store, userID uuid.UUID, key string, token uuid.UUID) {
+	// Stored after the response is sent, even if the client has gone
+	ctx := context.WithoutCancel(r.Context())
+	rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+	stored := false
+	defer func() {
+		if stored {
+			return
+		}
+		err := store.ReleaseIdempotencyKey(ctx, repo.ReleaseIdempotencyKeyParams{UserID: userID, IdempotencyKey: key, ClaimToken: token})
+		if err != nil {
+			log.Printf("ERROR: Failed to release idempotency key for user %s: %v", userID, err)
+		}
+	}()
+
+	next.ServeHTTP(rec, r)
+
+	if rec.status >= http.StatusInternalServerError {
+		return
+	}
+	status := int32(rec.status)
+	err := store.CompleteIdempotencyKey(ctx, repo.CompleteIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+		Status:         &status,
+		ContentType:    rec.Header().Get("Content-Type"),
+		Body:           rec.body.Bytes(),
+		ClaimToken:     token,
+	})
+	if err != nil {
+		log.Printf("ERROR: Failed to store idempotent response for user %s: %v", userID, err)
+		return
+	}
+	stored = true
+}
+
+// replay answers a request whose key is already taken
+func replay(w http.ResponseWriter, r *http.Request, store Store, userID uuid.UUID, key, sum string) {
+	stored, err := store.GetIdempotencyKey(r.Context(), repo.GetIdempotencyKeyParams{UserID: userID, IdempotencyKey: key})
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released or purged between the claim and now
+		w.Header().Set("Retry-After", "1")
+		response.Problemf(w, http.StatusConflict, "idempotency_key_in_use", "A request with this Idempotency-Key was just processed; retry")
+		return
+	}
+	if err != nil {
+		log.Printf("ERROR: Failed to load idempotency key for user %s: %v", userID, err)
+		response.InternalServerError(w, "Failed to check idempotency key")
+		return
+	}
+
+	switch {
+	case stored.Fingerprint != sum:
+		response.Problemf(w, http.StatusConflict, "idempotency_key_reused", "This Idempotency-Key was already used for a different request")
+	case stored.Status == nil:
+		w.Header().Set("Retry-After", "1")
+		response.Problemf(w, http.StatusConflict, "idempotency_key_in_use", "A request with this Idempotency-Key is still in progress")
+	default:
+		if stored.ContentType != "" {
+			w.Header().Set("Content-Type", stored.ContentType)
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(int(*stored.Status))
+		w.Write(stored.Body)
+	}
+}
+
+// fingerprint identifies the request a key was first used for
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.Path+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder passes a response through while keeping a copy of it
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *recorder) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *recorder) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// StartPurger deletes expired keys every interval until ctx is canceled
+func StartPurger(ctx context.Context, store Store, interval time.Duration) {
+	if interval <= 0 {
+		log.Println("idempotency: purge job disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if n, err := store.DeleteExpiredIdempotencyKeys(ctx); err != nil {
+				log.Printf("idempotency: purge failed: %v", err)
+			} else if n > 0 {
+				log.Printf("idempotency: purged %d expired keys", n)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,197 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"devhive-backend/internal/http/middleware"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type keyID struct {
+	user uuid.UUID
+	key  string
+}
+
+type fakeStore struct {
+	keys map[keyID]repo.IdempotencyKey
+}
+
+func (s *fakeStore) ClaimIdempotencyKey(ctx context.Context, arg repo.ClaimIdempotencyKeyParams) (int64, error) {
+	id := keyID{arg.UserID, arg.IdempotencyKey}
+	now := time.Now()
+	if k, ok := s.keys[id]; ok && k.ExpiresAt.After(now) && (k.Status != nil || k.LockedUntil.After(now)) {
+		return 0, nil
+	}
+	s.keys[id] = repo.IdempotencyKey{UserID: arg.UserID, IdempotencyKey: arg.IdempotencyKey, Fingerprint: arg.Fingerprint, ExpiresAt: arg.ExpiresAt, LockedUntil: arg.LockedUntil, ClaimToken: arg.ClaimToken}
+	return 1, nil
+}
+
+func (s *fakeStore) GetIdempotencyKey(ctx context.Context, arg repo.GetIdempotencyKeyParams) (repo.IdempotencyKey, error) {
+	k, ok := s.keys[keyID{arg.UserID, arg.IdempotencyKey}]
+	if !ok {
+		return repo.IdempotencyKey{}, pgx.ErrNoRows
+	}
+	return k, nil
+}
+
+func (s *fakeStore) CompleteIdempotencyKey(ctx context.Context, arg repo.CompleteIdempotencyKeyParams) error {
+	id := keyID{arg.UserID, arg.IdempotencyKey}
+	k, ok := s.keys[id]
+	if !ok || k.ClaimToken != arg.ClaimToken {
+		return nil
+	}
+	k.Status, k.ContentType, k.Body, k.LockedUntil = arg.Status, arg.ContentType, arg.Body, nil
+	s.keys[id] = k
+	return nil
+}
+
+func (s *fakeStore) ReleaseIdempotencyKey(ctx context.Context, arg repo.ReleaseIdempotencyKeyParams) error {
+	id := keyID{arg.UserID, arg.IdempotencyKey}
+	if k, ok := s.keys[id]; ok && k.Status == nil && k.ClaimToken == arg.ClaimToken {
+		delete(s.keys, id)
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	store := &fakeStore{keys: map[keyID]repo.IdempotencyKey{}}
+	user := uuid.New()
+	calls := 0
+	fail := false
+	handler := Middleware(store, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"task-1"}`))
+	}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/projects/p1/tasks", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(Header, key)
+		}
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, user.String()))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	first := send("k1", `{"title":"a"}`)
+	if first.Code != http.StatusCreated || first.Header().Get(ReplayedHeader) != "" {
+		t.Fatalf("first request: status %d, replayed %q", first.Code, first.Header().Get(ReplayedHeader))
+	}
+
+	retry := send("k1", `{"title":"a"}`)
+	if retry.Code != http.StatusCreated || retry.Body.String() != `{"id":"task-1"}` || retry.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("retry: status %d, body %q, replayed %q", retry.Code, retry.Body.String(), retry.Header().Get(ReplayedHeader))
+	}
+	if retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("retry Content-Type = %q", retry.Header().Get("Content-Type"))
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	if rec := send("k1", `{"title":"b"}`); rec.Code != http.StatusConflict {
+		t.Errorf("reused key with a different body: status %d, want 409", rec.Code)
+	}
+
+	// A key stays claimed while its first request runs
+	inProgress := repo.IdempotencyKey{Fingerprint: fingerprint(httptest.NewRequest(http.MethodPost, "/projects/p1/tasks", nil), []byte("{}")), ExpiresAt: time.Now().Add(time.Hour)}
+	leased := time.Now().Add(time.Minute)
+	inProgress.LockedUntil = &leased
+	store.keys[keyID{user, "k2"}] = inProgress
+	if rec := send("k2", "{}"); rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("in-progress key: status %d, Retry-After %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+
+	// A claim whose lease ran out, as when its server stopped, is taken over
+	lapsed := time.Now().Add(-time.Second)
+	inProgress.LockedUntil = &lapsed
+	store.keys[keyID{user, "k2"}] = inProgress
+	calls = 0
+	if rec := send("k2", "{}"); rec.Code != http.StatusCreated || calls != 1 {
+		t.Errorf("key with a lapsed lease: status %d, handler ran %d times", rec.Code, calls)
+	}
+
+	// Server errors release the key so the request can be retried
+	fail = true
+	if rec := send("k3", "{}"); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("failing request: status %d", rec.Code)
+	}
+	fail = false
+	if rec := send("k3", "{}"); rec.Code != http.StatusCreated || rec.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("retry after failure: status %d, replayed %q", rec.Code, rec.Header().Get(ReplayedHeader))
+	}
+
+	calls = 0
+	send("", "{}")
+	send("", "{}")
+	if calls != 2 {
+		t.Errorf("requests without a key ran the handler %d times, want 2", calls)
+	}
+}
+
+// TestMiddlewareLeaseTakeover checks that a request whose lease ran out and
+// whose key a retry took over neither stores its response over the retry's
+// claim nor releases it
+func TestMiddlewareLeaseTakeover(t *testing.T) {
+	for _, status := range []int{http.StatusCreated, http.StatusInternalServerError} {
+		store := &fakeStore{keys: map[keyID]repo.IdempotencyKey{}}
+		user := uuid.New()
+		id := keyID{user, "k1"}
+		retryToken := uuid.New()
+
+		handler := Middleware(store, time.Hour, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The lease runs out while the request is still running, and a
+			// retry takes the key over
+			k := store.keys[id]
+			lapsed := time.Now().Add(-time.Second)
+			k.LockedUntil = &lapsed
+			store.keys[id] = k
+			leased := time.Now().Add(time.Minute)
+			claimed, _ := store.ClaimIdempotencyKey(r.Context(), repo.ClaimIdempotencyKeyParams{
+				UserID:         user,
+				IdempotencyKey: "k1",
+				Fingerprint:    k.Fingerprint,
+				ExpiresAt:      k.ExpiresAt,
+				LockedUntil:    &leased,
+				ClaimToken:     retryToken,
+			})
+			if claimed != 1 {
+				t.Fatalf("retry could not take over a lapsed claim")
+			}
+			w.WriteHeader(status)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/projects/p1/tasks", strings.NewReader("{}"))
+		req.Header.Set(Header, "k1")
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, user.String()))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		k, ok := store.keys[id]
+		if !ok {
+			t.Errorf("status %d: the first request released the retry's claim", status)
+			continue
+		}
+		if k.ClaimToken != retryToken || k.Status != nil {
+			t.Errorf("status %d: key = %+v, want the retry's claim still in progress", status, k)
+		}
+	}
+}
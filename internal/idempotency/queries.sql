@@ -0,0 +1,36 @@
+-- name: ClaimIdempotencyKey :execrows
+-- Claims the key for a new request, taking over an expired key or a claim
+-- whose request stopped holding it. Affects no row when the key is in use.
+INSERT INTO idempotency_keys (user_id, idempotency_key, fingerprint, expires_at, locked_until, claim_token)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    status = NULL,
+    content_type = '',
+    body = NULL,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at,
+    locked_until = EXCLUDED.locked_until,
+    claim_token = EXCLUDED.claim_token
+WHERE idempotency_keys.expires_at <= now()
+   OR (idempotency_keys.status IS NULL AND idempotency_keys.locked_until <= now());
+
+-- name: GetIdempotencyKey :one
+SELECT user_id, idempotency_key, fingerprint, status, content_type, body, created_at, expires_at, locked_until, claim_token
+FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+-- Stores the response, unless another request has taken the claim over
+UPDATE idempotency_keys
+SET status = $3, content_type = $4, body = $5, locked_until = NULL
+WHERE user_id = $1 AND idempotency_key = $2 AND claim_token = $6;
+
+-- name: ReleaseIdempotencyKey :exec
+-- Frees a claim whose request failed, so a retry runs it again, unless
+-- another request has taken it over
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2 AND status IS NULL AND claim_token = $3;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= now();
@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CaptureMailer records messages in memory instead of delivering them.
+// When dir is set, each message is also written to disk as an .eml file.
+// It is intended for local development and tests.
+type CaptureMailer struct {
+	dir  string
+	from string
+
+	mu   sync.Mutex
+	sent []Message
+}
+
+// NewCaptureMailer creates a capturing mailer, optionally writing to dir
+func NewCaptureMailer(dir string) *CaptureMailer {
+	return &CaptureMailer{dir: dir, from: "capture@localhost"}
+}
+
+// Send records the message
+func (m *CaptureMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.sent = append(m.sent, msg)
+	m.mu.Unlock()
+
+	if m.dir == "" {
+		return nil
+	}
+
+	raw, err := buildMIME(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("mail: failed to create capture directory: %w", err)
+	}
+	name := fmt.Sprintf("%d.eml", time.Now().UnixNano())
+	if err := os.WriteFile(filepath.Join(m.dir, name), raw, 0o644); err != nil {
+		return fmt.Errorf("mail: failed to write captured message: %w", err)
+	}
+	return nil
+}
+
+// Sent returns a copy of every captured message
+func (m *CaptureMailer) Sent() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Message, len(m.sent))
+	copy(out, m.sent)
+	return out
+}
+
+// Last returns the most recently captured message
+func (m *CaptureMailer) Last() (Message, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.sent) == 0 {
+		return Message{}, false
+	}
+	return m.sent[len(m.sent)-1], true
+}
+
+// Reset discards all captured messages
+func (m *CaptureMailer) Reset() {
+	m.mu.Lock()
+	m.sent = nil
+	m.mu.Unlock()
+}
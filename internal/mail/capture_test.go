@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCaptureMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewCaptureMailer(dir)
+	ctx := context.Background()
+
+	if _, ok := m.Last(); ok {
+		t.Fatal("Last() on a new mailer reported a message")
+	}
+
+	first := Message{To: []string{"a@example.com"}, Subject: "First", Text: "one", HTML: "<p>one</p>"}
+	second := Message{To: []string{"b@example.com"}, Subject: "Second", Text: "two"}
+	for _, msg := range []Message{first, second} {
+		if err := m.Send(ctx, msg); err != nil {
+			t.Fatalf("Send(%s) error = %v", msg.Subject, err)
+		}
+	}
+	if err := m.Send(ctx, Message{Subject: "No recipient", Text: "three"}); !errors.Is(err, ErrInvalidMessage) {
+		t.Errorf("Send() without a recipient = %v, want ErrInvalidMessage", err)
+	}
+
+	if sent := m.Sent(); len(sent) != 2 || sent[0].Subject != "First" || sent[1].Subject != "Second" {
+		t.Errorf("Sent() = %+v, want the two valid messages in order", sent)
+	}
+	if last, ok := m.Last(); !ok || last.Subject != "Second" {
+		t.Errorf("Last() = %+v, %v, want the second message", last, ok)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("capture directory has %d .eml files (%v), want 2", len(files), err)
+	}
+	var found bool
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		eml := string(raw)
+		if strings.Contains(eml, "To: a@example.com\r\n") {
+			found = true
+			for _, want := range []string{"Subject: First\r\n", "Content-Type: text/plain", "Content-Type: text/html", "<p>one</p>"} {
+				if !strings.Contains(eml, want) {
+					t.Errorf("captured message is missing %q:\n%s", want, eml)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Error("no captured file is addressed to a@example.com")
+	}
+
+	m.Reset()
+	if sent := m.Sent(); len(sent) != 0 {
+		t.Errorf("Sent() after Reset() = %d messages, want none", len(sent))
+	}
+}
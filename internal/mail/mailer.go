@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"devhive-backend/internal/config"
+)
+
+// ErrInvalidMessage is returned when a message is missing a recipient, subject or body
+var ErrInvalidMessage = errors.New("mail: message requires a recipient, a subject and a body")
+
+// Message is a single outbound email
+type Message struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer delivers outbound email
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// ErrNoProvider is returned by New when no provider is configured. Capturing
+// mail must be asked for, so a deploy missing its API key fails at startup
+// instead of dropping every message.
+var ErrNoProvider = errors.New("mail: no provider configured; set RESEND_API_KEY, or MAIL_PROVIDER to smtp or capture")
+
+// New returns the Mailer selected by cfg.Provider ("resend", "smtp", "capture").
+// When no provider is set, Resend is used if an API key is configured.
+func New(cfg config.MailConfig) (Mailer, error) {
+	provider := strings.ToLower(cfg.Provider)
+	if provider == "" {
+		if cfg.APIKey == "" {
+			return nil, ErrNoProvider
+		}
+		provider = "resend"
+	}
+
+	switch provider {
+	case "resend":
+		if cfg.APIKey == "" {
+			return nil, errors.New("mail: the resend provider requires RESEND_API_KEY")
+		}
+		return NewResendMailer(cfg.APIKey, cfg.From()), nil
+	case "smtp":
+		if cfg.SMTP.Host == "" {
+			return nil, errors.New("mail: the smtp provider requires SMTP_HOST")
+		}
+		return NewSMTPMailer(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.Username, cfg.SMTP.Password, cfg.From()), nil
+	case "capture":
+		return NewCaptureMailer(cfg.CaptureDir), nil
+	default:
+		return nil, fmt.Errorf("mail: unknown provider %q", cfg.Provider)
+	}
+}
+
+// validate checks that a message can be delivered
+func (m Message) validate() error {
+	if len(m.To) == 0 || m.Subject == "" || (m.Text == "" && m.HTML == "") {
+		return ErrInvalidMessage
+	}
+	for _, to := range m.To {
+		if strings.TrimSpace(to) == "" || strings.ContainsAny(to, "\r\n") {
+			return ErrInvalidMessage
+		}
+	}
+	if strings.ContainsAny(m.Subject, "\r\n") {
+		return ErrInvalidMessage
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package mail
+
+import (
+	"errors"
+	"testing"
+
+	"devhive-backend/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	smtp := config.SMTPConfig{Host: "smtp.example.com", Port: 587}
+
+	tests := []struct {
+		name    string
+		cfg     config.MailConfig
+		want    string // Mailer type, empty when New must fail
+		wantErr error
+	}{
+		{"resend by API key", config.MailConfig{APIKey: "re_key"}, "resend", nil},
+		{"resend", config.MailConfig{Provider: "Resend", APIKey: "re_key"}, "resend", nil},
+		{"smtp", config.MailConfig{Provider: "smtp", SMTP: smtp}, "smtp", nil},
+		{"capture", config.MailConfig{Provider: "capture"}, "capture", nil},
+		{"nothing configured", config.MailConfig{}, "", ErrNoProvider},
+		{"resend without key", config.MailConfig{Provider: "resend"}, "", nil},
+		{"smtp without host", config.MailConfig{Provider: "smtp"}, "", nil},
+		{"unknown", config.MailConfig{Provider: "sendmail"}, "", nil},
+	}
+	for _, tt := range tests {
+		mailer, err := New(tt.cfg)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: New() = %T, want an error", tt.name, mailer)
+			} else if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: New() error = %v, want %v", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: New() error = %v", tt.name, err)
+			continue
+		}
+		var got string
+		switch mailer.(type) {
+		case *ResendMailer:
+			got = "resend"
+		case *SMTPMailer:
+			got = "smtp"
+		case *CaptureMailer:
+			got = "capture"
+		}
+		if got != tt.want {
+			t.Errorf("%s: New() = %T, want %s", tt.name, mailer, tt.want)
+		}
+	}
+}
+
+func TestMessageValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  Message
+		ok   bool
+	}{
+		{"text", Message{To: []string{"a@example.com"}, Subject: "Hi", Text: "Hello"}, true},
+		{"html", Message{To: []string{"a@example.com"}, Subject: "Hi", HTML: "<p>Hello</p>"}, true},
+		{"no recipient", Message{Subject: "Hi", Text: "Hello"}, false},
+		{"blank recipient", Message{To: []string{" "}, Subject: "Hi", Text: "Hello"}, false},
+		{"no subject", Message{To: []string{"a@example.com"}, Text: "Hello"}, false},
+		{"no body", Message{To: []string{"a@example.com"}, Subject: "Hi"}, false},
+		{"header in recipient", Message{To: []string{"a@example.com\r\nBcc: b@example.com"}, Subject: "Hi", Text: "Hello"}, false},
+		{"header in subject", Message{To: []string{"a@example.com"}, Subject: "Hi\nBcc: b@example.com", Text: "Hello"}, false},
+	}
+	for _, tt := range tests {
+		err := tt.msg.validate()
+		if tt.ok && err != nil {
+			t.Errorf("%s: validate() = %v, want nil", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidMessage) {
+			t.Errorf("%s: validate() = %v, want ErrInvalidMessage", tt.name, err)
+		}
+	}
+}
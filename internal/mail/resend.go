@@ -0,0 +1,79 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const resendEndpoint = "https://api.resend.com/emails"
+
+// ResendMailer sends email through the Resend HTTP API
+type ResendMailer struct {
+	apiKey   string
+	from     string
+	endpoint string
+	client   *http.Client
+}
+
+// NewResendMailer creates a Resend-backed mailer
+func NewResendMailer(apiKey, from string) *ResendMailer {
+	return &ResendMailer{
+		apiKey:   apiKey,
+		from:     from,
+		endpoint: resendEndpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+type resendRequest struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	HTML    string   `json:"html,omitempty"`
+	Text    string   `json:"text,omitempty"`
+}
+
+// Send delivers the message via Resend
+func (m *ResendMailer) Send(ctx context.Context, msg Message) error {
+	if err := msg.validate(); err != nil {
+		return err
+	}
+	if m.apiKey == "" {
+		return fmt.Errorf("mail: resend API key is not configured")
+	}
+
+	body, err := json.Marshal(resendRequest{
+		From:    m.from,
+		To:      msg.To,
+		Subject: msg.Subject,
+		HTML:    msg.HTML,
+		Text:    msg.Text,
+	})
+	if err != nil {
+		return fmt.Errorf("mail: failed to encode resend request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("mail: failed to create resend request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("mail: resend request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("mail: resend returned %d: %s", resp.StatusCode, bytes.TrimSpace(detail))
+	}
+	return nil
+}
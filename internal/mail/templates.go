@@ -0,0 +1,75 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.html templates/*.txt
+var templateFS embed.FS
+
+// Template names available to Render
+const (
+	TemplatePasswordReset     = "password_reset"
+	TemplateProjectInvite     = "project_invite"
+	TemplateEmailVerification = "email_verification"
+	TemplateGeneric           = "generic"
+)
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+// PasswordResetData is the template data for TemplatePasswordReset
+type PasswordResetData struct {
+	Username  string
+	ResetURL  string
+	Token     string
+	ExpiresIn string
+}
+
+// ProjectInviteData is the template data for TemplateProjectInvite
+type ProjectInviteData struct {
+	ProjectName string
+	InviterName string
+	InviteURL   string
+	ExpiresAt   string
+}
+
+// EmailVerificationData is the template data for TemplateEmailVerification
+type EmailVerificationData struct {
+	Username  string
+	VerifyURL string
+	ExpiresIn string
+}
+
+// GenericData is the template data for TemplateGeneric
+type GenericData struct {
+	Subject string
+	Body    string
+}
+
+// Render builds a message from the named template pair. The HTML part is
+// rendered from templates/<name>.html and the plain-text part from
+// templates/<name>.txt.
+func Render(name string, to []string, subject string, data interface{}) (Message, error) {
+	var htmlBuf, textBuf bytes.Buffer
+
+	if err := htmlTemplates.ExecuteTemplate(&htmlBuf, name+".html", data); err != nil {
+		return Message{}, fmt.Errorf("mail: failed to render %s html: %w", name, err)
+	}
+	if err := textTemplates.ExecuteTemplate(&textBuf, name+".txt", data); err != nil {
+		return Message{}, fmt.Errorf("mail: failed to render %s text: %w", name, err)
+	}
+
+	return Message{
+		To:      to,
+		Subject: subject,
+		HTML:    htmlBuf.String(),
+		Text:    textBuf.String(),
+	}, nil
+}
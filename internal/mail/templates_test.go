@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		contains []string // Expected in both parts
+	}{
+		{TemplatePasswordReset, PasswordResetData{Username: "ada", ResetURL: "https://devhive.it.com/reset-password?token=t1", ExpiresIn: "1 hour"}, []string{"ada", "https://devhive.it.com/reset-password?token=t1", "1 hour"}},
+		{TemplatePasswordReset, PasswordResetData{Token: "123456"}, []string{"123456"}},
+		{TemplateProjectInvite, ProjectInviteData{ProjectName: "Apollo", InviterName: "Grace", InviteURL: "https://devhive.it.com/invite/abc"}, []string{"Apollo", "Grace", "https://devhive.it.com/invite/abc"}},
+		{TemplateEmailVerification, EmailVerificationData{Username: "ada", VerifyURL: "https://devhive.it.com/verify-email?token=t2", ExpiresIn: "24 hours"}, []string{"ada", "https://devhive.it.com/verify-email?token=t2", "24 hours"}},
+		{TemplateGeneric, GenericData{Subject: "Release", Body: "Version 2 is out"}, []string{"Version 2 is out"}},
+	}
+	for _, tt := range tests {
+		msg, err := Render(tt.name, []string{"a@example.com"}, "Subject", tt.data)
+		if err != nil {
+			t.Errorf("Render(%s) error = %v", tt.name, err)
+			continue
+		}
+		if msg.Subject != "Subject" || len(msg.To) != 1 || msg.To[0] != "a@example.com" {
+			t.Errorf("Render(%s) headers = %q %q", tt.name, msg.To, msg.Subject)
+		}
+		for _, want := range tt.contains {
+			if !strings.Contains(msg.HTML, want) {
+				t.Errorf("Render(%s) HTML is missing %q", tt.name, want)
+			}
+			if !strings.Contains(msg.Text, want) {
+				t.Errorf("Render(%s) text is missing %q", tt.name, want)
+			}
+		}
+	}
+}
+
+func TestRenderEscapesHTML(t *testing.T) {
+	msg, err := Render(TemplateGeneric, []string{"a@example.com"}, "Hi", GenericData{
+		Subject: "Hi",
+		Body:    `<a href="https://evil.example">Log in</a>`,
+	})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if strings.Contains(msg.HTML, "<a href") {
+		t.Errorf("HTML part contains unescaped markup: %s", msg.HTML)
+	}
+	if !strings.Contains(msg.Text, `<a href="https://evil.example">`) {
+		t.Errorf("text part = %q, want the body as written", msg.Text)
+	}
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	if _, err := Render("missing", []string{"a@example.com"}, "Hi", nil); err == nil {
+		t.Error("Render(missing) succeeded, want an error")
+	}
+}
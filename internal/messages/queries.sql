@@ -10,17 +10,21 @@ SELECT m.id, m.project_id, m.sender_id, m.content, m.message_type, m.parent_mess
        u.username as sender_username, u.first_name as sender_first_name, u.last_name as sender_last_name, u.avatar_url as sender_avatar_url
 FROM messages m
 JOIN users u ON m.sender_id = u.id
-WHERE m.project_id = $1
-ORDER BY m.created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE m.project_id = sqlc.arg(project_id)
+  AND (sqlc.narg(cursor_id)::uuid IS NULL
+       OR (m.created_at, m.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY m.created_at DESC, m.id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: ListMessagesByProjectAfter :many
 SELECT m.id, m.project_id, m.sender_id, m.content, m.message_type, m.parent_message_id, m.created_at, m.updated_at,
        u.username as sender_username, u.first_name as sender_first_name, u.last_name as sender_last_name, u.avatar_url as sender_avatar_url
 FROM messages m
 JOIN users u ON m.sender_id = u.id
-WHERE m.project_id = $1 AND m.id > $2
-ORDER BY m.created_at ASC
+-- Messages are keyed on (created_at, id); UUIDs alone are not ordered
+WHERE m.project_id = $1
+  AND (m.created_at, m.id) > (SELECT a.created_at, a.id FROM messages a WHERE a.id = $2)
+ORDER BY m.created_at ASC, m.id ASC
 LIMIT $3;
 
 -- name: CreateMessage :one
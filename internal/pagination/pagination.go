@@ -0,0 +1,92 @@
+// Package pagination holds the keyset cursors and page bounds shared by the
+// REST and gRPC listings.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Page size bounds shared by the listings
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned when a cursor was not issued by this API
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of the last row of a page. Rows are ordered
+// by (created_at, id); Key carries an extra leading sort value for listings
+// ordered by something else, such as a sprint's start date.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"i"`
+	Key       string    `json:"k,omitempty"`
+}
+
+// Encode returns the opaque form handed to clients
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode
+func DecodeCursor(s string) (Cursor, error) {
+	var c Cursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || json.Unmarshal(b, &c) != nil || c.ID == uuid.Nil || c.CreatedAt.IsZero() {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Page is a parsed page request
+type Page struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// FetchLimit is the row count to query: one extra row reveals whether a
+// next page exists
+func (p Page) FetchLimit() int32 {
+	return int32(p.Limit + 1)
+}
+
+// Keyset returns the cursor position as nullable query arguments; both are
+// NULL on the first page
+func (p Page) Keyset() (pgtype.UUID, pgtype.Timestamptz) {
+	if p.Cursor == nil {
+		return pgtype.UUID{}, pgtype.Timestamptz{}
+	}
+	return pgtype.UUID{Bytes: p.Cursor.ID, Valid: true},
+		pgtype.Timestamptz{Time: p.Cursor.CreatedAt, Valid: true}
+}
+
+// TimeKey formats a timestamp for Cursor.Key
+func TimeKey(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// ParseTimeKey parses a Cursor.Key written by TimeKey
+func ParseTimeKey(key string) (pgtype.Timestamptz, error) {
+	t, err := time.Parse(time.RFC3339Nano, key)
+	if err != nil {
+		return pgtype.Timestamptz{}, ErrInvalidCursor
+	}
+	return pgtype.Timestamptz{Time: t, Valid: true}, nil
+}
+
+// Trim drops the extra row fetched by FetchLimit and reports whether there
+// is a next page
+func Trim[T any](rows []T, page Page) ([]T, bool) {
+	if len(rows) > page.Limit {
+		return rows[:page.Limit], true
+	}
+	return rows, false
+}
@@ -0,0 +1,92 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNotMember is returned when the user does not belong to the project
+var ErrNotMember = errors.New("permission: user is not a project member")
+
+// ErrForbidden is returned when the user's role does not grant the action
+var ErrForbidden = errors.New("permission: action not permitted")
+
+// DeniedError describes a denied action; it wraps ErrForbidden
+type DeniedError struct {
+	Role   Role
+	Action Action
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("permission: role %s may not %s", e.Role, e.Action)
+}
+
+func (e *DeniedError) Unwrap() error {
+	return ErrForbidden
+}
+
+// RoleLookup loads a user's stored role in a project; *repo.Queries implements it
+type RoleLookup interface {
+	GetProjectMemberRole(ctx context.Context, arg repo.GetProjectMemberRoleParams) (string, error)
+}
+
+type contextKey struct{}
+
+type resolvedRole struct {
+	projectID uuid.UUID
+	userID    uuid.UUID
+	role      Role
+}
+
+// WithRole records a resolved role in the context so later checks for the
+// same project and user in the request skip the database
+func WithRole(ctx context.Context, projectID, userID uuid.UUID, role Role) context.Context {
+	return context.WithValue(ctx, contextKey{}, resolvedRole{projectID: projectID, userID: userID, role: role})
+}
+
+// RoleFromContext returns the role recorded by WithRole for the project and user
+func RoleFromContext(ctx context.Context, projectID, userID uuid.UUID) (Role, bool) {
+	resolved, ok := ctx.Value(contextKey{}).(resolvedRole)
+	if !ok || resolved.projectID != projectID || resolved.userID != userID {
+		return "", false
+	}
+	return resolved.role, true
+}
+
+// ProjectRole returns the user's role in the project, or ErrNotMember
+func ProjectRole(ctx context.Context, lookup RoleLookup, projectID, userID uuid.UUID) (Role, error) {
+	if role, ok := RoleFromContext(ctx, projectID, userID); ok {
+		return role, nil
+	}
+
+	stored, err := lookup.GetProjectMemberRole(ctx, repo.GetProjectMemberRoleParams{
+		ProjectID: projectID,
+		UserID:    userID,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotMember
+	}
+	if err != nil {
+		return "", fmt.Errorf("permission: failed to load role: %w", err)
+	}
+	return ParseRole(stored)
+}
+
+// Authorize returns the user's role if it grants action in the project.
+// It fails with ErrNotMember, a *DeniedError, or a lookup error.
+func Authorize(ctx context.Context, lookup RoleLookup, projectID, userID uuid.UUID, action Action) (Role, error) {
+	role, err := ProjectRole(ctx, lookup, projectID, userID)
+	if err != nil {
+		return "", err
+	}
+	if !Can(role, action) {
+		return role, &DeniedError{Role: role, Action: action}
+	}
+	return role, nil
+}
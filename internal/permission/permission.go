@@ -0,0 +1,151 @@
+// Package permission maps project roles to the actions they may perform.
+//
+// Every project member has exactly one role (owner, admin, member or viewer)
+// stored in project_members. Handlers never compare roles directly; they ask
+// whether the caller's role grants a named action such as task.create or
+// member.manage.
+package permission
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Role is a project member's role
+type Role string
+
+const (
+	RoleOwner  Role = "owner"
+	RoleAdmin  Role = "admin"
+	RoleMember Role = "member"
+	RoleViewer Role = "viewer"
+)
+
+// Roles lists every role from most to least privileged
+var Roles = []Role{RoleOwner, RoleAdmin, RoleMember, RoleViewer}
+
+// Action is something a user can do within a project
+type Action string
+
+const (
+	ProjectView     Action = "project.view"
+	ProjectUpdate   Action = "project.update"
+	ProjectDelete   Action = "project.delete"
+	ProjectTransfer Action = "project.transfer"
+
+	MemberView   Action = "member.view"
+	MemberManage Action = "member.manage"
+
+	InviteView   Action = "invite.view"
+	InviteCreate Action = "invite.create"
+	InviteRevoke Action = "invite.revoke"
+
+	SprintView   Action = "sprint.view"
+	SprintCreate Action = "sprint.create"
+	SprintUpdate Action = "sprint.update"
+	SprintDelete Action = "sprint.delete"
+
+	TaskView   Action = "task.view"
+	TaskCreate Action = "task.create"
+	TaskUpdate Action = "task.update"
+	TaskDelete Action = "task.delete"
+
+	MessageView   Action = "message.view"
+	MessageCreate Action = "message.create"
+)
+
+// grants lists the actions each role may perform
+var grants = map[Role][]Action{
+	RoleOwner: {
+		ProjectView, ProjectUpdate, ProjectDelete, ProjectTransfer,
+		MemberView, MemberManage,
+		InviteView, InviteCreate, InviteRevoke,
+		SprintView, SprintCreate, SprintUpdate, SprintDelete,
+		TaskView, TaskCreate, TaskUpdate, TaskDelete,
+		MessageView, MessageCreate,
+	},
+	RoleAdmin: {
+		ProjectView, ProjectUpdate,
+		MemberView, MemberManage,
+		InviteView, InviteCreate, InviteRevoke,
+		SprintView, SprintCreate, SprintUpdate, SprintDelete,
+		TaskView, TaskCreate, TaskUpdate, TaskDelete,
+		MessageView, MessageCreate,
+	},
+	RoleMember: {
+		ProjectView,
+		MemberView,
+		InviteView,
+		SprintView, SprintCreate, SprintUpdate,
+		TaskView, TaskCreate, TaskUpdate, TaskDelete,
+		MessageView, MessageCreate,
+	},
+	RoleViewer: {
+		ProjectView,
+		MemberView,
+		InviteView,
+		SprintView,
+		TaskView,
+		MessageView,
+	},
+}
+
+// matrix is grants indexed for lookup
+var matrix = func() map[Role]map[Action]bool {
+	m := make(map[Role]map[Action]bool, len(grants))
+	for role, actions := range grants {
+		m[role] = make(map[Action]bool, len(actions))
+		for _, action := range actions {
+			m[role][action] = true
+		}
+	}
+	return m
+}()
+
+// Can reports whether role may perform action
+func Can(role Role, action Action) bool {
+	return matrix[role][action]
+}
+
+// Actions returns the actions granted to role, sorted by name
+func Actions(role Role) []Action {
+	actions := append([]Action(nil), grants[role]...)
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	return actions
+}
+
+// ParseRole converts a stored role name into a Role
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := matrix[role]; !ok {
+		return "", fmt.Errorf("permission: unknown role %q", s)
+	}
+	return role, nil
+}
+
+// rank orders roles by privilege; higher is more privileged
+func (r Role) rank() int {
+	switch r {
+	case RoleOwner:
+		return 4
+	case RoleAdmin:
+		return 3
+	case RoleMember:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// CanAssignRole reports whether actor may give another member the target
+// role, or change the role of a member who currently holds target. Owners
+// can manage every non-owner role; admins can manage members and viewers.
+// Ownership itself is only ever moved by a transfer.
+func CanAssignRole(actor, target Role) bool {
+	if !Can(actor, MemberManage) || target == RoleOwner || target.rank() == 0 {
+		return false
+	}
+	return actor == RoleOwner || actor.rank() > target.rank()
+}
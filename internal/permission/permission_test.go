@@ -0,0 +1,202 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestCanMatrix(t *testing.T) {
+	const (
+		o = RoleOwner
+		a = RoleAdmin
+		m = RoleMember
+		v = RoleViewer
+	)
+
+	tests := []struct {
+		action  Action
+		allowed []Role
+	}{
+		{ProjectView, []Role{o, a, m, v}},
+		{ProjectUpdate, []Role{o, a}},
+		{ProjectDelete, []Role{o}},
+		{ProjectTransfer, []Role{o}},
+		{MemberView, []Role{o, a, m, v}},
+		{MemberManage, []Role{o, a}},
+		{InviteView, []Role{o, a, m, v}},
+		{InviteCreate, []Role{o, a}},
+		{InviteRevoke, []Role{o, a}},
+		{SprintView, []Role{o, a, m, v}},
+		{SprintCreate, []Role{o, a, m}},
+		{SprintUpdate, []Role{o, a, m}},
+		{SprintDelete, []Role{o, a}},
+		{TaskView, []Role{o, a, m, v}},
+		{TaskCreate, []Role{o, a, m}},
+		{TaskUpdate, []Role{o, a, m}},
+		{TaskDelete, []Role{o, a, m}},
+		{MessageView, []Role{o, a, m, v}},
+		{MessageCreate, []Role{o, a, m}},
+	}
+
+	covered := map[Action]bool{}
+	for _, tt := range tests {
+		covered[tt.action] = true
+		allowed := map[Role]bool{}
+		for _, role := range tt.allowed {
+			allowed[role] = true
+		}
+		for _, role := range Roles {
+			t.Run(string(tt.action)+"/"+string(role), func(t *testing.T) {
+				if got := Can(role, tt.action); got != allowed[role] {
+					t.Errorf("Can(%s, %s) = %v, want %v", role, tt.action, got, allowed[role])
+				}
+			})
+		}
+	}
+
+	// Every granted action must appear in the table above
+	for _, role := range Roles {
+		for _, action := range Actions(role) {
+			if !covered[action] {
+				t.Errorf("action %s granted to %s is missing from the test matrix", action, role)
+			}
+		}
+	}
+}
+
+func TestCanUnknownRoleOrAction(t *testing.T) {
+	if Can(Role("superuser"), ProjectView) {
+		t.Error("unknown role should not be granted anything")
+	}
+	if Can(RoleOwner, Action("project.explode")) {
+		t.Error("unknown action should not be granted")
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Role
+		wantErr bool
+	}{
+		{"owner", RoleOwner, false},
+		{"admin", RoleAdmin, false},
+		{"member", RoleMember, false},
+		{"viewer", RoleViewer, false},
+		{"", "", true},
+		{"Owner", "", true},
+		{"guest", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRole(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRole(%q) = %q, %v; want %q, err=%v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCanAssignRole(t *testing.T) {
+	tests := []struct {
+		actor  Role
+		target Role
+		want   bool
+	}{
+		{RoleOwner, RoleOwner, false},
+		{RoleOwner, RoleAdmin, true},
+		{RoleOwner, RoleMember, true},
+		{RoleOwner, RoleViewer, true},
+		{RoleAdmin, RoleOwner, false},
+		{RoleAdmin, RoleAdmin, false},
+		{RoleAdmin, RoleMember, true},
+		{RoleAdmin, RoleViewer, true},
+		{RoleMember, RoleMember, false},
+		{RoleMember, RoleViewer, false},
+		{RoleViewer, RoleViewer, false},
+		{RoleOwner, Role("guest"), false},
+	}
+	for _, tt := range tests {
+		if got := CanAssignRole(tt.actor, tt.target); got != tt.want {
+			t.Errorf("CanAssignRole(%s, %s) = %v, want %v", tt.actor, tt.target, got, tt.want)
+		}
+	}
+}
+
+type memberKey struct {
+	projectID uuid.UUID
+	userID    uuid.UUID
+}
+
+type fakeLookup struct {
+	roles map[memberKey]string
+	err   error
+	calls int
+}
+
+func (f *fakeLookup) GetProjectMemberRole(ctx context.Context, arg repo.GetProjectMemberRoleParams) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	role, ok := f.roles[memberKey{arg.ProjectID, arg.UserID}]
+	if !ok {
+		return "", pgx.ErrNoRows
+	}
+	return role, nil
+}
+
+func TestAuthorize(t *testing.T) {
+	projectID := uuid.New()
+	owner, viewer, outsider := uuid.New(), uuid.New(), uuid.New()
+	lookup := &fakeLookup{roles: map[memberKey]string{
+		{projectID, owner}:  "owner",
+		{projectID, viewer}: "viewer",
+	}}
+
+	tests := []struct {
+		name    string
+		userID  uuid.UUID
+		action  Action
+		wantErr error
+	}{
+		{"owner deletes project", owner, ProjectDelete, nil},
+		{"viewer reads tasks", viewer, TaskView, nil},
+		{"viewer creates task", viewer, TaskCreate, ErrForbidden},
+		{"outsider reads project", outsider, ProjectView, ErrNotMember},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Authorize(context.Background(), lookup, projectID, tt.userID, tt.action)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authorize() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("lookup failure", func(t *testing.T) {
+		failing := &fakeLookup{err: errors.New("connection refused")}
+		_, err := Authorize(context.Background(), failing, projectID, owner, ProjectView)
+		if err == nil || errors.Is(err, ErrNotMember) || errors.Is(err, ErrForbidden) {
+			t.Errorf("Authorize() error = %v, want lookup error", err)
+		}
+	})
+
+	t.Run("role from context", func(t *testing.T) {
+		counting := &fakeLookup{roles: map[memberKey]string{{projectID, owner}: "owner"}}
+		ctx := WithRole(context.Background(), projectID, owner, RoleOwner)
+		if _, err := Authorize(ctx, counting, projectID, owner, ProjectDelete); err != nil {
+			t.Fatalf("Authorize() error = %v", err)
+		}
+		if counting.calls != 0 {
+			t.Errorf("lookup called %d times, want 0", counting.calls)
+		}
+		if _, err := Authorize(ctx, counting, uuid.New(), owner, ProjectView); !errors.Is(err, ErrNotMember) {
+			t.Errorf("Authorize() for other project error = %v, want ErrNotMember", err)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package planning
+
+import (
+	"context"
+	"errors"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidLabels is returned when a label ID is malformed or belongs to another project
+var ErrInvalidLabels = errors.New("planning: labels must be IDs of labels in the task's project")
+
+// ParseLabelIDs parses and de-duplicates label IDs and checks that they all
+// belong to the project
+func ParseLabelIDs(ctx context.Context, q *repo.Queries, projectID uuid.UUID, ids []string) ([]uuid.UUID, error) {
+	labelIDs := make([]uuid.UUID, 0, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		labelID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, ErrInvalidLabels
+		}
+		if !seen[labelID] {
+			seen[labelID] = true
+			labelIDs = append(labelIDs, labelID)
+		}
+	}
+	if len(labelIDs) == 0 {
+		return labelIDs, nil
+	}
+
+	count, err := q.CountProjectLabels(ctx, repo.CountProjectLabelsParams{
+		ProjectID: projectID,
+		LabelIds:  labelIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count != int64(len(labelIDs)) {
+		return nil, ErrInvalidLabels
+	}
+	return labelIDs, nil
+}
+
+// SetTaskLabels replaces a task's labels and bumps its updated_at so
+// realtime clients refresh it
+func SetTaskLabels(ctx context.Context, q *repo.Queries, taskID uuid.UUID, labelIDs []uuid.UUID) error {
+	if err := q.ClearTaskLabels(ctx, taskID); err != nil {
+		return err
+	}
+	if len(labelIDs) > 0 {
+		if err := q.AddTaskLabels(ctx, repo.AddTaskLabelsParams{
+			TaskID:   taskID,
+			LabelIds: labelIDs,
+		}); err != nil {
+			return err
+		}
+	}
+	return q.TouchTask(ctx, taskID)
+}
+
+// LabelsByTask loads the labels of many tasks with one query
+func LabelsByTask(ctx context.Context, q *repo.Queries, taskIDs []uuid.UUID) (map[uuid.UUID][]repo.ListLabelsForTasksRow, error) {
+	labels := make(map[uuid.UUID][]repo.ListLabelsForTasksRow, len(taskIDs))
+	if len(taskIDs) == 0 {
+		return labels, nil
+	}
+	rows, err := q.ListLabelsForTasks(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		labels[row.TaskID] = append(labels[row.TaskID], row)
+	}
+	return labels, nil
+}
@@ -0,0 +1,86 @@
+// Package planning converts the task planning fields (priority, story points
+// and due date) between their API and database representations.
+package planning
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Priority levels as stored in tasks.priority
+const (
+	PriorityNone int16 = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+// DateLayout is the wire format for due dates
+const DateLayout = "2006-01-02"
+
+// MaxStoryPoints caps estimates to catch typos such as 500 instead of 5
+const MaxStoryPoints = 1000
+
+// ErrInvalidPriority is returned for an unknown priority name
+var ErrInvalidPriority = errors.New("planning: priority must be one of none, low, medium, high, urgent")
+
+// ErrInvalidStoryPoints is returned for a negative or oversized estimate
+var ErrInvalidStoryPoints = fmt.Errorf("planning: story points must be between 0 and %d", MaxStoryPoints)
+
+// ErrInvalidDueDate is returned for a due date not in YYYY-MM-DD form
+var ErrInvalidDueDate = errors.New("planning: due date must be formatted YYYY-MM-DD")
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+// ParsePriority converts a priority name to its stored value
+func ParsePriority(name string) (int16, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for i, n := range priorityNames {
+		if n == name {
+			return int16(i), nil
+		}
+	}
+	return 0, ErrInvalidPriority
+}
+
+// PriorityName converts a stored priority to its name
+func PriorityName(p int16) string {
+	if p < 0 || int(p) >= len(priorityNames) {
+		return priorityNames[PriorityNone]
+	}
+	return priorityNames[p]
+}
+
+// ValidateStoryPoints checks an optional estimate
+func ValidateStoryPoints(points *int32) error {
+	if points != nil && (*points < 0 || *points > MaxStoryPoints) {
+		return ErrInvalidStoryPoints
+	}
+	return nil
+}
+
+// ParseDueDate converts an optional YYYY-MM-DD string; nil or "" clears the date
+func ParseDueDate(s *string) (pgtype.Date, error) {
+	if s == nil || *s == "" {
+		return pgtype.Date{}, nil
+	}
+	t, err := time.Parse(DateLayout, *s)
+	if err != nil {
+		return pgtype.Date{}, ErrInvalidDueDate
+	}
+	return pgtype.Date{Time: t, Valid: true}, nil
+}
+
+// FormatDueDate converts a stored due date to YYYY-MM-DD, or nil when unset
+func FormatDueDate(d pgtype.Date) *string {
+	if !d.Valid {
+		return nil
+	}
+	s := d.Time.Format(DateLayout)
+	return &s
+}
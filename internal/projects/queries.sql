@@ -1,14 +1,14 @@
 -- name: GetProjectByID :one
-SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at,
+SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at, p.version,
        u.id as owner_id, u.username as owner_username, u.email as owner_email,
        u.first_name as owner_first_name, u.last_name as owner_last_name
 FROM projects p
 JOIN users u ON p.owner_id = u.id
-WHERE p.id = $1;
+WHERE p.id = $1 AND p.deleted_at IS NULL;
 
 -- name: ListProjectsByUser :many
 -- Fixed: Use EXISTS to avoid duplicates from LEFT JOIN
-SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at,
+SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at, p.version,
        u.username AS owner_username,
        u.email AS owner_email,
        u.first_name AS owner_first_name,
@@ -16,37 +16,98 @@ SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at,
        u.avatar_url AS owner_avatar_url
 FROM projects p
 JOIN users u ON u.id = p.owner_id
-WHERE p.owner_id = $1
+WHERE (p.owner_id = sqlc.arg(user_id)
    OR EXISTS (
        SELECT 1
        FROM project_members pm
-       WHERE pm.project_id = p.id AND pm.user_id = $1
-   )
-ORDER BY p.created_at DESC
-LIMIT $2 OFFSET $3;
+       WHERE pm.project_id = p.id AND pm.user_id = sqlc.arg(user_id)
+   ))
+  AND p.deleted_at IS NULL
+  AND (sqlc.narg(cursor_id)::uuid IS NULL
+       OR (p.created_at, p.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: CreateProject :one
 INSERT INTO projects (owner_id, name, description)
 VALUES ($1, $2, $3)
-RETURNING id, owner_id, name, description, created_at, updated_at;
+RETURNING id, owner_id, name, description, created_at, updated_at, deleted_at, version;
 
 -- name: UpdateProject :one
+-- With expected_version set, no row is returned if the project changed since it was read
 UPDATE projects
 SET name = $2, description = $3, updated_at = now()
-WHERE id = $1
-RETURNING id, owner_id, name, description, created_at, updated_at;
+WHERE id = $1 AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING id, owner_id, name, description, created_at, updated_at, deleted_at, version;
 
--- name: DeleteProject :exec
-DELETE FROM projects WHERE id = $1;
+-- name: SoftDeleteProject :execrows
+-- Moves the project to the trash; its sprints, tasks and messages are kept
+-- and become reachable again when the project is restored
+UPDATE projects SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL;
 
--- name: AddProjectMember :exec
--- Idempotent: Uses ON CONFLICT to prevent duplicates
+-- name: RestoreProject :one
+-- Only the owner may restore a project, since deleted projects grant no roles
+UPDATE projects
+SET deleted_at = NULL, updated_at = now()
+WHERE id = sqlc.arg(id) AND owner_id = sqlc.arg(owner_id) AND deleted_at IS NOT NULL
+RETURNING id, owner_id, name, description, created_at, updated_at, deleted_at, version;
+
+-- name: ListDeletedProjectsByOwner :many
+SELECT id, owner_id, name, description, created_at, updated_at, deleted_at, version
+FROM projects
+WHERE owner_id = sqlc.arg(owner_id) AND deleted_at IS NOT NULL
+  AND (sqlc.narg(cursor_id)::uuid IS NULL
+       OR (deleted_at, id) < (sqlc.narg(cursor_deleted_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: AddProjectMember :execrows
+-- Idempotent: Uses ON CONFLICT to prevent duplicates. Never changes the owner's
+-- row, so a re-add racing an ownership transfer cannot demote the new owner;
+-- no row is affected then.
 INSERT INTO project_members (project_id, user_id, role)
 VALUES ($1, $2, $3)
-ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role;
-
--- name: RemoveProjectMember :exec
-DELETE FROM project_members WHERE project_id = $1 AND user_id = $2;
+ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+WHERE project_members.role <> 'owner';
+
+-- name: RemoveProjectMember :execrows
+-- Never removes the owner; no row is affected then
+DELETE FROM project_members WHERE project_id = $1 AND user_id = $2 AND role <> 'owner';
+
+-- name: UpdateProjectMemberRole :one
+-- Never touches the owner row and never grants ownership; use TransferProjectOwnership
+UPDATE project_members
+SET role = sqlc.arg(role)::text
+WHERE project_id = sqlc.arg(project_id)
+  AND user_id = sqlc.arg(user_id)
+  AND role <> 'owner'
+  AND sqlc.arg(role)::text <> 'owner'
+RETURNING project_id, user_id, role, joined_at;
+
+-- name: TransferProjectOwnership :one
+-- Single statement so owner_id and both member rows change together or not at all.
+-- Only succeeds while current_owner_id still owns the project and new_owner_id is a member.
+WITH transferred AS (
+    UPDATE projects
+    SET owner_id = sqlc.arg(new_owner_id), updated_at = now()
+    WHERE id = sqlc.arg(project_id)
+      AND owner_id = sqlc.arg(current_owner_id)
+      AND EXISTS (
+          SELECT 1 FROM project_members
+          WHERE project_id = sqlc.arg(project_id) AND user_id = sqlc.arg(new_owner_id)
+      )
+    RETURNING id, owner_id, name, description, created_at, updated_at, version
+), promoted AS (
+    UPDATE project_members
+    SET role = 'owner'
+    WHERE project_id IN (SELECT id FROM transferred) AND user_id = sqlc.arg(new_owner_id)
+), demoted AS (
+    INSERT INTO project_members (project_id, user_id, role)
+    SELECT id, sqlc.arg(current_owner_id), 'admin' FROM transferred
+    ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'admin'
+)
+SELECT id, owner_id, name, description, created_at, updated_at, version FROM transferred;
 
 -- name: GetProjectMembers :many
 -- Canonical model: project_members is single source of truth (includes owner)
@@ -66,11 +127,17 @@ JOIN users u ON pm.user_id = u.id
 WHERE pm.project_id = $1
 ORDER BY pm.joined_at;
 
+-- name: ListProjectMemberIDs :many
+-- Includes members of a project in the trash, who still hear about its deletion
+SELECT user_id FROM project_members
+WHERE project_id = $1;
+
 -- name: CheckProjectAccess :one
 -- Check if user is a project member (canonical model: project_members is single source of truth, includes owner)
 SELECT EXISTS(
-    SELECT 1 FROM project_members pm 
-    WHERE pm.project_id = $1 AND pm.user_id = $2
+    SELECT 1 FROM project_members pm
+    JOIN projects p ON p.id = pm.project_id
+    WHERE pm.project_id = $1 AND pm.user_id = $2 AND p.deleted_at IS NULL
 ) as has_access;
 
 -- name: CheckProjectOwner :one
@@ -82,7 +149,7 @@ SELECT EXISTS(
 -- name: ProjectExists :one
 SELECT EXISTS(
     SELECT 1 FROM projects p
-    WHERE p.id = $1
+    WHERE p.id = $1 AND p.deleted_at IS NULL
 ) as exists;
 
 -- name: CheckProjectOwnerOrAdmin :one
@@ -104,17 +171,17 @@ LEFT JOIN project_members pm ON p.id = pm.project_id AND pm.user_id = $2
 WHERE p.id = $1;
 
 -- name: CreateProjectInvite :one
-INSERT INTO project_invites (project_id, created_by, invite_token, expires_at, max_uses)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at;
+INSERT INTO project_invites (project_id, created_by, invite_token, expires_at, max_uses, recipient_email)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count;
 
 -- name: GetProjectInviteByToken :one
-SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 FROM project_invites
 WHERE invite_token = $1 AND is_active = true;
 
 -- name: GetProjectInviteByID :one
-SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 FROM project_invites
 WHERE id = $1;
 
@@ -129,8 +196,29 @@ SET is_active = false, updated_at = now()
 WHERE id = $1;
 
 -- name: ListProjectInvites :many
-SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+-- Link invites are listed while active; email invites are kept so their
+-- pending/accepted/expired state can be shown per recipient
+SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 FROM project_invites
-WHERE project_id = $1 AND is_active = true
+WHERE project_id = $1 AND (is_active = true OR recipient_email IS NOT NULL)
 ORDER BY created_at DESC;
 
+-- name: MarkInviteAccepted :exec
+-- Email invites are single-recipient, so accepting one also deactivates it
+UPDATE project_invites
+SET accepted_at = now(), accepted_by = $2, used_count = used_count + 1,
+    is_active = false, updated_at = now()
+WHERE id = $1;
+
+-- name: RecordInviteSent :one
+UPDATE project_invites
+SET expires_at = $2, last_sent_at = now(), send_count = send_count + 1, updated_at = now()
+WHERE id = $1
+RETURNING id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count;
+
+-- name: GetProjectMemberRole :one
+-- Canonical model: the owner has a project_members row with role 'owner'.
+-- Projects in the trash grant no role, which closes every project route.
+SELECT pm.role FROM project_members pm
+JOIN projects p ON p.id = pm.project_id
+WHERE pm.project_id = $1 AND pm.user_id = $2 AND p.deleted_at IS NULL;
@@ -11,6 +11,60 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ActivityEvent struct {
+	ID         uuid.UUID   `json:"id"`
+	ProjectID  uuid.UUID   `json:"projectId"`
+	ActorID    pgtype.UUID `json:"actorId"`
+	Resource   string      `json:"resource"`
+	ResourceID pgtype.UUID `json:"resourceId"`
+	Action     string      `json:"action"`
+	Changes    []byte      `json:"changes"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+type AdminAuditLog struct {
+	ID          uuid.UUID   `json:"id"`
+	UserID      pgtype.UUID `json:"userId"`
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Status      int32       `json:"status"`
+	RequestBody string      `json:"requestBody"`
+	RemoteAddr  string      `json:"remoteAddr"`
+	CreatedAt   time.Time   `json:"createdAt"`
+}
+
+type EmailVerification struct {
+	ID        uuid.UUID          `json:"id"`
+	UserID    uuid.UUID          `json:"userId"`
+	Email     string             `json:"email"`
+	TokenHash string             `json:"tokenHash"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	UsedAt    pgtype.Timestamptz `json:"usedAt"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+type IdempotencyKey struct {
+	UserID         uuid.UUID  `json:"userId"`
+	IdempotencyKey string     `json:"idempotencyKey"`
+	Fingerprint    string     `json:"fingerprint"`
+	Status         *int32     `json:"status"`
+	ContentType    string     `json:"contentType"`
+	Body           []byte     `json:"body"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	LockedUntil    *time.Time `json:"lockedUntil"`
+	ClaimToken     uuid.UUID  `json:"claimToken"`
+}
+
+type Label struct {
+	ID        uuid.UUID `json:"id"`
+	ProjectID uuid.UUID `json:"projectId"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
 type Message struct {
 	ID              uuid.UUID   `json:"id"`
 	ProjectID       uuid.UUID   `json:"projectId"`
@@ -37,33 +91,46 @@ type OauthState struct {
 }
 
 type PasswordReset struct {
-	ID         int32     `json:"id"`
-	UserID     uuid.UUID `json:"userId"`
-	ResetToken string    `json:"resetToken"`
-	ExpiresAt  time.Time `json:"expiresAt"`
-	CreatedAt  time.Time `json:"createdAt"`
+	ID        int32              `json:"id"`
+	UserID    uuid.UUID          `json:"userId"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	CreatedAt time.Time          `json:"createdAt"`
+	TokenHash string             `json:"tokenHash"`
+	UsedAt    pgtype.Timestamptz `json:"usedAt"`
 }
 
 type Project struct {
-	ID          uuid.UUID `json:"id"`
-	OwnerID     uuid.UUID `json:"ownerId"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uuid.UUID          `json:"id"`
+	OwnerID     uuid.UUID          `json:"ownerId"`
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	DeletedAt   pgtype.Timestamptz `json:"deletedAt"`
+	Version     int32              `json:"version"`
+}
+
+type ProjectEventSeq struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	LastSeq   int64     `json:"lastSeq"`
 }
 
 type ProjectInvite struct {
-	ID          uuid.UUID `json:"id"`
-	ProjectID   uuid.UUID `json:"projectId"`
-	CreatedBy   uuid.UUID `json:"createdBy"`
-	InviteToken string    `json:"inviteToken"`
-	ExpiresAt   time.Time `json:"expiresAt"`
-	MaxUses     *int32    `json:"maxUses"`
-	UsedCount   int32     `json:"usedCount"`
-	IsActive    bool      `json:"isActive"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID             uuid.UUID          `json:"id"`
+	ProjectID      uuid.UUID          `json:"projectId"`
+	CreatedBy      uuid.UUID          `json:"createdBy"`
+	InviteToken    string             `json:"inviteToken"`
+	ExpiresAt      time.Time          `json:"expiresAt"`
+	MaxUses        *int32             `json:"maxUses"`
+	UsedCount      int32              `json:"usedCount"`
+	IsActive       bool               `json:"isActive"`
+	CreatedAt      time.Time          `json:"createdAt"`
+	UpdatedAt      time.Time          `json:"updatedAt"`
+	RecipientEmail *string            `json:"recipientEmail"`
+	AcceptedAt     pgtype.Timestamptz `json:"acceptedAt"`
+	AcceptedBy     pgtype.UUID        `json:"acceptedBy"`
+	LastSentAt     pgtype.Timestamptz `json:"lastSentAt"`
+	SendCount      int32              `json:"sendCount"`
 }
 
 type ProjectMember struct {
@@ -73,6 +140,14 @@ type ProjectMember struct {
 	JoinedAt  time.Time `json:"joinedAt"`
 }
 
+type RealtimeEvent struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Seq       int64     `json:"seq"`
+	Type      string    `json:"type"`
+	Data      []byte    `json:"data"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 type RefreshToken struct {
 	ID        uuid.UUID `json:"id"`
 	UserID    uuid.UUID `json:"userId"`
@@ -90,28 +165,45 @@ type RefreshToken struct {
 }
 
 type Sprint struct {
-	ID          uuid.UUID `json:"id"`
-	ProjectID   uuid.UUID `json:"projectId"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
-	StartDate   time.Time `json:"startDate"`
-	EndDate     time.Time `json:"endDate"`
-	IsCompleted bool      `json:"isCompleted"`
-	IsStarted   bool      `json:"isStarted"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uuid.UUID          `json:"id"`
+	ProjectID   uuid.UUID          `json:"projectId"`
+	Name        string             `json:"name"`
+	Description *string            `json:"description"`
+	StartDate   time.Time          `json:"startDate"`
+	EndDate     time.Time          `json:"endDate"`
+	IsCompleted bool               `json:"isCompleted"`
+	IsStarted   bool               `json:"isStarted"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	DeletedAt   pgtype.Timestamptz `json:"deletedAt"`
+	Version     int32              `json:"version"`
+}
+
+type SystemAdmin struct {
+	UserID    uuid.UUID `json:"userId"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type Task struct {
-	ID          uuid.UUID   `json:"id"`
-	ProjectID   uuid.UUID   `json:"projectId"`
-	SprintID    pgtype.UUID `json:"sprintId"`
-	AssigneeID  pgtype.UUID `json:"assigneeId"`
-	Title       string      `json:"title"`
-	Description *string     `json:"description"`
-	Status      int32       `json:"status"`
-	CreatedAt   time.Time   `json:"createdAt"`
-	UpdatedAt   time.Time   `json:"updatedAt"`
+	ID          uuid.UUID          `json:"id"`
+	ProjectID   uuid.UUID          `json:"projectId"`
+	SprintID    pgtype.UUID        `json:"sprintId"`
+	AssigneeID  pgtype.UUID        `json:"assigneeId"`
+	Title       string             `json:"title"`
+	Description *string            `json:"description"`
+	Status      int32              `json:"status"`
+	CreatedAt   time.Time          `json:"createdAt"`
+	UpdatedAt   time.Time          `json:"updatedAt"`
+	Priority    int16              `json:"priority"`
+	StoryPoints *int32             `json:"storyPoints"`
+	DueDate     pgtype.Date        `json:"dueDate"`
+	DeletedAt   pgtype.Timestamptz `json:"deletedAt"`
+	Version     int32              `json:"version"`
+}
+
+type TaskLabel struct {
+	TaskID  uuid.UUID `json:"taskId"`
+	LabelID uuid.UUID `json:"labelId"`
 }
 
 type User struct {
@@ -130,5 +222,24 @@ type User struct {
 	// Google unique user identifier (sub claim from Google)
 	GoogleID *string `json:"googleId"`
 	// User profile picture URL from Google
-	ProfilePictureUrl *string `json:"profilePictureUrl"`
+	ProfilePictureUrl *string            `json:"profilePictureUrl"`
+	EmailVerifiedAt   pgtype.Timestamptz `json:"emailVerifiedAt"`
+}
+
+type WorkflowState struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Status    int32     `json:"status"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Position  int32     `json:"position"`
+	IsInitial bool      `json:"isInitial"`
+	IsFinal   bool      `json:"isFinal"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type WorkflowTransition struct {
+	ProjectID  uuid.UUID `json:"projectId"`
+	FromStatus int32     `json:"fromStatus"`
+	ToStatus   int32     `json:"toStatus"`
 }
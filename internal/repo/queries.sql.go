@@ -13,10 +13,11 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const addProjectMember = `-- name: AddProjectMember :exec
+const addProjectMember = `-- name: AddProjectMember :execrows
 INSERT INTO project_members (project_id, user_id, role)
 VALUES ($1, $2, $3)
 ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+WHERE project_members.role <> 'owner'
 `
 
 type AddProjectMemberParams struct {
@@ -25,16 +26,80 @@ type AddProjectMemberParams struct {
 	Role      string    `json:"role"`
 }
 
-// Idempotent: Uses ON CONFLICT to prevent duplicates
-func (q *Queries) AddProjectMember(ctx context.Context, arg AddProjectMemberParams) error {
-	_, err := q.db.Exec(ctx, addProjectMember, arg.ProjectID, arg.UserID, arg.Role)
+// Idempotent: Uses ON CONFLICT to prevent duplicates. Never changes the owner's
+// row, so a re-add racing an ownership transfer cannot demote the new owner;
+// no row is affected then.
+func (q *Queries) AddProjectMember(ctx context.Context, arg AddProjectMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addProjectMember, arg.ProjectID, arg.UserID, arg.Role)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const addTaskLabels = `-- name: AddTaskLabels :exec
+INSERT INTO task_labels (task_id, label_id)
+SELECT t.id, l.id
+FROM tasks t
+JOIN labels l ON l.project_id = t.project_id
+WHERE t.id = $1 AND l.id = ANY($2::uuid[])
+ON CONFLICT DO NOTHING
+`
+
+type AddTaskLabelsParams struct {
+	TaskID   uuid.UUID   `json:"taskId"`
+	LabelIds []uuid.UUID `json:"labelIds"`
+}
+
+// Only attaches labels from the task's own project
+func (q *Queries) AddTaskLabels(ctx context.Context, arg AddTaskLabelsParams) error {
+	_, err := q.db.Exec(ctx, addTaskLabels, arg.TaskID, arg.LabelIds)
 	return err
 }
 
+const carryOverSprintTasks = `-- name: CarryOverSprintTasks :many
+UPDATE tasks t
+SET sprint_id = $1::uuid, updated_at = now()
+WHERE t.sprint_id = $2 AND t.deleted_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM workflow_states ws
+      WHERE ws.project_id = t.project_id AND ws.status = t.status AND ws.is_final
+  )
+RETURNING t.id
+`
+
+type CarryOverSprintTasksParams struct {
+	NextSprintID pgtype.UUID `json:"nextSprintId"`
+	SprintID     pgtype.UUID `json:"sprintId"`
+}
+
+// Moves the sprint's unfinished tasks, those not in a final workflow state,
+// to the next sprint, or to the backlog when it is NULL
+func (q *Queries) CarryOverSprintTasks(ctx context.Context, arg CarryOverSprintTasksParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, carryOverSprintTasks, arg.NextSprintID, arg.SprintID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const checkProjectAccess = `-- name: CheckProjectAccess :one
 SELECT EXISTS(
-    SELECT 1 FROM project_members pm 
-    WHERE pm.project_id = $1 AND pm.user_id = $2
+    SELECT 1 FROM project_members pm
+    JOIN projects p ON p.id = pm.project_id
+    WHERE pm.project_id = $1 AND pm.user_id = $2 AND p.deleted_at IS NULL
 ) as has_access
 `
 
@@ -90,6 +155,314 @@ func (q *Queries) CheckProjectOwnerOrAdmin(ctx context.Context, arg CheckProject
 	return is_owner_or_admin, err
 }
 
+const claimIdempotencyKey = `-- name: ClaimIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, idempotency_key, fingerprint, expires_at, locked_until, claim_token)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET fingerprint = EXCLUDED.fingerprint,
+    status = NULL,
+    content_type = '',
+    body = NULL,
+    created_at = now(),
+    expires_at = EXCLUDED.expires_at,
+    locked_until = EXCLUDED.locked_until,
+    claim_token = EXCLUDED.claim_token
+WHERE idempotency_keys.expires_at <= now()
+   OR (idempotency_keys.status IS NULL AND idempotency_keys.locked_until <= now())
+`
+
+type ClaimIdempotencyKeyParams struct {
+	UserID         uuid.UUID  `json:"userId"`
+	IdempotencyKey string     `json:"idempotencyKey"`
+	Fingerprint    string     `json:"fingerprint"`
+	ExpiresAt      time.Time  `json:"expiresAt"`
+	LockedUntil    *time.Time `json:"lockedUntil"`
+	ClaimToken     uuid.UUID  `json:"claimToken"`
+}
+
+// Claims the key for a new request, taking over an expired key or a claim
+// whose request stopped holding it. Affects no row when the key is in use.
+func (q *Queries) ClaimIdempotencyKey(ctx context.Context, arg ClaimIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.Exec(ctx, claimIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.Fingerprint,
+		arg.ExpiresAt,
+		arg.LockedUntil,
+		arg.ClaimToken,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearTaskLabels = `-- name: ClearTaskLabels :exec
+DELETE FROM task_labels WHERE task_id = $1
+`
+
+func (q *Queries) ClearTaskLabels(ctx context.Context, taskID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, clearTaskLabels, taskID)
+	return err
+}
+
+const completeIdempotencyKey = `-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET status = $3, content_type = $4, body = $5, locked_until = NULL
+WHERE user_id = $1 AND idempotency_key = $2 AND claim_token = $6
+`
+
+type CompleteIdempotencyKeyParams struct {
+	UserID         uuid.UUID `json:"userId"`
+	IdempotencyKey string    `json:"idempotencyKey"`
+	Status         *int32    `json:"status"`
+	ContentType    string    `json:"contentType"`
+	Body           []byte    `json:"body"`
+	ClaimToken     uuid.UUID `json:"claimToken"`
+}
+
+// Stores the response, unless another request has taken the claim over
+func (q *Queries) CompleteIdempotencyKey(ctx context.Context, arg CompleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.Status,
+		arg.ContentType,
+		arg.Body,
+		arg.ClaimToken,
+	)
+	return err
+}
+
+const consumeEmailVerification = `-- name: ConsumeEmailVerification :one
+UPDATE email_verifications
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at
+`
+
+func (q *Queries) ConsumeEmailVerification(ctx context.Context, tokenHash string) (EmailVerification, error) {
+	row := q.db.QueryRow(ctx, consumeEmailVerification, tokenHash)
+	var i EmailVerification
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const consumePasswordReset = `-- name: ConsumePasswordReset :one
+UPDATE password_resets
+SET used_at = now()
+WHERE token_hash = $1 AND used_at IS NULL AND expires_at > now()
+RETURNING id, user_id, token_hash, expires_at, used_at, created_at
+`
+
+type ConsumePasswordResetRow struct {
+	ID        int32              `json:"id"`
+	UserID    uuid.UUID          `json:"userId"`
+	TokenHash string             `json:"tokenHash"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	UsedAt    pgtype.Timestamptz `json:"usedAt"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+func (q *Queries) ConsumePasswordReset(ctx context.Context, tokenHash string) (ConsumePasswordResetRow, error) {
+	row := q.db.QueryRow(ctx, consumePasswordReset, tokenHash)
+	var i ConsumePasswordResetRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const countActiveEmailVerifications = `-- name: CountActiveEmailVerifications :one
+SELECT COUNT(*) FROM email_verifications
+WHERE user_id = $1 AND used_at IS NULL AND expires_at > now()
+`
+
+func (q *Queries) CountActiveEmailVerifications(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveEmailVerifications, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countActivePasswordResets = `-- name: CountActivePasswordResets :one
+SELECT COUNT(*) FROM password_resets
+WHERE user_id = $1 AND used_at IS NULL AND expires_at > now()
+`
+
+func (q *Queries) CountActivePasswordResets(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countActivePasswordResets, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countProjectLabels = `-- name: CountProjectLabels :one
+SELECT COUNT(*) FROM labels
+WHERE project_id = $1 AND id = ANY($2::uuid[])
+`
+
+type CountProjectLabelsParams struct {
+	ProjectID uuid.UUID   `json:"projectId"`
+	LabelIds  []uuid.UUID `json:"labelIds"`
+}
+
+// Counts how many of the given label IDs belong to the project
+func (q *Queries) CountProjectLabels(ctx context.Context, arg CountProjectLabelsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countProjectLabels, arg.ProjectID, arg.LabelIds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWorkflowStates = `-- name: CountWorkflowStates :one
+SELECT COUNT(*) FROM workflow_states WHERE project_id = $1
+`
+
+func (q *Queries) CountWorkflowStates(ctx context.Context, projectID uuid.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, countWorkflowStates, projectID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createActivityEvent = `-- name: CreateActivityEvent :one
+INSERT INTO activity_events (project_id, actor_id, resource, resource_id, action, changes)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, project_id, actor_id, resource, resource_id, action, changes, created_at
+`
+
+type CreateActivityEventParams struct {
+	ProjectID  uuid.UUID   `json:"projectId"`
+	ActorID    pgtype.UUID `json:"actorId"`
+	Resource   string      `json:"resource"`
+	ResourceID pgtype.UUID `json:"resourceId"`
+	Action     string      `json:"action"`
+	Changes    []byte      `json:"changes"`
+}
+
+func (q *Queries) CreateActivityEvent(ctx context.Context, arg CreateActivityEventParams) (ActivityEvent, error) {
+	row := q.db.QueryRow(ctx, createActivityEvent,
+		arg.ProjectID,
+		arg.ActorID,
+		arg.Resource,
+		arg.ResourceID,
+		arg.Action,
+		arg.Changes,
+	)
+	var i ActivityEvent
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.ActorID,
+		&i.Resource,
+		&i.ResourceID,
+		&i.Action,
+		&i.Changes,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createAdminAuditEntry = `-- name: CreateAdminAuditEntry :exec
+INSERT INTO admin_audit_log (user_id, method, path, status, request_body, remote_addr)
+VALUES ($1, $2, $3, $4, $5, $6)
+`
+
+type CreateAdminAuditEntryParams struct {
+	UserID      pgtype.UUID `json:"userId"`
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Status      int32       `json:"status"`
+	RequestBody string      `json:"requestBody"`
+	RemoteAddr  string      `json:"remoteAddr"`
+}
+
+func (q *Queries) CreateAdminAuditEntry(ctx context.Context, arg CreateAdminAuditEntryParams) error {
+	_, err := q.db.Exec(ctx, createAdminAuditEntry,
+		arg.UserID,
+		arg.Method,
+		arg.Path,
+		arg.Status,
+		arg.RequestBody,
+		arg.RemoteAddr,
+	)
+	return err
+}
+
+const createEmailVerification = `-- name: CreateEmailVerification :one
+INSERT INTO email_verifications (user_id, email, token_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING id, user_id, email, token_hash, expires_at, used_at, created_at
+`
+
+type CreateEmailVerificationParams struct {
+	UserID    uuid.UUID `json:"userId"`
+	Email     string    `json:"email"`
+	TokenHash string    `json:"tokenHash"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Email Verification Queries
+func (q *Queries) CreateEmailVerification(ctx context.Context, arg CreateEmailVerificationParams) (EmailVerification, error) {
+	row := q.db.QueryRow(ctx, createEmailVerification,
+		arg.UserID,
+		arg.Email,
+		arg.TokenHash,
+		arg.ExpiresAt,
+	)
+	var i EmailVerification
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Email,
+		&i.TokenHash,
+		&i.ExpiresAt,
+		&i.UsedAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createLabel = `-- name: CreateLabel :one
+INSERT INTO labels (project_id, name, color)
+VALUES ($1, $2, $3)
+RETURNING id, project_id, name, color, created_at, updated_at
+`
+
+type CreateLabelParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+}
+
+func (q *Queries) CreateLabel(ctx context.Context, arg CreateLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, createLabel, arg.ProjectID, arg.Name, arg.Color)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createMessage = `-- name: CreateMessage :one
 INSERT INTO messages (project_id, sender_id, content, message_type, parent_message_id)
 VALUES ($1, $2, $3, $4, $5)
@@ -160,9 +533,9 @@ func (q *Queries) CreateOAuthState(ctx context.Context, arg CreateOAuthStatePara
 }
 
 const createOAuthUser = `-- name: CreateOAuthUser :one
-INSERT INTO users (username, email, first_name, last_name, auth_provider, google_id, profile_picture_url)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, created_at, updated_at
+INSERT INTO users (username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, email_verified_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+RETURNING id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, email_verified_at, created_at, updated_at
 `
 
 type CreateOAuthUserParams struct {
@@ -176,20 +549,22 @@ type CreateOAuthUserParams struct {
 }
 
 type CreateOAuthUserRow struct {
-	ID                uuid.UUID `json:"id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	FirstName         string    `json:"firstName"`
-	LastName          string    `json:"lastName"`
-	AuthProvider      *string   `json:"authProvider"`
-	GoogleID          *string   `json:"googleId"`
-	ProfilePictureUrl *string   `json:"profilePictureUrl"`
-	Active            bool      `json:"active"`
-	AvatarUrl         *string   `json:"avatarUrl"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
-}
-
+	ID                uuid.UUID          `json:"id"`
+	Username          string             `json:"username"`
+	Email             string             `json:"email"`
+	FirstName         string             `json:"firstName"`
+	LastName          string             `json:"lastName"`
+	AuthProvider      *string            `json:"authProvider"`
+	GoogleID          *string            `json:"googleId"`
+	ProfilePictureUrl *string            `json:"profilePictureUrl"`
+	Active            bool               `json:"active"`
+	AvatarUrl         *string            `json:"avatarUrl"`
+	EmailVerifiedAt   pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt"`
+}
+
+// Google has already verified the email address
 func (q *Queries) CreateOAuthUser(ctx context.Context, arg CreateOAuthUserParams) (CreateOAuthUserRow, error) {
 	row := q.db.QueryRow(ctx, createOAuthUser,
 		arg.Username,
@@ -212,6 +587,7 @@ func (q *Queries) CreateOAuthUser(ctx context.Context, arg CreateOAuthUserParams
 		&i.ProfilePictureUrl,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -219,25 +595,35 @@ func (q *Queries) CreateOAuthUser(ctx context.Context, arg CreateOAuthUserParams
 }
 
 const createPasswordReset = `-- name: CreatePasswordReset :one
-INSERT INTO password_resets (user_id, reset_token, expires_at)
+INSERT INTO password_resets (user_id, token_hash, expires_at)
 VALUES ($1, $2, $3)
-RETURNING id, user_id, reset_token, expires_at, created_at
+RETURNING id, user_id, token_hash, expires_at, used_at, created_at
 `
 
 type CreatePasswordResetParams struct {
-	UserID     uuid.UUID `json:"userId"`
-	ResetToken string    `json:"resetToken"`
-	ExpiresAt  time.Time `json:"expiresAt"`
+	UserID    uuid.UUID `json:"userId"`
+	TokenHash string    `json:"tokenHash"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
-func (q *Queries) CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error) {
-	row := q.db.QueryRow(ctx, createPasswordReset, arg.UserID, arg.ResetToken, arg.ExpiresAt)
-	var i PasswordReset
+type CreatePasswordResetRow struct {
+	ID        int32              `json:"id"`
+	UserID    uuid.UUID          `json:"userId"`
+	TokenHash string             `json:"tokenHash"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+	UsedAt    pgtype.Timestamptz `json:"usedAt"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+func (q *Queries) CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (CreatePasswordResetRow, error) {
+	row := q.db.QueryRow(ctx, createPasswordReset, arg.UserID, arg.TokenHash, arg.ExpiresAt)
+	var i CreatePasswordResetRow
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
-		&i.ResetToken,
+		&i.TokenHash,
 		&i.ExpiresAt,
+		&i.UsedAt,
 		&i.CreatedAt,
 	)
 	return i, err
@@ -246,7 +632,7 @@ func (q *Queries) CreatePasswordReset(ctx context.Context, arg CreatePasswordRes
 const createProject = `-- name: CreateProject :one
 INSERT INTO projects (owner_id, name, description)
 VALUES ($1, $2, $3)
-RETURNING id, owner_id, name, description, created_at, updated_at
+RETURNING id, owner_id, name, description, created_at, updated_at, deleted_at, version
 `
 
 type CreateProjectParams struct {
@@ -265,22 +651,25 @@ func (q *Queries) CreateProject(ctx context.Context, arg CreateProjectParams) (P
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const createProjectInvite = `-- name: CreateProjectInvite :one
-INSERT INTO project_invites (project_id, created_by, invite_token, expires_at, max_uses)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+INSERT INTO project_invites (project_id, created_by, invite_token, expires_at, max_uses, recipient_email)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 `
 
 type CreateProjectInviteParams struct {
-	ProjectID   uuid.UUID `json:"projectId"`
-	CreatedBy   uuid.UUID `json:"createdBy"`
-	InviteToken string    `json:"inviteToken"`
-	ExpiresAt   time.Time `json:"expiresAt"`
-	MaxUses     *int32    `json:"maxUses"`
+	ProjectID      uuid.UUID `json:"projectId"`
+	CreatedBy      uuid.UUID `json:"createdBy"`
+	InviteToken    string    `json:"inviteToken"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	MaxUses        *int32    `json:"maxUses"`
+	RecipientEmail *string   `json:"recipientEmail"`
 }
 
 func (q *Queries) CreateProjectInvite(ctx context.Context, arg CreateProjectInviteParams) (ProjectInvite, error) {
@@ -290,6 +679,7 @@ func (q *Queries) CreateProjectInvite(ctx context.Context, arg CreateProjectInvi
 		arg.InviteToken,
 		arg.ExpiresAt,
 		arg.MaxUses,
+		arg.RecipientEmail,
 	)
 	var i ProjectInvite
 	err := row.Scan(
@@ -303,6 +693,11 @@ func (q *Queries) CreateProjectInvite(ctx context.Context, arg CreateProjectInvi
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecipientEmail,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.LastSentAt,
+		&i.SendCount,
 	)
 	return i, err
 }
@@ -406,7 +801,7 @@ func (q *Queries) CreateRefreshTokenWithGoogle(ctx context.Context, arg CreateRe
 const createSprint = `-- name: CreateSprint :one
 INSERT INTO sprints (project_id, name, description, start_date, end_date)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at
+RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version
 `
 
 type CreateSprintParams struct {
@@ -437,14 +832,16 @@ func (q *Queries) CreateSprint(ctx context.Context, arg CreateSprintParams) (Spr
 		&i.IsStarted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const createTask = `-- name: CreateTask :one
-INSERT INTO tasks (project_id, sprint_id, assignee_id, description, status)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, project_id, sprint_id, assignee_id, description, status, created_at, updated_at
+INSERT INTO tasks (project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version
 `
 
 type CreateTaskParams struct {
@@ -453,6 +850,9 @@ type CreateTaskParams struct {
 	AssigneeID  pgtype.UUID `json:"assigneeId"`
 	Description *string     `json:"description"`
 	Status      int32       `json:"status"`
+	Priority    int16       `json:"priority"`
+	StoryPoints *int32      `json:"storyPoints"`
+	DueDate     pgtype.Date `json:"dueDate"`
 }
 
 type CreateTaskRow struct {
@@ -462,8 +862,12 @@ type CreateTaskRow struct {
 	AssigneeID  pgtype.UUID `json:"assigneeId"`
 	Description *string     `json:"description"`
 	Status      int32       `json:"status"`
+	Priority    int16       `json:"priority"`
+	StoryPoints *int32      `json:"storyPoints"`
+	DueDate     pgtype.Date `json:"dueDate"`
 	CreatedAt   time.Time   `json:"createdAt"`
 	UpdatedAt   time.Time   `json:"updatedAt"`
+	Version     int32       `json:"version"`
 }
 
 func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (CreateTaskRow, error) {
@@ -473,6 +877,9 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (CreateT
 		arg.AssigneeID,
 		arg.Description,
 		arg.Status,
+		arg.Priority,
+		arg.StoryPoints,
+		arg.DueDate,
 	)
 	var i CreateTaskRow
 	err := row.Scan(
@@ -482,8 +889,12 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (CreateT
 		&i.AssigneeID,
 		&i.Description,
 		&i.Status,
+		&i.Priority,
+		&i.StoryPoints,
+		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -491,7 +902,7 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (CreateT
 const createUser = `-- name: CreateUser :one
 INSERT INTO users (username, email, password_h, first_name, last_name)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at
+RETURNING id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 `
 
 type CreateUserParams struct {
@@ -503,15 +914,16 @@ type CreateUserParams struct {
 }
 
 type CreateUserRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateUserRow, error) {
@@ -531,12 +943,70 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (CreateU
 		&i.LastName,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const createWorkflowState = `-- name: CreateWorkflowState :one
+INSERT INTO workflow_states (project_id, status, name, color, position, is_initial, is_final)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+`
+
+type CreateWorkflowStateParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Status    int32     `json:"status"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	Position  int32     `json:"position"`
+	IsInitial bool      `json:"isInitial"`
+	IsFinal   bool      `json:"isFinal"`
+}
+
+func (q *Queries) CreateWorkflowState(ctx context.Context, arg CreateWorkflowStateParams) (WorkflowState, error) {
+	row := q.db.QueryRow(ctx, createWorkflowState,
+		arg.ProjectID,
+		arg.Status,
+		arg.Name,
+		arg.Color,
+		arg.Position,
+		arg.IsInitial,
+		arg.IsFinal,
+	)
+	var i WorkflowState
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Status,
+		&i.Name,
+		&i.Color,
+		&i.Position,
+		&i.IsInitial,
+		&i.IsFinal,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
 	return i, err
 }
 
+const createWorkflowTransition = `-- name: CreateWorkflowTransition :exec
+INSERT INTO workflow_transitions (project_id, from_status, to_status)
+VALUES ($1, $2, $3)
+`
+
+type CreateWorkflowTransitionParams struct {
+	ProjectID  uuid.UUID `json:"projectId"`
+	FromStatus int32     `json:"fromStatus"`
+	ToStatus   int32     `json:"toStatus"`
+}
+
+func (q *Queries) CreateWorkflowTransition(ctx context.Context, arg CreateWorkflowTransitionParams) error {
+	_, err := q.db.Exec(ctx, createWorkflowTransition, arg.ProjectID, arg.FromStatus, arg.ToStatus)
+	return err
+}
+
 const deactivateInvite = `-- name: DeactivateInvite :exec
 UPDATE project_invites
 SET is_active = false, updated_at = now()
@@ -559,6 +1029,40 @@ func (q *Queries) DeactivateUser(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deferActivityNotifications = `-- name: DeferActivityNotifications :exec
+SELECT set_config('devhive.defer_activity_notify', 'on', true)
+`
+
+// Holds back per-entry activity notifications until the end of the
+// transaction; NotifyActivityBatch then sends one for all of them
+func (q *Queries) DeferActivityNotifications(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deferActivityNotifications)
+	return err
+}
+
+const deferTaskNotifications = `-- name: DeferTaskNotifications :exec
+SELECT set_config('devhive.defer_task_notify', 'on', true)
+`
+
+// Holds back per-task cache invalidations until the end of the transaction;
+// NotifyTasksChanged then sends one for all of them
+func (q *Queries) DeferTaskNotifications(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, deferTaskNotifications)
+	return err
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= now()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteExpiredOAuthStates = `-- name: DeleteExpiredOAuthStates :exec
 DELETE FROM oauth_state WHERE expires_at < now()
 `
@@ -569,7 +1073,7 @@ func (q *Queries) DeleteExpiredOAuthStates(ctx context.Context) error {
 }
 
 const deleteExpiredPasswordResets = `-- name: DeleteExpiredPasswordResets :exec
-DELETE FROM password_resets WHERE expires_at < now()
+DELETE FROM password_resets WHERE expires_at < now() OR used_at IS NOT NULL
 `
 
 func (q *Queries) DeleteExpiredPasswordResets(ctx context.Context) error {
@@ -586,6 +1090,15 @@ func (q *Queries) DeleteExpiredRefreshTokens(ctx context.Context) error {
 	return err
 }
 
+const deleteLabel = `-- name: DeleteLabel :exec
+DELETE FROM labels WHERE id = $1
+`
+
+func (q *Queries) DeleteLabel(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteLabel, id)
+	return err
+}
+
 const deleteMessage = `-- name: DeleteMessage :exec
 DELETE FROM messages WHERE id = $1
 `
@@ -604,62 +1117,171 @@ func (q *Queries) DeleteOAuthState(ctx context.Context, stateToken string) error
 	return err
 }
 
-const deletePasswordReset = `-- name: DeletePasswordReset :exec
-DELETE FROM password_resets WHERE reset_token = $1
+const deleteRefreshToken = `-- name: DeleteRefreshToken :exec
+DELETE FROM refresh_tokens WHERE token = $1
 `
 
-func (q *Queries) DeletePasswordReset(ctx context.Context, resetToken string) error {
-	_, err := q.db.Exec(ctx, deletePasswordReset, resetToken)
+func (q *Queries) DeleteRefreshToken(ctx context.Context, token string) error {
+	_, err := q.db.Exec(ctx, deleteRefreshToken, token)
 	return err
 }
 
-const deleteProject = `-- name: DeleteProject :exec
-DELETE FROM projects WHERE id = $1
+const deleteUserRefreshTokens = `-- name: DeleteUserRefreshTokens :exec
+DELETE FROM refresh_tokens WHERE user_id = $1
 `
 
-func (q *Queries) DeleteProject(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteProject, id)
+func (q *Queries) DeleteUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserRefreshTokens, userID)
 	return err
 }
 
-const deleteRefreshToken = `-- name: DeleteRefreshToken :exec
-DELETE FROM refresh_tokens WHERE token = $1
+const deleteWorkflowStates = `-- name: DeleteWorkflowStates :exec
+DELETE FROM workflow_states WHERE project_id = $1
 `
 
-func (q *Queries) DeleteRefreshToken(ctx context.Context, token string) error {
-	_, err := q.db.Exec(ctx, deleteRefreshToken, token)
+func (q *Queries) DeleteWorkflowStates(ctx context.Context, projectID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWorkflowStates, projectID)
 	return err
 }
 
-const deleteSprint = `-- name: DeleteSprint :exec
-DELETE FROM sprints WHERE id = $1
+const deleteWorkflowTransitions = `-- name: DeleteWorkflowTransitions :exec
+DELETE FROM workflow_transitions WHERE project_id = $1
 `
 
-func (q *Queries) DeleteSprint(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteSprint, id)
+func (q *Queries) DeleteWorkflowTransitions(ctx context.Context, projectID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteWorkflowTransitions, projectID)
 	return err
 }
 
-const deleteTask = `-- name: DeleteTask :exec
-DELETE FROM tasks WHERE id = $1
+const getDeletedSprint = `-- name: GetDeletedSprint :one
+SELECT id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version
+FROM sprints
+WHERE id = $1 AND deleted_at IS NOT NULL
 `
 
-func (q *Queries) DeleteTask(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteTask, id)
-	return err
+func (q *Queries) GetDeletedSprint(ctx context.Context, id uuid.UUID) (Sprint, error) {
+	row := q.db.QueryRow(ctx, getDeletedSprint, id)
+	var i Sprint
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Description,
+		&i.StartDate,
+		&i.EndDate,
+		&i.IsCompleted,
+		&i.IsStarted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
 }
 
-const deleteUserRefreshTokens = `-- name: DeleteUserRefreshTokens :exec
-DELETE FROM refresh_tokens WHERE user_id = $1
+const getDeletedTask = `-- name: GetDeletedTask :one
+SELECT id, project_id, sprint_id, description, status, deleted_at
+FROM tasks
+WHERE id = $1 AND deleted_at IS NOT NULL
 `
 
-func (q *Queries) DeleteUserRefreshTokens(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUserRefreshTokens, userID)
-	return err
+type GetDeletedTaskRow struct {
+	ID          uuid.UUID          `json:"id"`
+	ProjectID   uuid.UUID          `json:"projectId"`
+	SprintID    pgtype.UUID        `json:"sprintId"`
+	Description *string            `json:"description"`
+	Status      int32              `json:"status"`
+	DeletedAt   pgtype.Timestamptz `json:"deletedAt"`
 }
 
-const getMessageByID = `-- name: GetMessageByID :one
-SELECT m.id, m.project_id, m.sender_id, m.content, m.message_type, m.parent_message_id, m.created_at, m.updated_at,
+func (q *Queries) GetDeletedTask(ctx context.Context, id uuid.UUID) (GetDeletedTaskRow, error) {
+	row := q.db.QueryRow(ctx, getDeletedTask, id)
+	var i GetDeletedTaskRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.SprintID,
+		&i.Description,
+		&i.Status,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, idempotency_key, fingerprint, status, content_type, body, created_at, expires_at, locked_until, claim_token
+FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID         uuid.UUID `json:"userId"`
+	IdempotencyKey string    `json:"idempotencyKey"`
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.Fingerprint,
+		&i.Status,
+		&i.ContentType,
+		&i.Body,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.LockedUntil,
+		&i.ClaimToken,
+	)
+	return i, err
+}
+
+const getInitialWorkflowState = `-- name: GetInitialWorkflowState :one
+SELECT project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+FROM workflow_states
+WHERE project_id = $1 AND is_initial
+`
+
+func (q *Queries) GetInitialWorkflowState(ctx context.Context, projectID uuid.UUID) (WorkflowState, error) {
+	row := q.db.QueryRow(ctx, getInitialWorkflowState, projectID)
+	var i WorkflowState
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Status,
+		&i.Name,
+		&i.Color,
+		&i.Position,
+		&i.IsInitial,
+		&i.IsFinal,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getLabelByID = `-- name: GetLabelByID :one
+SELECT id, project_id, name, color, created_at, updated_at
+FROM labels
+WHERE id = $1
+`
+
+func (q *Queries) GetLabelByID(ctx context.Context, id uuid.UUID) (Label, error) {
+	row := q.db.QueryRow(ctx, getLabelByID, id)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getMessageByID = `-- name: GetMessageByID :one
+SELECT m.id, m.project_id, m.sender_id, m.content, m.message_type, m.parent_message_id, m.created_at, m.updated_at,
        u.username as sender_username, u.first_name as sender_first_name, u.last_name as sender_last_name, u.avatar_url as sender_avatar_url
 FROM messages m
 JOIN users u ON m.sender_id = u.id
@@ -721,50 +1343,13 @@ func (q *Queries) GetOAuthState(ctx context.Context, stateToken string) (OauthSt
 	return i, err
 }
 
-const getPasswordResetByToken = `-- name: GetPasswordResetByToken :one
-SELECT pr.id, pr.user_id, pr.reset_token, pr.expires_at, pr.created_at,
-       u.username, u.email, u.first_name, u.last_name
-FROM password_resets pr
-JOIN users u ON pr.user_id = u.id
-WHERE pr.reset_token = $1
-`
-
-type GetPasswordResetByTokenRow struct {
-	ID         int32     `json:"id"`
-	UserID     uuid.UUID `json:"userId"`
-	ResetToken string    `json:"resetToken"`
-	ExpiresAt  time.Time `json:"expiresAt"`
-	CreatedAt  time.Time `json:"createdAt"`
-	Username   string    `json:"username"`
-	Email      string    `json:"email"`
-	FirstName  string    `json:"firstName"`
-	LastName   string    `json:"lastName"`
-}
-
-func (q *Queries) GetPasswordResetByToken(ctx context.Context, resetToken string) (GetPasswordResetByTokenRow, error) {
-	row := q.db.QueryRow(ctx, getPasswordResetByToken, resetToken)
-	var i GetPasswordResetByTokenRow
-	err := row.Scan(
-		&i.ID,
-		&i.UserID,
-		&i.ResetToken,
-		&i.ExpiresAt,
-		&i.CreatedAt,
-		&i.Username,
-		&i.Email,
-		&i.FirstName,
-		&i.LastName,
-	)
-	return i, err
-}
-
 const getProjectByID = `-- name: GetProjectByID :one
-SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at,
+SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at, p.version,
        u.id as owner_id, u.username as owner_username, u.email as owner_email,
        u.first_name as owner_first_name, u.last_name as owner_last_name
 FROM projects p
 JOIN users u ON p.owner_id = u.id
-WHERE p.id = $1
+WHERE p.id = $1 AND p.deleted_at IS NULL
 `
 
 type GetProjectByIDRow struct {
@@ -774,6 +1359,7 @@ type GetProjectByIDRow struct {
 	Description    *string   `json:"description"`
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
+	Version        int32     `json:"version"`
 	OwnerID_2      uuid.UUID `json:"ownerId2"`
 	OwnerUsername  string    `json:"ownerUsername"`
 	OwnerEmail     string    `json:"ownerEmail"`
@@ -791,6 +1377,7 @@ func (q *Queries) GetProjectByID(ctx context.Context, id uuid.UUID) (GetProjectB
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 		&i.OwnerID_2,
 		&i.OwnerUsername,
 		&i.OwnerEmail,
@@ -801,7 +1388,7 @@ func (q *Queries) GetProjectByID(ctx context.Context, id uuid.UUID) (GetProjectB
 }
 
 const getProjectInviteByID = `-- name: GetProjectInviteByID :one
-SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 FROM project_invites
 WHERE id = $1
 `
@@ -820,12 +1407,17 @@ func (q *Queries) GetProjectInviteByID(ctx context.Context, id uuid.UUID) (Proje
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecipientEmail,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.LastSentAt,
+		&i.SendCount,
 	)
 	return i, err
 }
 
 const getProjectInviteByToken = `-- name: GetProjectInviteByToken :one
-SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 FROM project_invites
 WHERE invite_token = $1 AND is_active = true
 `
@@ -844,10 +1436,35 @@ func (q *Queries) GetProjectInviteByToken(ctx context.Context, inviteToken strin
 		&i.IsActive,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RecipientEmail,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.LastSentAt,
+		&i.SendCount,
 	)
 	return i, err
 }
 
+const getProjectMemberRole = `-- name: GetProjectMemberRole :one
+SELECT pm.role FROM project_members pm
+JOIN projects p ON p.id = pm.project_id
+WHERE pm.project_id = $1 AND pm.user_id = $2 AND p.deleted_at IS NULL
+`
+
+type GetProjectMemberRoleParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// Canonical model: the owner has a project_members row with role 'owner'.
+// Projects in the trash grant no role, which closes every project route.
+func (q *Queries) GetProjectMemberRole(ctx context.Context, arg GetProjectMemberRoleParams) (string, error) {
+	row := q.db.QueryRow(ctx, getProjectMemberRole, arg.ProjectID, arg.UserID)
+	var role string
+	err := row.Scan(&role)
+	return role, err
+}
+
 const getProjectMembers = `-- name: GetProjectMembers :many
 SELECT pm.project_id, pm.user_id, pm.role, pm.joined_at,
        u.username, u.email, u.first_name, u.last_name, u.avatar_url
@@ -900,6 +1517,26 @@ func (q *Queries) GetProjectMembers(ctx context.Context, projectID uuid.UUID) ([
 	return items, nil
 }
 
+const getRealtimeEventBounds = `-- name: GetRealtimeEventBounds :one
+SELECT
+    COALESCE((SELECT MIN(seq) FROM realtime_events WHERE project_id = $1), 0)::bigint AS oldest_seq,
+    COALESCE((SELECT last_seq FROM project_event_seqs WHERE project_id = $1), 0)::bigint AS last_seq
+`
+
+type GetRealtimeEventBoundsRow struct {
+	OldestSeq int64 `json:"oldestSeq"`
+	LastSeq   int64 `json:"lastSeq"`
+}
+
+// Oldest sequence number still in the project's event log and the last one
+// assigned; both are 0 before the project's first event
+func (q *Queries) GetRealtimeEventBounds(ctx context.Context, projectID uuid.UUID) (GetRealtimeEventBoundsRow, error) {
+	row := q.db.QueryRow(ctx, getRealtimeEventBounds, projectID)
+	var i GetRealtimeEventBoundsRow
+	err := row.Scan(&i.OldestSeq, &i.LastSeq)
+	return i, err
+}
+
 const getRefreshToken = `-- name: GetRefreshToken :one
 SELECT id, user_id, token, expires_at, is_persistent, created_at
 FROM refresh_tokens
@@ -930,12 +1567,12 @@ func (q *Queries) GetRefreshToken(ctx context.Context, token string) (GetRefresh
 }
 
 const getSprintByID = `-- name: GetSprintByID :one
-SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at,
+SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at, s.version,
        p.owner_id, u.username as owner_username, u.email as owner_email, u.first_name as owner_first_name, u.last_name as owner_last_name
 FROM sprints s
 JOIN projects p ON s.project_id = p.id
 JOIN users u ON p.owner_id = u.id
-WHERE s.id = $1
+WHERE s.id = $1 AND s.deleted_at IS NULL
 `
 
 type GetSprintByIDRow struct {
@@ -949,6 +1586,7 @@ type GetSprintByIDRow struct {
 	IsStarted      bool      `json:"isStarted"`
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
+	Version        int32     `json:"version"`
 	OwnerID        uuid.UUID `json:"ownerId"`
 	OwnerUsername  string    `json:"ownerUsername"`
 	OwnerEmail     string    `json:"ownerEmail"`
@@ -970,6 +1608,7 @@ func (q *Queries) GetSprintByID(ctx context.Context, id uuid.UUID) (GetSprintByI
 		&i.IsStarted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 		&i.OwnerID,
 		&i.OwnerUsername,
 		&i.OwnerEmail,
@@ -980,14 +1619,14 @@ func (q *Queries) GetSprintByID(ctx context.Context, id uuid.UUID) (GetSprintByI
 }
 
 const getTaskByID = `-- name: GetTaskByID :one
-SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.created_at, t.updated_at,
+SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.priority, t.story_points, t.due_date, t.created_at, t.updated_at, t.version,
        u.username as assignee_username, u.first_name as assignee_first_name, u.last_name as assignee_last_name,
        p.owner_id, owner.username as owner_username, owner.email as owner_email, owner.first_name as owner_first_name, owner.last_name as owner_last_name
 FROM tasks t
 LEFT JOIN users u ON t.assignee_id = u.id
 JOIN projects p ON t.project_id = p.id
 JOIN users owner ON p.owner_id = owner.id
-WHERE t.id = $1
+WHERE t.id = $1 AND t.deleted_at IS NULL
 `
 
 type GetTaskByIDRow struct {
@@ -997,8 +1636,12 @@ type GetTaskByIDRow struct {
 	AssigneeID        pgtype.UUID `json:"assigneeId"`
 	Description       *string     `json:"description"`
 	Status            int32       `json:"status"`
+	Priority          int16       `json:"priority"`
+	StoryPoints       *int32      `json:"storyPoints"`
+	DueDate           pgtype.Date `json:"dueDate"`
 	CreatedAt         time.Time   `json:"createdAt"`
 	UpdatedAt         time.Time   `json:"updatedAt"`
+	Version           int32       `json:"version"`
 	AssigneeUsername  *string     `json:"assigneeUsername"`
 	AssigneeFirstName *string     `json:"assigneeFirstName"`
 	AssigneeLastName  *string     `json:"assigneeLastName"`
@@ -1019,8 +1662,12 @@ func (q *Queries) GetTaskByID(ctx context.Context, id uuid.UUID) (GetTaskByIDRow
 		&i.AssigneeID,
 		&i.Description,
 		&i.Status,
+		&i.Priority,
+		&i.StoryPoints,
+		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 		&i.AssigneeUsername,
 		&i.AssigneeFirstName,
 		&i.AssigneeLastName,
@@ -1034,22 +1681,23 @@ func (q *Queries) GetTaskByID(ctx context.Context, id uuid.UUID) (GetTaskByIDRow
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE lower(email) = lower($1)
 `
 
 type GetUserByEmailRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	PasswordH *string   `json:"passwordH"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	PasswordH       *string            `json:"passwordH"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
 }
 
 func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (GetUserByEmailRow, error) {
@@ -1064,6 +1712,7 @@ func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (GetUserByEm
 		&i.LastName,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -1071,24 +1720,25 @@ func (q *Queries) GetUserByEmail(ctx context.Context, lower string) (GetUserByEm
 }
 
 const getUserByGoogleID = `-- name: GetUserByGoogleID :one
-SELECT id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, created_at, updated_at
+SELECT id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE google_id = $1
 `
 
 type GetUserByGoogleIDRow struct {
-	ID                uuid.UUID `json:"id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	FirstName         string    `json:"firstName"`
-	LastName          string    `json:"lastName"`
-	AuthProvider      *string   `json:"authProvider"`
-	GoogleID          *string   `json:"googleId"`
-	ProfilePictureUrl *string   `json:"profilePictureUrl"`
-	Active            bool      `json:"active"`
-	AvatarUrl         *string   `json:"avatarUrl"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	ID                uuid.UUID          `json:"id"`
+	Username          string             `json:"username"`
+	Email             string             `json:"email"`
+	FirstName         string             `json:"firstName"`
+	LastName          string             `json:"lastName"`
+	AuthProvider      *string            `json:"authProvider"`
+	GoogleID          *string            `json:"googleId"`
+	ProfilePictureUrl *string            `json:"profilePictureUrl"`
+	Active            bool               `json:"active"`
+	AvatarUrl         *string            `json:"avatarUrl"`
+	EmailVerifiedAt   pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt         time.Time          `json:"createdAt"`
+	UpdatedAt         time.Time          `json:"updatedAt"`
 }
 
 // OAuth User Queries
@@ -1106,6 +1756,7 @@ func (q *Queries) GetUserByGoogleID(ctx context.Context, googleID *string) (GetU
 		&i.ProfilePictureUrl,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -1113,21 +1764,22 @@ func (q *Queries) GetUserByGoogleID(ctx context.Context, googleID *string) (GetU
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE id = $1
 `
 
 type GetUserByIDRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
 }
 
 func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow, error) {
@@ -1141,6 +1793,7 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 		&i.LastName,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -1148,22 +1801,23 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 }
 
 const getUserByIDWithPassword = `-- name: GetUserByIDWithPassword :one
-SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE id = $1
 `
 
 type GetUserByIDWithPasswordRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	PasswordH *string   `json:"passwordH"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	PasswordH       *string            `json:"passwordH"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
 }
 
 func (q *Queries) GetUserByIDWithPassword(ctx context.Context, id uuid.UUID) (GetUserByIDWithPasswordRow, error) {
@@ -1178,6 +1832,7 @@ func (q *Queries) GetUserByIDWithPassword(ctx context.Context, id uuid.UUID) (Ge
 		&i.LastName,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -1185,22 +1840,23 @@ func (q *Queries) GetUserByIDWithPassword(ctx context.Context, id uuid.UUID) (Ge
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE lower(username) = lower($1)
 `
 
 type GetUserByUsernameRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	PasswordH *string   `json:"passwordH"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	PasswordH       *string            `json:"passwordH"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
 }
 
 func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (GetUserByUsernameRow, error) {
@@ -1215,6 +1871,7 @@ func (q *Queries) GetUserByUsername(ctx context.Context, lower string) (GetUserB
 		&i.LastName,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -1245,6 +1902,34 @@ func (q *Queries) GetUserProjectRole(ctx context.Context, arg GetUserProjectRole
 	return role, err
 }
 
+const getWorkflowState = `-- name: GetWorkflowState :one
+SELECT project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+FROM workflow_states
+WHERE project_id = $1 AND status = $2
+`
+
+type GetWorkflowStateParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	Status    int32     `json:"status"`
+}
+
+func (q *Queries) GetWorkflowState(ctx context.Context, arg GetWorkflowStateParams) (WorkflowState, error) {
+	row := q.db.QueryRow(ctx, getWorkflowState, arg.ProjectID, arg.Status)
+	var i WorkflowState
+	err := row.Scan(
+		&i.ProjectID,
+		&i.Status,
+		&i.Name,
+		&i.Color,
+		&i.Position,
+		&i.IsInitial,
+		&i.IsFinal,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const incrementInviteUseCount = `-- name: IncrementInviteUseCount :exec
 UPDATE project_invites
 SET used_count = used_count + 1, updated_at = now()
@@ -1256,20 +1941,241 @@ func (q *Queries) IncrementInviteUseCount(ctx context.Context, id uuid.UUID) err
 	return err
 }
 
+const invalidateUserEmailVerifications = `-- name: InvalidateUserEmailVerifications :exec
+UPDATE email_verifications
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) InvalidateUserEmailVerifications(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, invalidateUserEmailVerifications, userID)
+	return err
+}
+
+const invalidateUserPasswordResets = `-- name: InvalidateUserPasswordResets :exec
+UPDATE password_resets
+SET used_at = now()
+WHERE user_id = $1 AND used_at IS NULL
+`
+
+func (q *Queries) InvalidateUserPasswordResets(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, invalidateUserPasswordResets, userID)
+	return err
+}
+
+const isSystemAdmin = `-- name: IsSystemAdmin :one
+SELECT EXISTS (
+    SELECT 1 FROM system_admins sa
+    JOIN users u ON u.id = sa.user_id
+    WHERE sa.user_id = $1 AND u.active
+) AS is_admin
+`
+
+// System Admin Queries
+func (q *Queries) IsSystemAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	row := q.db.QueryRow(ctx, isSystemAdmin, userID)
+	var is_admin bool
+	err := row.Scan(&is_admin)
+	return is_admin, err
+}
+
+const listActivityEvents = `-- name: ListActivityEvents :many
+SELECT a.id, a.project_id, a.actor_id, a.resource, a.resource_id, a.action, a.changes, a.created_at,
+       u.username AS actor_username, u.first_name AS actor_first_name, u.last_name AS actor_last_name
+FROM activity_events a
+LEFT JOIN users u ON u.id = a.actor_id
+WHERE a.project_id = $1
+  AND ($2::uuid IS NULL OR a.actor_id = $2::uuid)
+  AND ($3::text IS NULL OR a.resource = $3::text)
+  AND ($4::uuid IS NULL OR a.resource_id = $4::uuid)
+  AND ($5::text IS NULL OR a.action = $5::text)
+  AND ($6::timestamptz IS NULL OR a.created_at >= $6::timestamptz)
+  AND ($7::timestamptz IS NULL OR a.created_at < $7::timestamptz)
+  AND ($8::uuid IS NULL
+       OR (a.created_at, a.id) < ($9::timestamptz, $8::uuid))
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT $10
+`
+
+type ListActivityEventsParams struct {
+	ProjectID       uuid.UUID          `json:"projectId"`
+	ActorID         pgtype.UUID        `json:"actorId"`
+	Resource        *string            `json:"resource"`
+	ResourceID      pgtype.UUID        `json:"resourceId"`
+	Action          *string            `json:"action"`
+	Since           pgtype.Timestamptz `json:"since"`
+	Until           pgtype.Timestamptz `json:"until"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	Limit           int32              `json:"limit"`
+}
+
+type ListActivityEventsRow struct {
+	ID             uuid.UUID   `json:"id"`
+	ProjectID      uuid.UUID   `json:"projectId"`
+	ActorID        pgtype.UUID `json:"actorId"`
+	Resource       string      `json:"resource"`
+	ResourceID     pgtype.UUID `json:"resourceId"`
+	Action         string      `json:"action"`
+	Changes        []byte      `json:"changes"`
+	CreatedAt      time.Time   `json:"createdAt"`
+	ActorUsername  *string     `json:"actorUsername"`
+	ActorFirstName *string     `json:"actorFirstName"`
+	ActorLastName  *string     `json:"actorLastName"`
+}
+
+// Every filter is optional; pages are keyed on (created_at, id), newest first.
+func (q *Queries) ListActivityEvents(ctx context.Context, arg ListActivityEventsParams) ([]ListActivityEventsRow, error) {
+	rows, err := q.db.Query(ctx, listActivityEvents,
+		arg.ProjectID,
+		arg.ActorID,
+		arg.Resource,
+		arg.ResourceID,
+		arg.Action,
+		arg.Since,
+		arg.Until,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListActivityEventsRow
+	for rows.Next() {
+		var i ListActivityEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProjectID,
+			&i.ActorID,
+			&i.Resource,
+			&i.ResourceID,
+			&i.Action,
+			&i.Changes,
+			&i.CreatedAt,
+			&i.ActorUsername,
+			&i.ActorFirstName,
+			&i.ActorLastName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listDeletedProjectsByOwner = `-- name: ListDeletedProjectsByOwner :many
+SELECT id, owner_id, name, description, created_at, updated_at, deleted_at, version
+FROM projects
+WHERE owner_id = $1 AND deleted_at IS NOT NULL
+  AND ($2::uuid IS NULL
+       OR (deleted_at, id) < ($3::timestamptz, $2::uuid))
+ORDER BY deleted_at DESC, id DESC
+LIMIT $4
+`
+
+type ListDeletedProjectsByOwnerParams struct {
+	OwnerID         uuid.UUID          `json:"ownerId"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorDeletedAt pgtype.Timestamptz `json:"cursorDeletedAt"`
+	Limit           int32              `json:"limit"`
+}
+
+func (q *Queries) ListDeletedProjectsByOwner(ctx context.Context, arg ListDeletedProjectsByOwnerParams) ([]Project, error) {
+	rows, err := q.db.Query(ctx, listDeletedProjectsByOwner,
+		arg.OwnerID,
+		arg.CursorID,
+		arg.CursorDeletedAt,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Project
+	for rows.Next() {
+		var i Project
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+			&i.Version,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLabelsForTasks = `-- name: ListLabelsForTasks :many
+SELECT tl.task_id, l.id, l.name, l.color
+FROM task_labels tl
+JOIN labels l ON l.id = tl.label_id
+WHERE tl.task_id = ANY($1::uuid[])
+ORDER BY tl.task_id, lower(l.name)
+`
+
+type ListLabelsForTasksRow struct {
+	TaskID uuid.UUID `json:"taskId"`
+	ID     uuid.UUID `json:"id"`
+	Name   string    `json:"name"`
+	Color  string    `json:"color"`
+}
+
+func (q *Queries) ListLabelsForTasks(ctx context.Context, taskIds []uuid.UUID) ([]ListLabelsForTasksRow, error) {
+	rows, err := q.db.Query(ctx, listLabelsForTasks, taskIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListLabelsForTasksRow
+	for rows.Next() {
+		var i ListLabelsForTasksRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.ID,
+			&i.Name,
+			&i.Color,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMessagesByProject = `-- name: ListMessagesByProject :many
 SELECT m.id, m.project_id, m.sender_id, m.content, m.message_type, m.parent_message_id, m.created_at, m.updated_at,
        u.username as sender_username, u.first_name as sender_first_name, u.last_name as sender_last_name, u.avatar_url as sender_avatar_url
 FROM messages m
 JOIN users u ON m.sender_id = u.id
 WHERE m.project_id = $1
-ORDER BY m.created_at DESC
-LIMIT $2 OFFSET $3
+  AND ($2::uuid IS NULL
+       OR (m.created_at, m.id) < ($3::timestamptz, $2::uuid))
+ORDER BY m.created_at DESC, m.id DESC
+LIMIT $4
 `
 
 type ListMessagesByProjectParams struct {
-	ProjectID uuid.UUID `json:"projectId"`
-	Limit     int32     `json:"limit"`
-	Offset    int32     `json:"offset"`
+	ProjectID       uuid.UUID          `json:"projectId"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	Limit           int32              `json:"limit"`
 }
 
 type ListMessagesByProjectRow struct {
@@ -1288,7 +2194,12 @@ type ListMessagesByProjectRow struct {
 }
 
 func (q *Queries) ListMessagesByProject(ctx context.Context, arg ListMessagesByProjectParams) ([]ListMessagesByProjectRow, error) {
-	rows, err := q.db.Query(ctx, listMessagesByProject, arg.ProjectID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listMessagesByProject,
+		arg.ProjectID,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1325,8 +2236,9 @@ SELECT m.id, m.project_id, m.sender_id, m.content, m.message_type, m.parent_mess
        u.username as sender_username, u.first_name as sender_first_name, u.last_name as sender_last_name, u.avatar_url as sender_avatar_url
 FROM messages m
 JOIN users u ON m.sender_id = u.id
-WHERE m.project_id = $1 AND m.id > $2
-ORDER BY m.created_at ASC
+WHERE m.project_id = $1
+  AND (m.created_at, m.id) > (SELECT a.created_at, a.id FROM messages a WHERE a.id = $2)
+ORDER BY m.created_at ASC, m.id ASC
 LIMIT $3
 `
 
@@ -1351,6 +2263,7 @@ type ListMessagesByProjectAfterRow struct {
 	SenderAvatarUrl *string     `json:"senderAvatarUrl"`
 }
 
+// Messages are keyed on (created_at, id); UUIDs alone are not ordered
 func (q *Queries) ListMessagesByProjectAfter(ctx context.Context, arg ListMessagesByProjectAfterParams) ([]ListMessagesByProjectAfterRow, error) {
 	rows, err := q.db.Query(ctx, listMessagesByProjectAfter, arg.ProjectID, arg.ID, arg.Limit)
 	if err != nil {
@@ -1385,12 +2298,14 @@ func (q *Queries) ListMessagesByProjectAfter(ctx context.Context, arg ListMessag
 }
 
 const listProjectInvites = `-- name: ListProjectInvites :many
-SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at
+SELECT id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
 FROM project_invites
-WHERE project_id = $1 AND is_active = true
+WHERE project_id = $1 AND (is_active = true OR recipient_email IS NOT NULL)
 ORDER BY created_at DESC
 `
 
+// Link invites are listed while active; email invites are kept so their
+// pending/accepted/expired state can be shown per recipient
 func (q *Queries) ListProjectInvites(ctx context.Context, projectID uuid.UUID) ([]ProjectInvite, error) {
 	rows, err := q.db.Query(ctx, listProjectInvites, projectID)
 	if err != nil {
@@ -1411,6 +2326,11 @@ func (q *Queries) ListProjectInvites(ctx context.Context, projectID uuid.UUID) (
 			&i.IsActive,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.RecipientEmail,
+			&i.AcceptedAt,
+			&i.AcceptedBy,
+			&i.LastSentAt,
+			&i.SendCount,
 		); err != nil {
 			return nil, err
 		}
@@ -1422,45 +2342,29 @@ func (q *Queries) ListProjectInvites(ctx context.Context, projectID uuid.UUID) (
 	return items, nil
 }
 
-const listProjectMembers = `-- name: ListProjectMembers :many
-SELECT u.id, u.username, u.email, u.first_name, u.last_name, u.avatar_url,
-       pm.joined_at, pm.role
-FROM project_members pm
-JOIN users u ON pm.user_id = u.id
-WHERE pm.project_id = $1
-ORDER BY pm.joined_at
+const listProjectLabels = `-- name: ListProjectLabels :many
+SELECT id, project_id, name, color, created_at, updated_at
+FROM labels
+WHERE project_id = $1
+ORDER BY lower(name)
 `
 
-type ListProjectMembersRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	JoinedAt  time.Time `json:"joinedAt"`
-	Role      string    `json:"role"`
-}
-
-// Canonical model: project_members is single source of truth (includes owner)
-func (q *Queries) ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]ListProjectMembersRow, error) {
-	rows, err := q.db.Query(ctx, listProjectMembers, projectID)
+func (q *Queries) ListProjectLabels(ctx context.Context, projectID uuid.UUID) ([]Label, error) {
+	rows, err := q.db.Query(ctx, listProjectLabels, projectID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListProjectMembersRow
+	var items []Label
 	for rows.Next() {
-		var i ListProjectMembersRow
+		var i Label
 		if err := rows.Scan(
 			&i.ID,
-			&i.Username,
-			&i.Email,
-			&i.FirstName,
-			&i.LastName,
-			&i.AvatarUrl,
-			&i.JoinedAt,
-			&i.Role,
+			&i.ProjectID,
+			&i.Name,
+			&i.Color,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1472,29 +2376,109 @@ func (q *Queries) ListProjectMembers(ctx context.Context, projectID uuid.UUID) (
 	return items, nil
 }
 
-const listProjectsByUser = `-- name: ListProjectsByUser :many
-SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at,
-       u.username AS owner_username,
-       u.email AS owner_email,
-       u.first_name AS owner_first_name,
+const listProjectMemberIDs = `-- name: ListProjectMemberIDs :many
+SELECT user_id FROM project_members
+WHERE project_id = $1
+`
+
+// Includes members of a project in the trash, who still hear about its deletion
+func (q *Queries) ListProjectMemberIDs(ctx context.Context, projectID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listProjectMemberIDs, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectMembers = `-- name: ListProjectMembers :many
+SELECT u.id, u.username, u.email, u.first_name, u.last_name, u.avatar_url,
+       pm.joined_at, pm.role
+FROM project_members pm
+JOIN users u ON pm.user_id = u.id
+WHERE pm.project_id = $1
+ORDER BY pm.joined_at
+`
+
+type ListProjectMembersRow struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName"`
+	AvatarUrl *string   `json:"avatarUrl"`
+	JoinedAt  time.Time `json:"joinedAt"`
+	Role      string    `json:"role"`
+}
+
+// Canonical model: project_members is single source of truth (includes owner)
+func (q *Queries) ListProjectMembers(ctx context.Context, projectID uuid.UUID) ([]ListProjectMembersRow, error) {
+	rows, err := q.db.Query(ctx, listProjectMembers, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectMembersRow
+	for rows.Next() {
+		var i ListProjectMembersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.AvatarUrl,
+			&i.JoinedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listProjectsByUser = `-- name: ListProjectsByUser :many
+SELECT p.id, p.owner_id, p.name, p.description, p.created_at, p.updated_at, p.version,
+       u.username AS owner_username,
+       u.email AS owner_email,
+       u.first_name AS owner_first_name,
        u.last_name AS owner_last_name,
        u.avatar_url AS owner_avatar_url
 FROM projects p
 JOIN users u ON u.id = p.owner_id
-WHERE p.owner_id = $1
+WHERE (p.owner_id = $1
    OR EXISTS (
        SELECT 1
        FROM project_members pm
        WHERE pm.project_id = p.id AND pm.user_id = $1
-   )
-ORDER BY p.created_at DESC
-LIMIT $2 OFFSET $3
+   ))
+  AND p.deleted_at IS NULL
+  AND ($2::uuid IS NULL
+       OR (p.created_at, p.id) < ($3::timestamptz, $2::uuid))
+ORDER BY p.created_at DESC, p.id DESC
+LIMIT $4
 `
 
 type ListProjectsByUserParams struct {
-	OwnerID uuid.UUID `json:"ownerId"`
-	Limit   int32     `json:"limit"`
-	Offset  int32     `json:"offset"`
+	UserID          uuid.UUID          `json:"userId"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	Limit           int32              `json:"limit"`
 }
 
 type ListProjectsByUserRow struct {
@@ -1504,6 +2488,7 @@ type ListProjectsByUserRow struct {
 	Description    *string   `json:"description"`
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
+	Version        int32     `json:"version"`
 	OwnerUsername  string    `json:"ownerUsername"`
 	OwnerEmail     string    `json:"ownerEmail"`
 	OwnerFirstName string    `json:"ownerFirstName"`
@@ -1513,7 +2498,12 @@ type ListProjectsByUserRow struct {
 
 // Fixed: Use EXISTS to avoid duplicates from LEFT JOIN
 func (q *Queries) ListProjectsByUser(ctx context.Context, arg ListProjectsByUserParams) ([]ListProjectsByUserRow, error) {
-	rows, err := q.db.Query(ctx, listProjectsByUser, arg.OwnerID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listProjectsByUser,
+		arg.UserID,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1528,6 +2518,7 @@ func (q *Queries) ListProjectsByUser(ctx context.Context, arg ListProjectsByUser
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 			&i.OwnerUsername,
 			&i.OwnerEmail,
 			&i.OwnerFirstName,
@@ -1544,21 +2535,132 @@ func (q *Queries) ListProjectsByUser(ctx context.Context, arg ListProjectsByUser
 	return items, nil
 }
 
+const listProjectTrash = `-- name: ListProjectTrash :many
+SELECT resource, id, title, deleted_at, task_count
+FROM (
+    SELECT 'sprint'::text AS resource, s.id, s.name AS title, s.deleted_at::timestamptz AS deleted_at,
+           (SELECT COUNT(*) FROM tasks t WHERE t.sprint_id = s.id AND t.deleted_at = s.deleted_at) AS task_count
+    FROM sprints s
+    WHERE s.project_id = $1 AND s.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'task'::text, t.id, coalesce(t.description, ''), t.deleted_at::timestamptz, 0::bigint
+    FROM tasks t
+    LEFT JOIN sprints s ON s.id = t.sprint_id
+    WHERE t.project_id = $1 AND t.deleted_at IS NOT NULL
+      AND (s.deleted_at IS NULL OR s.deleted_at <> t.deleted_at)
+) trash
+WHERE $2::uuid IS NULL
+   OR (deleted_at, id) < ($3::timestamptz, $2::uuid)
+ORDER BY deleted_at DESC, id DESC
+LIMIT $4
+`
+
+type ListProjectTrashParams struct {
+	ProjectID       uuid.UUID          `json:"projectId"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorDeletedAt pgtype.Timestamptz `json:"cursorDeletedAt"`
+	Limit           int32              `json:"limit"`
+}
+
+type ListProjectTrashRow struct {
+	Resource  string    `json:"resource"`
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deletedAt"`
+	TaskCount int64     `json:"taskCount"`
+}
+
+// Sprints and tasks in the project's trash, most recently deleted first.
+// Tasks deleted together with their sprint are counted on the sprint
+// rather than listed, since restoring the sprint brings them back.
+func (q *Queries) ListProjectTrash(ctx context.Context, arg ListProjectTrashParams) ([]ListProjectTrashRow, error) {
+	rows, err := q.db.Query(ctx, listProjectTrash,
+		arg.ProjectID,
+		arg.CursorID,
+		arg.CursorDeletedAt,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListProjectTrashRow
+	for rows.Next() {
+		var i ListProjectTrashRow
+		if err := rows.Scan(
+			&i.Resource,
+			&i.ID,
+			&i.Title,
+			&i.DeletedAt,
+			&i.TaskCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRealtimeEventsSince = `-- name: ListRealtimeEventsSince :many
+SELECT seq, type, data FROM realtime_events
+WHERE project_id = $1 AND seq > $2
+ORDER BY seq
+`
+
+type ListRealtimeEventsSinceParams struct {
+	ProjectID uuid.UUID `json:"projectId"`
+	AfterSeq  int64     `json:"afterSeq"`
+}
+
+type ListRealtimeEventsSinceRow struct {
+	Seq  int64  `json:"seq"`
+	Type string `json:"type"`
+	Data []byte `json:"data"`
+}
+
+func (q *Queries) ListRealtimeEventsSince(ctx context.Context, arg ListRealtimeEventsSinceParams) ([]ListRealtimeEventsSinceRow, error) {
+	rows, err := q.db.Query(ctx, listRealtimeEventsSince, arg.ProjectID, arg.AfterSeq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRealtimeEventsSinceRow
+	for rows.Next() {
+		var i ListRealtimeEventsSinceRow
+		if err := rows.Scan(&i.Seq, &i.Type, &i.Data); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSprintsByProject = `-- name: ListSprintsByProject :many
-SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at,
+SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at, s.version,
        p.owner_id, u.username as owner_username, u.email as owner_email, u.first_name as owner_first_name, u.last_name as owner_last_name
 FROM sprints s
 JOIN projects p ON s.project_id = p.id
 JOIN users u ON p.owner_id = u.id
 WHERE s.project_id = $1
-ORDER BY s.start_date DESC
-LIMIT $2 OFFSET $3
+  AND s.deleted_at IS NULL
+  AND ($2::uuid IS NULL
+       OR (s.start_date, s.created_at, s.id) < ($3::timestamptz, $4::timestamptz, $2::uuid))
+ORDER BY s.start_date DESC, s.created_at DESC, s.id DESC
+LIMIT $5
 `
 
 type ListSprintsByProjectParams struct {
-	ProjectID uuid.UUID `json:"projectId"`
-	Limit     int32     `json:"limit"`
-	Offset    int32     `json:"offset"`
+	ProjectID       uuid.UUID          `json:"projectId"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorStartDate pgtype.Timestamptz `json:"cursorStartDate"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	Limit           int32              `json:"limit"`
 }
 
 type ListSprintsByProjectRow struct {
@@ -1572,6 +2674,7 @@ type ListSprintsByProjectRow struct {
 	IsStarted      bool      `json:"isStarted"`
 	CreatedAt      time.Time `json:"createdAt"`
 	UpdatedAt      time.Time `json:"updatedAt"`
+	Version        int32     `json:"version"`
 	OwnerID        uuid.UUID `json:"ownerId"`
 	OwnerUsername  string    `json:"ownerUsername"`
 	OwnerEmail     string    `json:"ownerEmail"`
@@ -1580,7 +2683,13 @@ type ListSprintsByProjectRow struct {
 }
 
 func (q *Queries) ListSprintsByProject(ctx context.Context, arg ListSprintsByProjectParams) ([]ListSprintsByProjectRow, error) {
-	rows, err := q.db.Query(ctx, listSprintsByProject, arg.ProjectID, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listSprintsByProject,
+		arg.ProjectID,
+		arg.CursorID,
+		arg.CursorStartDate,
+		arg.CursorCreatedAt,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -1599,6 +2708,7 @@ func (q *Queries) ListSprintsByProject(ctx context.Context, arg ListSprintsByPro
 			&i.IsStarted,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 			&i.OwnerID,
 			&i.OwnerUsername,
 			&i.OwnerEmail,
@@ -1615,8 +2725,33 @@ func (q *Queries) ListSprintsByProject(ctx context.Context, arg ListSprintsByPro
 	return items, nil
 }
 
-const listTasksByProject = `-- name: ListTasksByProject :many
-SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.created_at, t.updated_at,
+const listTaskStatusesInUse = `-- name: ListTaskStatusesInUse :many
+SELECT DISTINCT status FROM tasks WHERE project_id = $1 ORDER BY status
+`
+
+// Includes tasks in the trash so that they keep a valid status when restored
+func (q *Queries) ListTaskStatusesInUse(ctx context.Context, projectID uuid.UUID) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listTaskStatusesInUse, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var status int32
+		if err := rows.Scan(&status); err != nil {
+			return nil, err
+		}
+		items = append(items, status)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTasks = `-- name: ListTasks :many
+SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.priority, t.story_points, t.due_date, t.created_at, t.updated_at, t.version,
        u.username as assignee_username, u.first_name as assignee_first_name, u.last_name as assignee_last_name,
        p.owner_id, owner.username as owner_username, owner.email as owner_email, owner.first_name as owner_first_name, owner.last_name as owner_last_name
 FROM tasks t
@@ -1624,25 +2759,78 @@ LEFT JOIN users u ON t.assignee_id = u.id
 JOIN projects p ON t.project_id = p.id
 JOIN users owner ON p.owner_id = owner.id
 WHERE t.project_id = $1
-ORDER BY t.created_at DESC
-LIMIT $2 OFFSET $3
-`
-
-type ListTasksByProjectParams struct {
-	ProjectID uuid.UUID `json:"projectId"`
-	Limit     int32     `json:"limit"`
-	Offset    int32     `json:"offset"`
-}
-
-type ListTasksByProjectRow struct {
+  AND t.deleted_at IS NULL
+  AND ($2::uuid IS NULL OR t.sprint_id = $2::uuid)
+  AND (NOT $3::bool OR t.sprint_id IS NULL)
+  AND ($4::uuid IS NULL OR t.assignee_id = $4::uuid)
+  AND (NOT $5::bool OR t.assignee_id IS NULL)
+  AND ($6::int[] IS NULL OR t.status = ANY($6::int[]))
+  AND ($7::uuid[] IS NULL OR EXISTS (
+        SELECT 1 FROM task_labels tl
+        WHERE tl.task_id = t.id AND tl.label_id = ANY($7::uuid[])))
+  AND ($8::date IS NULL OR t.due_date >= $8::date)
+  AND ($9::date IS NULL OR t.due_date <= $9::date)
+  AND ($10::text IS NULL
+       OR to_tsvector('english', coalesce(t.description, '')) @@ websearch_to_tsquery('english', $10::text))
+  -- Keyset: rows after the cursor in (sort key, created_at, id) order
+  AND ($11::uuid IS NULL OR CASE
+       WHEN $12::text = 'priority' AND $13::bool
+         THEN (t.priority, t.created_at, t.id) < ($14::smallint, $15::timestamptz, $11::uuid)
+       WHEN $12::text = 'priority'
+         THEN (t.priority, t.created_at, t.id) > ($14::smallint, $15::timestamptz, $11::uuid)
+       WHEN $12::text = 'updated' AND $13::bool
+         THEN (t.updated_at, t.created_at, t.id) < ($16::timestamptz, $15::timestamptz, $11::uuid)
+       WHEN $12::text = 'updated'
+         THEN (t.updated_at, t.created_at, t.id) > ($16::timestamptz, $15::timestamptz, $11::uuid)
+       WHEN $13::bool
+         THEN (t.created_at, t.id) < ($15::timestamptz, $11::uuid)
+       ELSE (t.created_at, t.id) > ($15::timestamptz, $11::uuid)
+     END)
+ORDER BY
+  CASE WHEN $12::text = 'priority' AND $13::bool THEN t.priority END DESC,
+  CASE WHEN $12::text = 'priority' AND NOT $13::bool THEN t.priority END ASC,
+  CASE WHEN $12::text = 'updated' AND $13::bool THEN t.updated_at END DESC,
+  CASE WHEN $12::text = 'updated' AND NOT $13::bool THEN t.updated_at END ASC,
+  CASE WHEN $13::bool THEN t.created_at END DESC,
+  CASE WHEN NOT $13::bool THEN t.created_at END ASC,
+  CASE WHEN $13::bool THEN t.id END DESC,
+  CASE WHEN NOT $13::bool THEN t.id END ASC
+LIMIT $17
+`
+
+type ListTasksParams struct {
+	ProjectID       uuid.UUID          `json:"projectId"`
+	SprintID        pgtype.UUID        `json:"sprintId"`
+	Backlog         bool               `json:"backlog"`
+	AssigneeID      pgtype.UUID        `json:"assigneeId"`
+	Unassigned      bool               `json:"unassigned"`
+	Statuses        []int32            `json:"statuses"`
+	LabelIds        []uuid.UUID        `json:"labelIds"`
+	DueAfter        pgtype.Date        `json:"dueAfter"`
+	DueBefore       pgtype.Date        `json:"dueBefore"`
+	Search          *string            `json:"search"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	Sort            string             `json:"sort"`
+	Descending      bool               `json:"descending"`
+	CursorPriority  *int16             `json:"cursorPriority"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	CursorUpdatedAt pgtype.Timestamptz `json:"cursorUpdatedAt"`
+	Limit           int32              `json:"limit"`
+}
+
+type ListTasksRow struct {
 	ID                uuid.UUID   `json:"id"`
 	ProjectID         uuid.UUID   `json:"projectId"`
 	SprintID          pgtype.UUID `json:"sprintId"`
 	AssigneeID        pgtype.UUID `json:"assigneeId"`
 	Description       *string     `json:"description"`
 	Status            int32       `json:"status"`
+	Priority          int16       `json:"priority"`
+	StoryPoints       *int32      `json:"storyPoints"`
+	DueDate           pgtype.Date `json:"dueDate"`
 	CreatedAt         time.Time   `json:"createdAt"`
 	UpdatedAt         time.Time   `json:"updatedAt"`
+	Version           int32       `json:"version"`
 	AssigneeUsername  *string     `json:"assigneeUsername"`
 	AssigneeFirstName *string     `json:"assigneeFirstName"`
 	AssigneeLastName  *string     `json:"assigneeLastName"`
@@ -1653,15 +2841,35 @@ type ListTasksByProjectRow struct {
 	OwnerLastName     string      `json:"ownerLastName"`
 }
 
-func (q *Queries) ListTasksByProject(ctx context.Context, arg ListTasksByProjectParams) ([]ListTasksByProjectRow, error) {
-	rows, err := q.db.Query(ctx, listTasksByProject, arg.ProjectID, arg.Limit, arg.Offset)
+// Every filter is optional: a NULL argument (or false flag) disables it.
+// The search expression matches idx_tasks_search.
+func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]ListTasksRow, error) {
+	rows, err := q.db.Query(ctx, listTasks,
+		arg.ProjectID,
+		arg.SprintID,
+		arg.Backlog,
+		arg.AssigneeID,
+		arg.Unassigned,
+		arg.Statuses,
+		arg.LabelIds,
+		arg.DueAfter,
+		arg.DueBefore,
+		arg.Search,
+		arg.CursorID,
+		arg.Sort,
+		arg.Descending,
+		arg.CursorPriority,
+		arg.CursorCreatedAt,
+		arg.CursorUpdatedAt,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListTasksByProjectRow
+	var items []ListTasksRow
 	for rows.Next() {
-		var i ListTasksByProjectRow
+		var i ListTasksRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.ProjectID,
@@ -1669,8 +2877,12 @@ func (q *Queries) ListTasksByProject(ctx context.Context, arg ListTasksByProject
 			&i.AssigneeID,
 			&i.Description,
 			&i.Status,
+			&i.Priority,
+			&i.StoryPoints,
+			&i.DueDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 			&i.AssigneeUsername,
 			&i.AssigneeFirstName,
 			&i.AssigneeLastName,
@@ -1690,34 +2902,42 @@ func (q *Queries) ListTasksByProject(ctx context.Context, arg ListTasksByProject
 	return items, nil
 }
 
-const listTasksBySprint = `-- name: ListTasksBySprint :many
-SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.created_at, t.updated_at,
+const listTasksByProject = `-- name: ListTasksByProject :many
+SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.priority, t.story_points, t.due_date, t.created_at, t.updated_at, t.version,
        u.username as assignee_username, u.first_name as assignee_first_name, u.last_name as assignee_last_name,
        p.owner_id, owner.username as owner_username, owner.email as owner_email, owner.first_name as owner_first_name, owner.last_name as owner_last_name
 FROM tasks t
 LEFT JOIN users u ON t.assignee_id = u.id
 JOIN projects p ON t.project_id = p.id
 JOIN users owner ON p.owner_id = owner.id
-WHERE t.sprint_id = $1
-ORDER BY t.created_at DESC
-LIMIT $2 OFFSET $3
+WHERE t.project_id = $1
+  AND t.deleted_at IS NULL
+  AND ($2::uuid IS NULL
+       OR (t.created_at, t.id) < ($3::timestamptz, $2::uuid))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT $4
 `
 
-type ListTasksBySprintParams struct {
-	SprintID pgtype.UUID `json:"sprintId"`
-	Limit    int32       `json:"limit"`
-	Offset   int32       `json:"offset"`
+type ListTasksByProjectParams struct {
+	ProjectID       uuid.UUID          `json:"projectId"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	Limit           int32              `json:"limit"`
 }
 
-type ListTasksBySprintRow struct {
+type ListTasksByProjectRow struct {
 	ID                uuid.UUID   `json:"id"`
 	ProjectID         uuid.UUID   `json:"projectId"`
 	SprintID          pgtype.UUID `json:"sprintId"`
 	AssigneeID        pgtype.UUID `json:"assigneeId"`
 	Description       *string     `json:"description"`
 	Status            int32       `json:"status"`
+	Priority          int16       `json:"priority"`
+	StoryPoints       *int32      `json:"storyPoints"`
+	DueDate           pgtype.Date `json:"dueDate"`
 	CreatedAt         time.Time   `json:"createdAt"`
 	UpdatedAt         time.Time   `json:"updatedAt"`
+	Version           int32       `json:"version"`
 	AssigneeUsername  *string     `json:"assigneeUsername"`
 	AssigneeFirstName *string     `json:"assigneeFirstName"`
 	AssigneeLastName  *string     `json:"assigneeLastName"`
@@ -1728,15 +2948,20 @@ type ListTasksBySprintRow struct {
 	OwnerLastName     string      `json:"ownerLastName"`
 }
 
-func (q *Queries) ListTasksBySprint(ctx context.Context, arg ListTasksBySprintParams) ([]ListTasksBySprintRow, error) {
-	rows, err := q.db.Query(ctx, listTasksBySprint, arg.SprintID, arg.Limit, arg.Offset)
+func (q *Queries) ListTasksByProject(ctx context.Context, arg ListTasksByProjectParams) ([]ListTasksByProjectRow, error) {
+	rows, err := q.db.Query(ctx, listTasksByProject,
+		arg.ProjectID,
+		arg.CursorID,
+		arg.CursorCreatedAt,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListTasksBySprintRow
+	var items []ListTasksByProjectRow
 	for rows.Next() {
-		var i ListTasksBySprintRow
+		var i ListTasksByProjectRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.ProjectID,
@@ -1744,8 +2969,12 @@ func (q *Queries) ListTasksBySprint(ctx context.Context, arg ListTasksBySprintPa
 			&i.AssigneeID,
 			&i.Description,
 			&i.Status,
+			&i.Priority,
+			&i.StoryPoints,
+			&i.DueDate,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.Version,
 			&i.AssigneeUsername,
 			&i.AssigneeFirstName,
 			&i.AssigneeLastName,
@@ -1766,31 +2995,35 @@ func (q *Queries) ListTasksBySprint(ctx context.Context, arg ListTasksBySprintPa
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2
+WHERE $1::uuid IS NULL
+   OR (created_at, id) < ($2::timestamptz, $1::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT $3
 `
 
 type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+	CursorID        pgtype.UUID        `json:"cursorId"`
+	CursorCreatedAt pgtype.Timestamptz `json:"cursorCreatedAt"`
+	Limit           int32              `json:"limit"`
 }
 
 type ListUsersRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
 }
 
 func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
-	rows, err := q.db.Query(ctx, listUsers, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listUsers, arg.CursorID, arg.CursorCreatedAt, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -1806,6 +3039,44 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUse
 			&i.LastName,
 			&i.Active,
 			&i.AvatarUrl,
+			&i.EmailVerifiedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWorkflowStates = `-- name: ListWorkflowStates :many
+SELECT project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+FROM workflow_states
+WHERE project_id = $1
+ORDER BY position, status
+`
+
+func (q *Queries) ListWorkflowStates(ctx context.Context, projectID uuid.UUID) ([]WorkflowState, error) {
+	rows, err := q.db.Query(ctx, listWorkflowStates, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkflowState
+	for rows.Next() {
+		var i WorkflowState
+		if err := rows.Scan(
+			&i.ProjectID,
+			&i.Status,
+			&i.Name,
+			&i.Color,
+			&i.Position,
+			&i.IsInitial,
+			&i.IsFinal,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -1819,10 +3090,184 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUse
 	return items, nil
 }
 
+const listWorkflowTransitions = `-- name: ListWorkflowTransitions :many
+SELECT project_id, from_status, to_status
+FROM workflow_transitions
+WHERE project_id = $1
+ORDER BY from_status, to_status
+`
+
+func (q *Queries) ListWorkflowTransitions(ctx context.Context, projectID uuid.UUID) ([]WorkflowTransition, error) {
+	rows, err := q.db.Query(ctx, listWorkflowTransitions, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WorkflowTransition
+	for rows.Next() {
+		var i WorkflowTransition
+		if err := rows.Scan(&i.ProjectID, &i.FromStatus, &i.ToStatus); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockSprint = `-- name: LockSprint :one
+SELECT id, project_id, is_started, is_completed, version
+FROM sprints
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
+`
+
+type LockSprintRow struct {
+	ID          uuid.UUID `json:"id"`
+	ProjectID   uuid.UUID `json:"projectId"`
+	IsStarted   bool      `json:"isStarted"`
+	IsCompleted bool      `json:"isCompleted"`
+	Version     int32     `json:"version"`
+}
+
+// Locks a live sprint for the rest of the transaction, so its state cannot
+// change while a lifecycle decision depends on it
+func (q *Queries) LockSprint(ctx context.Context, id uuid.UUID) (LockSprintRow, error) {
+	row := q.db.QueryRow(ctx, lockSprint, id)
+	var i LockSprintRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.IsStarted,
+		&i.IsCompleted,
+		&i.Version,
+	)
+	return i, err
+}
+
+const markEmailVerified = `-- name: MarkEmailVerified :execrows
+UPDATE users
+SET email_verified_at = now(), updated_at = now()
+WHERE id = $1 AND lower(email) = lower($2::text) AND email_verified_at IS NULL
+`
+
+type MarkEmailVerifiedParams struct {
+	ID    uuid.UUID `json:"id"`
+	Email string    `json:"email"`
+}
+
+// Only verifies the address the token was issued for
+func (q *Queries) MarkEmailVerified(ctx context.Context, arg MarkEmailVerifiedParams) (int64, error) {
+	result, err := q.db.Exec(ctx, markEmailVerified, arg.ID, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const markInviteAccepted = `-- name: MarkInviteAccepted :exec
+UPDATE project_invites
+SET accepted_at = now(), accepted_by = $2, used_count = used_count + 1,
+    is_active = false, updated_at = now()
+WHERE id = $1
+`
+
+type MarkInviteAcceptedParams struct {
+	ID         uuid.UUID   `json:"id"`
+	AcceptedBy pgtype.UUID `json:"acceptedBy"`
+}
+
+// Email invites are single-recipient, so accepting one also deactivates it
+func (q *Queries) MarkInviteAccepted(ctx context.Context, arg MarkInviteAcceptedParams) error {
+	_, err := q.db.Exec(ctx, markInviteAccepted, arg.ID, arg.AcceptedBy)
+	return err
+}
+
+const moveTaskToSprint = `-- name: MoveTaskToSprint :one
+UPDATE tasks
+SET sprint_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($3::int IS NULL OR version = $3::int)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version
+`
+
+type MoveTaskToSprintParams struct {
+	ID              uuid.UUID   `json:"id"`
+	SprintID        pgtype.UUID `json:"sprintId"`
+	ExpectedVersion *int32      `json:"expectedVersion"`
+}
+
+type MoveTaskToSprintRow struct {
+	ID          uuid.UUID   `json:"id"`
+	ProjectID   uuid.UUID   `json:"projectId"`
+	SprintID    pgtype.UUID `json:"sprintId"`
+	AssigneeID  pgtype.UUID `json:"assigneeId"`
+	Description *string     `json:"description"`
+	Status      int32       `json:"status"`
+	Priority    int16       `json:"priority"`
+	StoryPoints *int32      `json:"storyPoints"`
+	DueDate     pgtype.Date `json:"dueDate"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Version     int32       `json:"version"`
+}
+
+// A NULL sprint moves the task to the backlog. With expected_version set, no
+// row is returned if the task changed since it was read
+func (q *Queries) MoveTaskToSprint(ctx context.Context, arg MoveTaskToSprintParams) (MoveTaskToSprintRow, error) {
+	row := q.db.QueryRow(ctx, moveTaskToSprint, arg.ID, arg.SprintID, arg.ExpectedVersion)
+	var i MoveTaskToSprintRow
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.SprintID,
+		&i.AssigneeID,
+		&i.Description,
+		&i.Status,
+		&i.Priority,
+		&i.StoryPoints,
+		&i.DueDate,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const notifyActivityBatch = `-- name: NotifyActivityBatch :exec
+SELECT notify_activity_batch($1::uuid, $2::uuid[])
+`
+
+type NotifyActivityBatchParams struct {
+	ProjectID uuid.UUID   `json:"projectId"`
+	EventIds  []uuid.UUID `json:"eventIds"`
+}
+
+func (q *Queries) NotifyActivityBatch(ctx context.Context, arg NotifyActivityBatchParams) error {
+	_, err := q.db.Exec(ctx, notifyActivityBatch, arg.ProjectID, arg.EventIds)
+	return err
+}
+
+const notifyTasksChanged = `-- name: NotifyTasksChanged :exec
+SELECT notify_tasks_changed($1::uuid, $2::uuid[])
+`
+
+type NotifyTasksChangedParams struct {
+	ProjectID uuid.UUID   `json:"projectId"`
+	TaskIds   []uuid.UUID `json:"taskIds"`
+}
+
+func (q *Queries) NotifyTasksChanged(ctx context.Context, arg NotifyTasksChangedParams) error {
+	_, err := q.db.Exec(ctx, notifyTasksChanged, arg.ProjectID, arg.TaskIds)
+	return err
+}
+
 const projectExists = `-- name: ProjectExists :one
 SELECT EXISTS(
     SELECT 1 FROM projects p
-    WHERE p.id = $1
+    WHERE p.id = $1 AND p.deleted_at IS NULL
 ) as exists
 `
 
@@ -1833,8 +3278,108 @@ func (q *Queries) ProjectExists(ctx context.Context, id uuid.UUID) (bool, error)
 	return exists, err
 }
 
-const removeProjectMember = `-- name: RemoveProjectMember :exec
-DELETE FROM project_members WHERE project_id = $1 AND user_id = $2
+const publishHubEvent = `-- name: PublishHubEvent :exec
+SELECT pg_notify('hub_events', $1::text)
+`
+
+// Fan a hub event out to the NOTIFY listener of every API instance
+func (q *Queries) PublishHubEvent(ctx context.Context, payload string) error {
+	_, err := q.db.Exec(ctx, publishHubEvent, payload)
+	return err
+}
+
+const purgeDeletedProjects = `-- name: PurgeDeletedProjects :execrows
+DELETE FROM projects WHERE deleted_at < $1
+`
+
+// Cascades to everything else in the project
+func (q *Queries) PurgeDeletedProjects(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedProjects, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedSprints = `-- name: PurgeDeletedSprints :execrows
+DELETE FROM sprints WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedSprints(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedSprints, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeDeletedTasks = `-- name: PurgeDeletedTasks :execrows
+DELETE FROM tasks WHERE deleted_at < $1
+`
+
+func (q *Queries) PurgeDeletedTasks(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeDeletedTasks, deletedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recordInviteSent = `-- name: RecordInviteSent :one
+UPDATE project_invites
+SET expires_at = $2, last_sent_at = now(), send_count = send_count + 1, updated_at = now()
+WHERE id = $1
+RETURNING id, project_id, created_by, invite_token, expires_at, max_uses, used_count, is_active, created_at, updated_at, recipient_email, accepted_at, accepted_by, last_sent_at, send_count
+`
+
+type RecordInviteSentParams struct {
+	ID        uuid.UUID `json:"id"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+func (q *Queries) RecordInviteSent(ctx context.Context, arg RecordInviteSentParams) (ProjectInvite, error) {
+	row := q.db.QueryRow(ctx, recordInviteSent, arg.ID, arg.ExpiresAt)
+	var i ProjectInvite
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.CreatedBy,
+		&i.InviteToken,
+		&i.ExpiresAt,
+		&i.MaxUses,
+		&i.UsedCount,
+		&i.IsActive,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RecipientEmail,
+		&i.AcceptedAt,
+		&i.AcceptedBy,
+		&i.LastSentAt,
+		&i.SendCount,
+	)
+	return i, err
+}
+
+const releaseIdempotencyKey = `-- name: ReleaseIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND idempotency_key = $2 AND status IS NULL AND claim_token = $3
+`
+
+type ReleaseIdempotencyKeyParams struct {
+	UserID         uuid.UUID `json:"userId"`
+	IdempotencyKey string    `json:"idempotencyKey"`
+	ClaimToken     uuid.UUID `json:"claimToken"`
+}
+
+// Frees a claim whose request failed, so a retry runs it again, unless
+// another request has taken it over
+func (q *Queries) ReleaseIdempotencyKey(ctx context.Context, arg ReleaseIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, releaseIdempotencyKey, arg.UserID, arg.IdempotencyKey, arg.ClaimToken)
+	return err
+}
+
+const removeProjectMember = `-- name: RemoveProjectMember :execrows
+DELETE FROM project_members WHERE project_id = $1 AND user_id = $2 AND role <> 'owner'
 `
 
 type RemoveProjectMemberParams struct {
@@ -1842,11 +3387,244 @@ type RemoveProjectMemberParams struct {
 	UserID    uuid.UUID `json:"userId"`
 }
 
-func (q *Queries) RemoveProjectMember(ctx context.Context, arg RemoveProjectMemberParams) error {
-	_, err := q.db.Exec(ctx, removeProjectMember, arg.ProjectID, arg.UserID)
+// Never removes the owner; no row is affected then
+func (q *Queries) RemoveProjectMember(ctx context.Context, arg RemoveProjectMemberParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removeProjectMember, arg.ProjectID, arg.UserID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const restoreProject = `-- name: RestoreProject :one
+UPDATE projects
+SET deleted_at = NULL, updated_at = now()
+WHERE id = $1 AND owner_id = $2 AND deleted_at IS NOT NULL
+RETURNING id, owner_id, name, description, created_at, updated_at, deleted_at, version
+`
+
+type RestoreProjectParams struct {
+	ID      uuid.UUID `json:"id"`
+	OwnerID uuid.UUID `json:"ownerId"`
+}
+
+// Only the owner may restore a project, since deleted projects grant no roles
+func (q *Queries) RestoreProject(ctx context.Context, arg RestoreProjectParams) (Project, error) {
+	row := q.db.QueryRow(ctx, restoreProject, arg.ID, arg.OwnerID)
+	var i Project
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const restoreSprint = `-- name: RestoreSprint :one
+WITH target AS (
+    SELECT id, deleted_at FROM sprints
+    WHERE id = $1 AND deleted_at IS NOT NULL
+    FOR UPDATE
+), restored_tasks AS (
+    UPDATE tasks t
+    SET deleted_at = NULL, updated_at = now()
+    FROM target
+    WHERE t.sprint_id = target.id AND t.deleted_at = target.deleted_at
+)
+UPDATE sprints s
+SET deleted_at = NULL, updated_at = now()
+FROM target
+WHERE s.id = target.id
+RETURNING s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at, s.deleted_at, s.version
+`
+
+// Restores the sprint together with the tasks that were deleted with it
+func (q *Queries) RestoreSprint(ctx context.Context, id uuid.UUID) (Sprint, error) {
+	row := q.db.QueryRow(ctx, restoreSprint, id)
+	var i Sprint
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Description,
+		&i.StartDate,
+		&i.EndDate,
+		&i.IsCompleted,
+		&i.IsStarted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const restoreTask = `-- name: RestoreTask :execrows
+UPDATE tasks t
+SET deleted_at = NULL, updated_at = now()
+WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM sprints s
+      WHERE s.id = t.sprint_id AND s.deleted_at IS NOT NULL
+  )
+`
+
+// A task cannot come back into a sprint that is still in the trash
+func (q *Queries) RestoreTask(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreTask, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteProject = `-- name: SoftDeleteProject :execrows
+UPDATE projects SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL
+`
+
+// Moves the project to the trash; its sprints, tasks and messages are kept
+// and become reachable again when the project is restored
+func (q *Queries) SoftDeleteProject(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteProject, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteSprint = `-- name: SoftDeleteSprint :execrows
+WITH deleted AS (
+    UPDATE sprints
+    SET deleted_at = now()
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING id, deleted_at
+), deleted_tasks AS (
+    UPDATE tasks t
+    SET deleted_at = deleted.deleted_at
+    FROM deleted
+    WHERE t.sprint_id = deleted.id AND t.deleted_at IS NULL
+)
+SELECT id FROM deleted
+`
+
+// Moves the sprint and its live tasks to the trash with one shared deleted_at
+func (q *Queries) SoftDeleteSprint(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteSprint, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteTask = `-- name: SoftDeleteTask :execrows
+UPDATE tasks SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) SoftDeleteTask(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteTask, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const touchTask = `-- name: TouchTask :exec
+UPDATE tasks SET updated_at = now() WHERE id = $1
+`
+
+func (q *Queries) TouchTask(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchTask, id)
 	return err
 }
 
+const transferProjectOwnership = `-- name: TransferProjectOwnership :one
+WITH transferred AS (
+    UPDATE projects
+    SET owner_id = $1, updated_at = now()
+    WHERE id = $2
+      AND owner_id = $3
+      AND EXISTS (
+          SELECT 1 FROM project_members
+          WHERE project_id = $2 AND user_id = $1
+      )
+    RETURNING id, owner_id, name, description, created_at, updated_at, version
+), promoted AS (
+    UPDATE project_members
+    SET role = 'owner'
+    WHERE project_id IN (SELECT id FROM transferred) AND user_id = $1
+), demoted AS (
+    INSERT INTO project_members (project_id, user_id, role)
+    SELECT id, $3, 'admin' FROM transferred
+    ON CONFLICT (project_id, user_id) DO UPDATE SET role = 'admin'
+)
+SELECT id, owner_id, name, description, created_at, updated_at, version FROM transferred
+`
+
+type TransferProjectOwnershipParams struct {
+	NewOwnerID     uuid.UUID `json:"newOwnerId"`
+	ProjectID      uuid.UUID `json:"projectId"`
+	CurrentOwnerID uuid.UUID `json:"currentOwnerId"`
+}
+
+type TransferProjectOwnershipRow struct {
+	ID          uuid.UUID `json:"id"`
+	OwnerID     uuid.UUID `json:"ownerId"`
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Version     int32     `json:"version"`
+}
+
+// Single statement so owner_id and both member rows change together or not at all.
+// Only succeeds while current_owner_id still owns the project and new_owner_id is a member.
+func (q *Queries) TransferProjectOwnership(ctx context.Context, arg TransferProjectOwnershipParams) (TransferProjectOwnershipRow, error) {
+	row := q.db.QueryRow(ctx, transferProjectOwnership, arg.NewOwnerID, arg.ProjectID, arg.CurrentOwnerID)
+	var i TransferProjectOwnershipRow
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateLabel = `-- name: UpdateLabel :one
+UPDATE labels
+SET name = $2, color = $3, updated_at = now()
+WHERE id = $1
+RETURNING id, project_id, name, color, created_at, updated_at
+`
+
+type UpdateLabelParams struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Color string    `json:"color"`
+}
+
+func (q *Queries) UpdateLabel(ctx context.Context, arg UpdateLabelParams) (Label, error) {
+	row := q.db.QueryRow(ctx, updateLabel, arg.ID, arg.Name, arg.Color)
+	var i Label
+	err := row.Scan(
+		&i.ID,
+		&i.ProjectID,
+		&i.Name,
+		&i.Color,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateMessage = `-- name: UpdateMessage :one
 UPDATE messages
 SET content = $2, updated_at = now()
@@ -1878,18 +3656,21 @@ func (q *Queries) UpdateMessage(ctx context.Context, arg UpdateMessageParams) (M
 const updateProject = `-- name: UpdateProject :one
 UPDATE projects
 SET name = $2, description = $3, updated_at = now()
-WHERE id = $1
-RETURNING id, owner_id, name, description, created_at, updated_at
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($4::int IS NULL OR version = $4::int)
+RETURNING id, owner_id, name, description, created_at, updated_at, deleted_at, version
 `
 
 type UpdateProjectParams struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Description     *string   `json:"description"`
+	ExpectedVersion *int32    `json:"expectedVersion"`
 }
 
+// With expected_version set, no row is returned if the project changed since it was read
 func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (Project, error) {
-	row := q.db.QueryRow(ctx, updateProject, arg.ID, arg.Name, arg.Description)
+	row := q.db.QueryRow(ctx, updateProject, arg.ID, arg.Name, arg.Description, arg.ExpectedVersion)
 	var i Project
 	err := row.Scan(
 		&i.ID,
@@ -1898,6 +3679,37 @@ func (q *Queries) UpdateProject(ctx context.Context, arg UpdateProjectParams) (P
 		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
+	)
+	return i, err
+}
+
+const updateProjectMemberRole = `-- name: UpdateProjectMemberRole :one
+UPDATE project_members
+SET role = $1::text
+WHERE project_id = $2
+  AND user_id = $3
+  AND role <> 'owner'
+  AND $1::text <> 'owner'
+RETURNING project_id, user_id, role, joined_at
+`
+
+type UpdateProjectMemberRoleParams struct {
+	Role      string    `json:"role"`
+	ProjectID uuid.UUID `json:"projectId"`
+	UserID    uuid.UUID `json:"userId"`
+}
+
+// Never touches the owner row and never grants ownership; use TransferProjectOwnership
+func (q *Queries) UpdateProjectMemberRole(ctx context.Context, arg UpdateProjectMemberRoleParams) (ProjectMember, error) {
+	row := q.db.QueryRow(ctx, updateProjectMemberRole, arg.Role, arg.ProjectID, arg.UserID)
+	var i ProjectMember
+	err := row.Scan(
+		&i.ProjectID,
+		&i.UserID,
+		&i.Role,
+		&i.JoinedAt,
 	)
 	return i, err
 }
@@ -1922,18 +3734,21 @@ func (q *Queries) UpdateRefreshTokenGoogleTokens(ctx context.Context, arg Update
 const updateSprint = `-- name: UpdateSprint :one
 UPDATE sprints
 SET name = $2, description = $3, start_date = $4, end_date = $5, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($6::int IS NULL OR version = $6::int)
+RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version
 `
 
 type UpdateSprintParams struct {
-	ID          uuid.UUID `json:"id"`
-	Name        string    `json:"name"`
-	Description *string   `json:"description"`
-	StartDate   time.Time `json:"startDate"`
-	EndDate     time.Time `json:"endDate"`
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Description     *string   `json:"description"`
+	StartDate       time.Time `json:"startDate"`
+	EndDate         time.Time `json:"endDate"`
+	ExpectedVersion *int32    `json:"expectedVersion"`
 }
 
+// With expected_version set, no row is returned if the sprint changed since it was read
 func (q *Queries) UpdateSprint(ctx context.Context, arg UpdateSprintParams) (Sprint, error) {
 	row := q.db.QueryRow(ctx, updateSprint,
 		arg.ID,
@@ -1941,6 +3756,7 @@ func (q *Queries) UpdateSprint(ctx context.Context, arg UpdateSprintParams) (Spr
 		arg.Description,
 		arg.StartDate,
 		arg.EndDate,
+		arg.ExpectedVersion,
 	)
 	var i Sprint
 	err := row.Scan(
@@ -1954,6 +3770,8 @@ func (q *Queries) UpdateSprint(ctx context.Context, arg UpdateSprintParams) (Spr
 		&i.IsStarted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -1961,18 +3779,33 @@ func (q *Queries) UpdateSprint(ctx context.Context, arg UpdateSprintParams) (Spr
 const updateSprintStatus = `-- name: UpdateSprintStatus :one
 UPDATE sprints
 SET is_started = $2, is_completed = $3, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at
+WHERE id = $1 AND deleted_at IS NULL
+  AND is_started = $4 AND is_completed = $5
+  AND ($6::int IS NULL OR version = $6::int)
+RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version
 `
 
 type UpdateSprintStatusParams struct {
-	ID          uuid.UUID `json:"id"`
-	IsStarted   bool      `json:"isStarted"`
-	IsCompleted bool      `json:"isCompleted"`
+	ID              uuid.UUID `json:"id"`
+	IsStarted       bool      `json:"isStarted"`
+	IsCompleted     bool      `json:"isCompleted"`
+	WasStarted      bool      `json:"wasStarted"`
+	WasCompleted    bool      `json:"wasCompleted"`
+	ExpectedVersion *int32    `json:"expectedVersion"`
 }
 
+// Only moves a sprint still in the state it was read in, so concurrent
+// transitions cannot both apply. With expected_version set, no row is
+// returned if the sprint changed since it was read either.
 func (q *Queries) UpdateSprintStatus(ctx context.Context, arg UpdateSprintStatusParams) (Sprint, error) {
-	row := q.db.QueryRow(ctx, updateSprintStatus, arg.ID, arg.IsStarted, arg.IsCompleted)
+	row := q.db.QueryRow(ctx, updateSprintStatus,
+		arg.ID,
+		arg.IsStarted,
+		arg.IsCompleted,
+		arg.WasStarted,
+		arg.WasCompleted,
+		arg.ExpectedVersion,
+	)
 	var i Sprint
 	err := row.Scan(
 		&i.ID,
@@ -1985,21 +3818,28 @@ func (q *Queries) UpdateSprintStatus(ctx context.Context, arg UpdateSprintStatus
 		&i.IsStarted,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DeletedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const updateTask = `-- name: UpdateTask :one
 UPDATE tasks
-SET description = $2, assignee_id = $3, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, sprint_id, assignee_id, description, status, created_at, updated_at
+SET description = $2, assignee_id = $3, priority = $4, story_points = $5, due_date = $6, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($7::int IS NULL OR version = $7::int)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version
 `
 
 type UpdateTaskParams struct {
-	ID          uuid.UUID   `json:"id"`
-	Description *string     `json:"description"`
-	AssigneeID  pgtype.UUID `json:"assigneeId"`
+	ID              uuid.UUID   `json:"id"`
+	Description     *string     `json:"description"`
+	AssigneeID      pgtype.UUID `json:"assigneeId"`
+	Priority        int16       `json:"priority"`
+	StoryPoints     *int32      `json:"storyPoints"`
+	DueDate         pgtype.Date `json:"dueDate"`
+	ExpectedVersion *int32      `json:"expectedVersion"`
 }
 
 type UpdateTaskRow struct {
@@ -2009,12 +3849,25 @@ type UpdateTaskRow struct {
 	AssigneeID  pgtype.UUID `json:"assigneeId"`
 	Description *string     `json:"description"`
 	Status      int32       `json:"status"`
+	Priority    int16       `json:"priority"`
+	StoryPoints *int32      `json:"storyPoints"`
+	DueDate     pgtype.Date `json:"dueDate"`
 	CreatedAt   time.Time   `json:"createdAt"`
 	UpdatedAt   time.Time   `json:"updatedAt"`
+	Version     int32       `json:"version"`
 }
 
+// With expected_version set, no row is returned if the task changed since it was read
 func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (UpdateTaskRow, error) {
-	row := q.db.QueryRow(ctx, updateTask, arg.ID, arg.Description, arg.AssigneeID)
+	row := q.db.QueryRow(ctx, updateTask,
+		arg.ID,
+		arg.Description,
+		arg.AssigneeID,
+		arg.Priority,
+		arg.StoryPoints,
+		arg.DueDate,
+		arg.ExpectedVersion,
+	)
 	var i UpdateTaskRow
 	err := row.Scan(
 		&i.ID,
@@ -2023,8 +3876,12 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (UpdateT
 		&i.AssigneeID,
 		&i.Description,
 		&i.Status,
+		&i.Priority,
+		&i.StoryPoints,
+		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
@@ -2032,13 +3889,15 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (UpdateT
 const updateTaskStatus = `-- name: UpdateTaskStatus :one
 UPDATE tasks
 SET status = $2, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, sprint_id, assignee_id, description, status, created_at, updated_at
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($3::int IS NULL OR version = $3::int)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version
 `
 
 type UpdateTaskStatusParams struct {
-	ID     uuid.UUID `json:"id"`
-	Status int32     `json:"status"`
+	ID              uuid.UUID `json:"id"`
+	Status          int32     `json:"status"`
+	ExpectedVersion *int32    `json:"expectedVersion"`
 }
 
 type UpdateTaskStatusRow struct {
@@ -2048,12 +3907,17 @@ type UpdateTaskStatusRow struct {
 	AssigneeID  pgtype.UUID `json:"assigneeId"`
 	Description *string     `json:"description"`
 	Status      int32       `json:"status"`
+	Priority    int16       `json:"priority"`
+	StoryPoints *int32      `json:"storyPoints"`
+	DueDate     pgtype.Date `json:"dueDate"`
 	CreatedAt   time.Time   `json:"createdAt"`
 	UpdatedAt   time.Time   `json:"updatedAt"`
+	Version     int32       `json:"version"`
 }
 
+// With expected_version set, no row is returned if the task changed since it was read
 func (q *Queries) UpdateTaskStatus(ctx context.Context, arg UpdateTaskStatusParams) (UpdateTaskStatusRow, error) {
-	row := q.db.QueryRow(ctx, updateTaskStatus, arg.ID, arg.Status)
+	row := q.db.QueryRow(ctx, updateTaskStatus, arg.ID, arg.Status, arg.ExpectedVersion)
 	var i UpdateTaskStatusRow
 	err := row.Scan(
 		&i.ID,
@@ -2062,17 +3926,23 @@ func (q *Queries) UpdateTaskStatus(ctx context.Context, arg UpdateTaskStatusPara
 		&i.AssigneeID,
 		&i.Description,
 		&i.Status,
+		&i.Priority,
+		&i.StoryPoints,
+		&i.DueDate,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Version,
 	)
 	return i, err
 }
 
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
-SET username = $2, email = $3, first_name = $4, last_name = $5, avatar_url = $6, updated_at = now()
+SET username = $2, email = $3, first_name = $4, last_name = $5, avatar_url = $6,
+    email_verified_at = CASE WHEN lower(email) = lower($3) THEN email_verified_at ELSE NULL END,
+    updated_at = now()
 WHERE id = $1
-RETURNING id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at
+RETURNING id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 `
 
 type UpdateUserParams struct {
@@ -2085,17 +3955,19 @@ type UpdateUserParams struct {
 }
 
 type UpdateUserRow struct {
-	ID        uuid.UUID `json:"id"`
-	Username  string    `json:"username"`
-	Email     string    `json:"email"`
-	FirstName string    `json:"firstName"`
-	LastName  string    `json:"lastName"`
-	Active    bool      `json:"active"`
-	AvatarUrl *string   `json:"avatarUrl"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
-}
-
+	ID              uuid.UUID          `json:"id"`
+	Username        string             `json:"username"`
+	Email           string             `json:"email"`
+	FirstName       string             `json:"firstName"`
+	LastName        string             `json:"lastName"`
+	Active          bool               `json:"active"`
+	AvatarUrl       *string            `json:"avatarUrl"`
+	EmailVerifiedAt pgtype.Timestamptz `json:"emailVerifiedAt"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	UpdatedAt       time.Time          `json:"updatedAt"`
+}
+
+// Changing the email address clears its verification
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateUserRow, error) {
 	row := q.db.QueryRow(ctx, updateUser,
 		arg.ID,
@@ -2114,6 +3986,7 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 		&i.LastName,
 		&i.Active,
 		&i.AvatarUrl,
+		&i.EmailVerifiedAt,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -2151,3 +4024,23 @@ func (q *Queries) UpdateUserProfilePicture(ctx context.Context, arg UpdateUserPr
 	_, err := q.db.Exec(ctx, updateUserProfilePicture, arg.ID, arg.ProfilePictureUrl)
 	return err
 }
+
+const workflowTransitionAllowed = `-- name: WorkflowTransitionAllowed :one
+SELECT EXISTS(
+    SELECT 1 FROM workflow_transitions
+    WHERE project_id = $1 AND from_status = $2 AND to_status = $3
+) AS allowed
+`
+
+type WorkflowTransitionAllowedParams struct {
+	ProjectID  uuid.UUID `json:"projectId"`
+	FromStatus int32     `json:"fromStatus"`
+	ToStatus   int32     `json:"toStatus"`
+}
+
+func (q *Queries) WorkflowTransitionAllowed(ctx context.Context, arg WorkflowTransitionAllowedParams) (bool, error) {
+	row := q.db.QueryRow(ctx, workflowTransitionAllowed, arg.ProjectID, arg.FromStatus, arg.ToStatus)
+	var allowed bool
+	err := row.Scan(&allowed)
+	return allowed, err
+}
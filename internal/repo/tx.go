@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrTxUnsupported is returned by InTx when the underlying DBTX cannot begin transactions
+var ErrTxUnsupported = errors.New("repo: database handle does not support transactions")
+
+type txBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// InTx runs fn inside a transaction, committing if it returns nil and rolling
+// back otherwise. Works on a pool, a connection, or (as a savepoint) a transaction.
+func (q *Queries) InTx(ctx context.Context, fn func(*Queries) error) error {
+	beginner, ok := q.db.(txBeginner)
+	if !ok {
+		return ErrTxUnsupported
+	}
+
+	tx, err := beginner.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("repo: begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(q.WithTx(tx)); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("repo: commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package sprints enforces the sprint lifecycle: a sprint is planned, then
+// active, then completed, and a project has at most one active sprint.
+// Completing a sprint carries its unfinished tasks over to another sprint or
+// to the backlog.
+package sprints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"devhive-backend/internal/activity"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// State is where a sprint is in its lifecycle
+type State string
+
+const (
+	StatePlanned   State = "planned"
+	StateActive    State = "active"
+	StateCompleted State = "completed"
+)
+
+// ErrUnknownState is returned for a state name that is not planned, active or completed
+var ErrUnknownState = errors.New("sprints: state must be planned, active or completed")
+
+// ErrTransitionNotAllowed is returned for any move but planned to active or active to completed
+var ErrTransitionNotAllowed = errors.New("sprints: transition not allowed")
+
+// ErrActiveSprintExists is returned when starting a sprint while another sprint of the project is active
+var ErrActiveSprintExists = errors.New("sprints: the project already has an active sprint")
+
+// ErrInvalidCarryOver is returned when the sprint chosen for unfinished tasks cannot take them
+var ErrInvalidCarryOver = errors.New("sprints: unfinished tasks must move to another planned or active sprint of the project")
+
+// TransitionError describes a rejected state change; it wraps ErrTransitionNotAllowed
+type TransitionError struct {
+	From, To State
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("sprints: cannot move a sprint from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrTransitionNotAllowed
+}
+
+// StateOf derives a sprint's state from its stored flags
+func StateOf(isStarted, isCompleted bool) State {
+	switch {
+	case isCompleted:
+		return StateCompleted
+	case isStarted:
+		return StateActive
+	default:
+		return StatePlanned
+	}
+}
+
+// ParseState converts a state name
+func ParseState(s string) (State, error) {
+	switch state := State(s); state {
+	case StatePlanned, StateActive, StateCompleted:
+		return state, nil
+	}
+	return "", ErrUnknownState
+}
+
+// flags returns the stored is_started and is_completed values for s
+func (s State) flags() (isStarted, isCompleted bool) {
+	return s != StatePlanned, s == StateCompleted
+}
+
+// CheckTransition reports whether a sprint may move from one state to another
+func CheckTransition(from, to State) error {
+	if (from == StatePlanned && to == StateActive) || (from == StateActive && to == StateCompleted) {
+		return nil
+	}
+	return &TransitionError{From: from, To: to}
+}
+
+// Change is the outcome of a transition
+type Change struct {
+	Sprint repo.Sprint
+	// Unchanged is set when the sprint already was in the requested state
+	Unchanged bool
+	// CarriedOver lists the unfinished tasks moved out of a completed sprint
+	CarriedOver []uuid.UUID
+	// CarriedTo is the sprint they moved to, or uuid.Nil for the backlog
+	CarriedTo uuid.UUID
+	// ActivityIDs are the activity events written with the change, to be
+	// announced with activity.AnnounceBatch once it is committed
+	ActivityIDs []uuid.UUID
+}
+
+// completion is the activity snapshot of a completed sprint, including
+// where its unfinished tasks went
+type completion struct {
+	repo.GetSprintByIDRow
+	CarriedOverTo    string      `json:"carriedOverTo"` // Sprint ID or "backlog"
+	CarriedOverTasks []uuid.UUID `json:"carriedOverTaskIds"`
+}
+
+// Transition moves sprint to state to in one transaction. Completing it moves
+// its unfinished tasks to next, or to the backlog when next is uuid.Nil, and
+// announces them as one realtime event. The status_changed activity event,
+// which records the carry-over, is written by actorID in the same
+// transaction. Requesting the state the sprint is already in changes
+// nothing. The sprint and next are locked before they are checked, so a
+// concurrent transition of either cannot interleave. With expectedVersion
+// set it fails with pgx.ErrNoRows if the sprint changed since it was read.
+func Transition(ctx context.Context, q *repo.Queries, sprint repo.GetSprintByIDRow, to State, next uuid.UUID, expectedVersion *int32, actorID uuid.UUID) (Change, error) {
+	var change Change
+	err := q.InTx(ctx, func(q *repo.Queries) error {
+		current, err := q.LockSprint(ctx, sprint.ID)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != nil && *expectedVersion != current.Version {
+			return pgx.ErrNoRows
+		}
+
+		from := StateOf(current.IsStarted, current.IsCompleted)
+		if from == to && next == uuid.Nil {
+			change.Unchanged = true
+			return nil
+		}
+		if err := CheckTransition(from, to); err != nil {
+			return err
+		}
+		before, err := q.GetSprintByID(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+		if next != uuid.Nil {
+			if to != StateCompleted {
+				return ErrInvalidCarryOver
+			}
+			if err := checkCarryOverTarget(ctx, q, current, next); err != nil {
+				return err
+			}
+		}
+
+		isStarted, isCompleted := to.flags()
+		updated, err := q.UpdateSprintStatus(ctx, repo.UpdateSprintStatusParams{
+			ID:              current.ID,
+			IsStarted:       isStarted,
+			IsCompleted:     isCompleted,
+			WasStarted:      current.IsStarted,
+			WasCompleted:    current.IsCompleted,
+			ExpectedVersion: expectedVersion,
+		})
+		if isUniqueViolation(err) {
+			return ErrActiveSprintExists
+		}
+		if err != nil {
+			return err
+		}
+		change.Sprint = updated
+		after, err := q.GetSprintByID(ctx, current.ID)
+		if err != nil {
+			return err
+		}
+		event := activity.Event{
+			ProjectID:  current.ProjectID,
+			ActorID:    actorID,
+			Resource:   activity.ResourceSprint,
+			ResourceID: current.ID,
+			Action:     activity.ActionStatusChanged,
+			Before:     before,
+			After:      after,
+		}
+
+		if to == StateCompleted {
+			// Announce the carried-over tasks once rather than per task
+			if err := q.DeferTaskNotifications(ctx); err != nil {
+				return err
+			}
+			change.CarriedTo = next
+			change.CarriedOver, err = q.CarryOverSprintTasks(ctx, repo.CarryOverSprintTasksParams{
+				NextSprintID: pgtype.UUID{Bytes: next, Valid: next != uuid.Nil},
+				SprintID:     pgtype.UUID{Bytes: current.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			if len(change.CarriedOver) > 0 {
+				if err := q.NotifyTasksChanged(ctx, repo.NotifyTasksChangedParams{
+					ProjectID: current.ProjectID,
+					TaskIds:   change.CarriedOver,
+				}); err != nil {
+					return err
+				}
+			}
+
+			// Keep the carry-over in the sprint's history next to the status change
+			carriedTo := "backlog"
+			if next != uuid.Nil {
+				carriedTo = next.String()
+			}
+			event.After = completion{
+				GetSprintByIDRow: after,
+				CarriedOverTo:    carriedTo,
+				CarriedOverTasks: change.CarriedOver,
+			}
+		}
+
+		change.ActivityIDs, err = activity.RecordBatch(ctx, q, current.ProjectID, []activity.Event{event})
+		return err
+	})
+	return change, err
+}
+
+// checkCarryOverTarget locks next and checks that it is a different,
+// unfinished sprint of the same project
+func checkCarryOverTarget(ctx context.Context, q *repo.Queries, sprint repo.LockSprintRow, next uuid.UUID) error {
+	if next == sprint.ID {
+		return ErrInvalidCarryOver
+	}
+	target, err := q.LockSprint(ctx, next)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvalidCarryOver
+	}
+	if err != nil {
+		return fmt.Errorf("sprints: failed to load carry-over sprint: %w", err)
+	}
+	if target.ProjectID != sprint.ProjectID || target.IsCompleted {
+		return ErrInvalidCarryOver
+	}
+	return nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint
+// violation; starting a sprint can only hit idx_sprints_one_active
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,60 @@
+package sprints
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStateOf(t *testing.T) {
+	tests := []struct {
+		isStarted, isCompleted bool
+		want                   State
+	}{
+		{false, false, StatePlanned},
+		{true, false, StateActive},
+		{true, true, StateCompleted},
+		{false, true, StateCompleted},
+	}
+	for _, tt := range tests {
+		if got := StateOf(tt.isStarted, tt.isCompleted); got != tt.want {
+			t.Errorf("StateOf(%v, %v) = %s, want %s", tt.isStarted, tt.isCompleted, got, tt.want)
+		}
+	}
+}
+
+func TestParseState(t *testing.T) {
+	for _, s := range []State{StatePlanned, StateActive, StateCompleted} {
+		if got, err := ParseState(string(s)); err != nil || got != s {
+			t.Errorf("ParseState(%q) = %q, %v", s, got, err)
+		}
+	}
+	for _, s := range []string{"", "started", "Active"} {
+		if _, err := ParseState(s); !errors.Is(err, ErrUnknownState) {
+			t.Errorf("ParseState(%q) error = %v, want ErrUnknownState", s, err)
+		}
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	states := []State{StatePlanned, StateActive, StateCompleted}
+	for _, from := range states {
+		for _, to := range states {
+			allowed := (from == StatePlanned && to == StateActive) || (from == StateActive && to == StateCompleted)
+			err := CheckTransition(from, to)
+			if allowed && err != nil {
+				t.Errorf("CheckTransition(%s, %s) = %v, want nil", from, to, err)
+			}
+			if !allowed && !errors.Is(err, ErrTransitionNotAllowed) {
+				t.Errorf("CheckTransition(%s, %s) = %v, want ErrTransitionNotAllowed", from, to, err)
+			}
+		}
+	}
+}
+
+func TestStateFlags(t *testing.T) {
+	for _, s := range []State{StatePlanned, StateActive, StateCompleted} {
+		if got := StateOf(s.flags()); got != s {
+			t.Errorf("StateOf(%s.flags()) = %s", s, got)
+		}
+	}
+}
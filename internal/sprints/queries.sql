@@ -1,38 +1,103 @@
 -- name: GetSprintByID :one
-SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at,
+SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at, s.version,
        p.owner_id, u.username as owner_username, u.email as owner_email, u.first_name as owner_first_name, u.last_name as owner_last_name
 FROM sprints s
 JOIN projects p ON s.project_id = p.id
 JOIN users u ON p.owner_id = u.id
-WHERE s.id = $1;
+WHERE s.id = $1 AND s.deleted_at IS NULL;
 
 -- name: ListSprintsByProject :many
-SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at,
+SELECT s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at, s.version,
        p.owner_id, u.username as owner_username, u.email as owner_email, u.first_name as owner_first_name, u.last_name as owner_last_name
 FROM sprints s
 JOIN projects p ON s.project_id = p.id
 JOIN users u ON p.owner_id = u.id
-WHERE s.project_id = $1
-ORDER BY s.start_date DESC
-LIMIT $2 OFFSET $3;
+WHERE s.project_id = sqlc.arg(project_id)
+  AND s.deleted_at IS NULL
+  AND (sqlc.narg(cursor_id)::uuid IS NULL
+       OR (s.start_date, s.created_at, s.id) < (sqlc.narg(cursor_start_date)::timestamptz, sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY s.start_date DESC, s.created_at DESC, s.id DESC
+LIMIT sqlc.arg('limit');
 
 -- name: CreateSprint :one
 INSERT INTO sprints (project_id, name, description, start_date, end_date)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at;
+RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version;
 
 -- name: UpdateSprint :one
+-- With expected_version set, no row is returned if the sprint changed since it was read
 UPDATE sprints
 SET name = $2, description = $3, start_date = $4, end_date = $5, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at;
+WHERE id = $1 AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version;
 
 -- name: UpdateSprintStatus :one
+-- Only moves a sprint still in the state it was read in, so concurrent
+-- transitions cannot both apply. With expected_version set, no row is
+-- returned if the sprint changed since it was read either.
 UPDATE sprints
 SET is_started = $2, is_completed = $3, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at;
+WHERE id = $1 AND deleted_at IS NULL
+  AND is_started = sqlc.arg(was_started) AND is_completed = sqlc.arg(was_completed)
+  AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version;
 
--- name: DeleteSprint :exec
-DELETE FROM sprints WHERE id = $1;
+-- name: LockSprint :one
+-- Locks a live sprint for the rest of the transaction, so its state cannot
+-- change while a lifecycle decision depends on it
+SELECT id, project_id, is_started, is_completed, version
+FROM sprints
+WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: CarryOverSprintTasks :many
+-- Moves the sprint's unfinished tasks, those not in a final workflow state,
+-- to the next sprint, or to the backlog when it is NULL
+UPDATE tasks t
+SET sprint_id = sqlc.narg(next_sprint_id)::uuid, updated_at = now()
+WHERE t.sprint_id = sqlc.arg(sprint_id) AND t.deleted_at IS NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM workflow_states ws
+      WHERE ws.project_id = t.project_id AND ws.status = t.status AND ws.is_final
+  )
+RETURNING t.id;
+
+-- name: SoftDeleteSprint :execrows
+-- Moves the sprint and its live tasks to the trash with one shared deleted_at
+WITH deleted AS (
+    UPDATE sprints
+    SET deleted_at = now()
+    WHERE id = $1 AND deleted_at IS NULL
+    RETURNING id, deleted_at
+), deleted_tasks AS (
+    UPDATE tasks t
+    SET deleted_at = deleted.deleted_at
+    FROM deleted
+    WHERE t.sprint_id = deleted.id AND t.deleted_at IS NULL
+)
+SELECT id FROM deleted;
+
+-- name: GetDeletedSprint :one
+SELECT id, project_id, name, description, start_date, end_date, is_completed, is_started, created_at, updated_at, deleted_at, version
+FROM sprints
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: RestoreSprint :one
+-- Restores the sprint together with the tasks that were deleted with it
+WITH target AS (
+    SELECT id, deleted_at FROM sprints
+    WHERE id = $1 AND deleted_at IS NOT NULL
+    FOR UPDATE
+), restored_tasks AS (
+    UPDATE tasks t
+    SET deleted_at = NULL, updated_at = now()
+    FROM target
+    WHERE t.sprint_id = target.id AND t.deleted_at = target.deleted_at
+)
+UPDATE sprints s
+SET deleted_at = NULL, updated_at = now()
+FROM target
+WHERE s.id = target.id
+RETURNING s.id, s.project_id, s.name, s.description, s.start_date, s.end_date, s.is_completed, s.is_started, s.created_at, s.updated_at, s.deleted_at, s.version;
 
@@ -0,0 +1,256 @@
+// Package tasks parses the filters, sort order and search text shared by the
+// REST and gRPC task listings.
+package tasks
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"devhive-backend/internal/pagination"
+	"devhive-backend/internal/planning"
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Sort keys accepted by the listings
+const (
+	SortCreated  = "created"
+	SortUpdated  = "updated"
+	SortPriority = "priority"
+)
+
+// Special filter values
+const (
+	// Backlog selects tasks that are not in any sprint
+	Backlog = "backlog"
+	// Unassigned selects tasks without an assignee
+	Unassigned = "none"
+)
+
+// ErrInvalidFilter is returned for a malformed filter value
+var ErrInvalidFilter = errors.New("tasks: invalid filter")
+
+// Filter narrows and orders a project's task listing. The zero value lists
+// every task, newest first.
+type Filter struct {
+	Statuses   []int32
+	SprintID   pgtype.UUID
+	Backlog    bool
+	AssigneeID pgtype.UUID
+	Unassigned bool
+	LabelIDs   []uuid.UUID
+	DueAfter   pgtype.Date
+	DueBefore  pgtype.Date
+	Search     string
+	Sort       string
+	Ascending  bool
+}
+
+// ParseQuery reads a filter from URL query parameters:
+//
+//	status     status value; repeatable or comma-separated
+//	assignee   user ID, or "none" for unassigned tasks
+//	sprint     sprint ID, or "backlog" for tasks without a sprint
+//	label      label ID; repeatable or comma-separated, matches any
+//	dueAfter   earliest due date (YYYY-MM-DD), inclusive
+//	dueBefore  latest due date (YYYY-MM-DD), inclusive
+//	q          free text matched against the task text
+//	sort       created, updated or priority
+//	order      asc or desc (default desc)
+func ParseQuery(values url.Values) (Filter, error) {
+	var f Filter
+	var err error
+
+	for _, s := range splitValues(values["status"]) {
+		status, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return Filter{}, fmt.Errorf("%w: status %q is not a number", ErrInvalidFilter, s)
+		}
+		f.Statuses = append(f.Statuses, int32(status))
+	}
+	if err = f.SetSprint(values.Get("sprint")); err != nil {
+		return Filter{}, err
+	}
+	if err = f.SetAssignee(values.Get("assignee")); err != nil {
+		return Filter{}, err
+	}
+	if err = f.SetLabels(splitValues(values["label"])); err != nil {
+		return Filter{}, err
+	}
+	if err = f.SetDueRange(values.Get("dueAfter"), values.Get("dueBefore")); err != nil {
+		return Filter{}, err
+	}
+	f.Search = values.Get("q")
+	if err = f.SetSort(values.Get("sort"), values.Get("order")); err != nil {
+		return Filter{}, err
+	}
+	return f, nil
+}
+
+// SetSprint filters by sprint ID, or to the backlog; "" clears the filter
+func (f *Filter) SetSprint(s string) error {
+	f.SprintID, f.Backlog = pgtype.UUID{}, false
+	switch s {
+	case "":
+	case Backlog:
+		f.Backlog = true
+	default:
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: sprint must be a sprint ID or %q", ErrInvalidFilter, Backlog)
+		}
+		f.SprintID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	return nil
+}
+
+// SetAssignee filters by assignee ID, or to unassigned tasks; "" clears the filter
+func (f *Filter) SetAssignee(s string) error {
+	f.AssigneeID, f.Unassigned = pgtype.UUID{}, false
+	switch s {
+	case "":
+	case Unassigned:
+		f.Unassigned = true
+	default:
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: assignee must be a user ID or %q", ErrInvalidFilter, Unassigned)
+		}
+		f.AssigneeID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	return nil
+}
+
+// SetLabels filters to tasks carrying any of the labels
+func (f *Filter) SetLabels(ids []string) error {
+	f.LabelIDs = nil
+	for _, s := range ids {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return fmt.Errorf("%w: label %q is not a label ID", ErrInvalidFilter, s)
+		}
+		f.LabelIDs = append(f.LabelIDs, id)
+	}
+	return nil
+}
+
+// SetDueRange filters by an inclusive due-date range; either end may be ""
+func (f *Filter) SetDueRange(after, before string) error {
+	var err error
+	if f.DueAfter, err = planning.ParseDueDate(&after); err != nil {
+		return fmt.Errorf("%w: dueAfter must be formatted YYYY-MM-DD", ErrInvalidFilter)
+	}
+	if f.DueBefore, err = planning.ParseDueDate(&before); err != nil {
+		return fmt.Errorf("%w: dueBefore must be formatted YYYY-MM-DD", ErrInvalidFilter)
+	}
+	if f.DueAfter.Valid && f.DueBefore.Valid && f.DueAfter.Time.After(f.DueBefore.Time) {
+		return fmt.Errorf("%w: dueAfter is later than dueBefore", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// SetSort sets the sort key and order; "" selects created and desc
+func (f *Filter) SetSort(sort, order string) error {
+	switch sort {
+	case "":
+		f.Sort = SortCreated
+	case SortCreated, SortUpdated, SortPriority:
+		f.Sort = sort
+	default:
+		return fmt.Errorf("%w: sort must be one of created, updated, priority", ErrInvalidFilter)
+	}
+	switch strings.ToLower(order) {
+	case "", "desc":
+		f.Ascending = false
+	case "asc":
+		f.Ascending = true
+	default:
+		return fmt.Errorf("%w: order must be asc or desc", ErrInvalidFilter)
+	}
+	return nil
+}
+
+// Params returns the ListTasks arguments for one page of a project's tasks.
+// The page cursor must have been issued for the same sort.
+func (f Filter) Params(projectID uuid.UUID, page pagination.Page) (repo.ListTasksParams, error) {
+	params := repo.ListTasksParams{
+		ProjectID:  projectID,
+		SprintID:   f.SprintID,
+		Backlog:    f.Backlog,
+		AssigneeID: f.AssigneeID,
+		Unassigned: f.Unassigned,
+		Statuses:   f.Statuses,
+		LabelIds:   f.LabelIDs,
+		DueAfter:   f.DueAfter,
+		DueBefore:  f.DueBefore,
+		Sort:       f.Sort,
+		Descending: !f.Ascending,
+		Limit:      page.FetchLimit(),
+	}
+	if params.Sort == "" {
+		params.Sort = SortCreated
+	}
+	if search := strings.TrimSpace(f.Search); search != "" {
+		params.Search = &search
+	}
+
+	if page.Cursor == nil {
+		return params, nil
+	}
+	params.CursorID, params.CursorCreatedAt = page.Keyset()
+
+	// The cursor key is "<sort>:<value>" for sorts other than created
+	sort, value, _ := strings.Cut(page.Cursor.Key, ":")
+	if sort == "" {
+		sort = SortCreated
+	}
+	if sort != params.Sort {
+		return repo.ListTasksParams{}, fmt.Errorf("%w: cursor was issued for a different sort", ErrInvalidFilter)
+	}
+	switch sort {
+	case SortPriority:
+		priority, err := strconv.ParseInt(value, 10, 16)
+		if err != nil {
+			return repo.ListTasksParams{}, fmt.Errorf("%w: %v", ErrInvalidFilter, pagination.ErrInvalidCursor)
+		}
+		p := int16(priority)
+		params.CursorPriority = &p
+	case SortUpdated:
+		updatedAt, err := pagination.ParseTimeKey(value)
+		if err != nil {
+			return repo.ListTasksParams{}, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+		params.CursorUpdatedAt = updatedAt
+	}
+	return params, nil
+}
+
+// Cursor returns the position of a listed task under the filter's sort
+func (f Filter) Cursor(task repo.ListTasksRow) pagination.Cursor {
+	c := pagination.Cursor{CreatedAt: task.CreatedAt, ID: task.ID}
+	switch f.Sort {
+	case SortPriority:
+		c.Key = SortPriority + ":" + strconv.Itoa(int(task.Priority))
+	case SortUpdated:
+		c.Key = SortUpdated + ":" + pagination.TimeKey(task.UpdatedAt)
+	}
+	return c
+}
+
+// splitValues flattens repeated and comma-separated query values
+func splitValues(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
@@ -1,54 +1,231 @@
 -- name: GetTaskByID :one
-SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.created_at, t.updated_at,
+SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.priority, t.story_points, t.due_date, t.created_at, t.updated_at, t.version,
        u.username as assignee_username, u.first_name as assignee_first_name, u.last_name as assignee_last_name,
        p.owner_id, owner.username as owner_username, owner.email as owner_email, owner.first_name as owner_first_name, owner.last_name as owner_last_name
 FROM tasks t
 LEFT JOIN users u ON t.assignee_id = u.id
 JOIN projects p ON t.project_id = p.id
 JOIN users owner ON p.owner_id = owner.id
-WHERE t.id = $1;
+WHERE t.id = $1 AND t.deleted_at IS NULL;
 
 -- name: ListTasksByProject :many
-SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.created_at, t.updated_at,
+SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.priority, t.story_points, t.due_date, t.created_at, t.updated_at, t.version,
        u.username as assignee_username, u.first_name as assignee_first_name, u.last_name as assignee_last_name,
        p.owner_id, owner.username as owner_username, owner.email as owner_email, owner.first_name as owner_first_name, owner.last_name as owner_last_name
 FROM tasks t
 LEFT JOIN users u ON t.assignee_id = u.id
 JOIN projects p ON t.project_id = p.id
 JOIN users owner ON p.owner_id = owner.id
-WHERE t.project_id = $1
-ORDER BY t.created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE t.project_id = sqlc.arg(project_id)
+  AND t.deleted_at IS NULL
+  AND (sqlc.narg(cursor_id)::uuid IS NULL
+       OR (t.created_at, t.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid))
+ORDER BY t.created_at DESC, t.id DESC
+LIMIT sqlc.arg('limit');
 
--- name: ListTasksBySprint :many
-SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.created_at, t.updated_at,
+-- name: ListTasks :many
+-- Every filter is optional: a NULL argument (or false flag) disables it.
+-- The search expression matches idx_tasks_search.
+SELECT t.id, t.project_id, t.sprint_id, t.assignee_id, t.description, t.status, t.priority, t.story_points, t.due_date, t.created_at, t.updated_at, t.version,
        u.username as assignee_username, u.first_name as assignee_first_name, u.last_name as assignee_last_name,
        p.owner_id, owner.username as owner_username, owner.email as owner_email, owner.first_name as owner_first_name, owner.last_name as owner_last_name
 FROM tasks t
 LEFT JOIN users u ON t.assignee_id = u.id
 JOIN projects p ON t.project_id = p.id
 JOIN users owner ON p.owner_id = owner.id
-WHERE t.sprint_id = $1
-ORDER BY t.created_at DESC
-LIMIT $2 OFFSET $3;
+WHERE t.project_id = sqlc.arg(project_id)
+  AND t.deleted_at IS NULL
+  AND (sqlc.narg(sprint_id)::uuid IS NULL OR t.sprint_id = sqlc.narg(sprint_id)::uuid)
+  AND (NOT sqlc.arg(backlog)::bool OR t.sprint_id IS NULL)
+  AND (sqlc.narg(assignee_id)::uuid IS NULL OR t.assignee_id = sqlc.narg(assignee_id)::uuid)
+  AND (NOT sqlc.arg(unassigned)::bool OR t.assignee_id IS NULL)
+  AND (sqlc.narg(statuses)::int[] IS NULL OR t.status = ANY(sqlc.narg(statuses)::int[]))
+  AND (sqlc.narg(label_ids)::uuid[] IS NULL OR EXISTS (
+        SELECT 1 FROM task_labels tl
+        WHERE tl.task_id = t.id AND tl.label_id = ANY(sqlc.narg(label_ids)::uuid[])))
+  AND (sqlc.narg(due_after)::date IS NULL OR t.due_date >= sqlc.narg(due_after)::date)
+  AND (sqlc.narg(due_before)::date IS NULL OR t.due_date <= sqlc.narg(due_before)::date)
+  AND (sqlc.narg(search)::text IS NULL
+       OR to_tsvector('english', coalesce(t.description, '')) @@ websearch_to_tsquery('english', sqlc.narg(search)::text))
+  -- Keyset: rows after the cursor in (sort key, created_at, id) order
+  AND (sqlc.narg(cursor_id)::uuid IS NULL OR CASE
+       WHEN sqlc.arg(sort)::text = 'priority' AND sqlc.arg(descending)::bool
+         THEN (t.priority, t.created_at, t.id) < (sqlc.narg(cursor_priority)::smallint, sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+       WHEN sqlc.arg(sort)::text = 'priority'
+         THEN (t.priority, t.created_at, t.id) > (sqlc.narg(cursor_priority)::smallint, sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+       WHEN sqlc.arg(sort)::text = 'updated' AND sqlc.arg(descending)::bool
+         THEN (t.updated_at, t.created_at, t.id) < (sqlc.narg(cursor_updated_at)::timestamptz, sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+       WHEN sqlc.arg(sort)::text = 'updated'
+         THEN (t.updated_at, t.created_at, t.id) > (sqlc.narg(cursor_updated_at)::timestamptz, sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+       WHEN sqlc.arg(descending)::bool
+         THEN (t.created_at, t.id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+       ELSE (t.created_at, t.id) > (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+     END)
+ORDER BY
+  CASE WHEN sqlc.arg(sort)::text = 'priority' AND sqlc.arg(descending)::bool THEN t.priority END DESC,
+  CASE WHEN sqlc.arg(sort)::text = 'priority' AND NOT sqlc.arg(descending)::bool THEN t.priority END ASC,
+  CASE WHEN sqlc.arg(sort)::text = 'updated' AND sqlc.arg(descending)::bool THEN t.updated_at END DESC,
+  CASE WHEN sqlc.arg(sort)::text = 'updated' AND NOT sqlc.arg(descending)::bool THEN t.updated_at END ASC,
+  CASE WHEN sqlc.arg(descending)::bool THEN t.created_at END DESC,
+  CASE WHEN NOT sqlc.arg(descending)::bool THEN t.created_at END ASC,
+  CASE WHEN sqlc.arg(descending)::bool THEN t.id END DESC,
+  CASE WHEN NOT sqlc.arg(descending)::bool THEN t.id END ASC
+LIMIT sqlc.arg('limit');
 
 -- name: CreateTask :one
-INSERT INTO tasks (project_id, sprint_id, assignee_id, description, status)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, project_id, sprint_id, assignee_id, description, status, created_at, updated_at;
+INSERT INTO tasks (project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version;
 
 -- name: UpdateTask :one
+-- With expected_version set, no row is returned if the task changed since it was read
 UPDATE tasks
-SET description = $2, assignee_id = $3, updated_at = now()
-WHERE id = $1
-RETURNING id, project_id, sprint_id, assignee_id, description, status, created_at, updated_at;
+SET description = $2, assignee_id = $3, priority = $4, story_points = $5, due_date = $6, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version;
 
 -- name: UpdateTaskStatus :one
+-- With expected_version set, no row is returned if the task changed since it was read
 UPDATE tasks
 SET status = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version;
+
+-- name: MoveTaskToSprint :one
+-- A NULL sprint moves the task to the backlog. With expected_version set, no
+-- row is returned if the task changed since it was read
+UPDATE tasks
+SET sprint_id = $2, updated_at = now()
+WHERE id = $1 AND deleted_at IS NULL
+  AND (sqlc.narg(expected_version)::int IS NULL OR version = sqlc.narg(expected_version)::int)
+RETURNING id, project_id, sprint_id, assignee_id, description, status, priority, story_points, due_date, created_at, updated_at, version;
+
+-- name: SoftDeleteTask :execrows
+UPDATE tasks SET deleted_at = now() WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: GetDeletedTask :one
+SELECT id, project_id, sprint_id, description, status, deleted_at
+FROM tasks
+WHERE id = $1 AND deleted_at IS NOT NULL;
+
+-- name: RestoreTask :execrows
+-- A task cannot come back into a sprint that is still in the trash
+UPDATE tasks t
+SET deleted_at = NULL, updated_at = now()
+WHERE t.id = $1 AND t.deleted_at IS NOT NULL
+  AND NOT EXISTS (
+      SELECT 1 FROM sprints s
+      WHERE s.id = t.sprint_id AND s.deleted_at IS NOT NULL
+  );
+
+-- name: ListWorkflowStates :many
+SELECT project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+FROM workflow_states
+WHERE project_id = $1
+ORDER BY position, status;
+
+-- name: ListWorkflowTransitions :many
+SELECT project_id, from_status, to_status
+FROM workflow_transitions
+WHERE project_id = $1
+ORDER BY from_status, to_status;
+
+-- name: GetWorkflowState :one
+SELECT project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+FROM workflow_states
+WHERE project_id = $1 AND status = $2;
+
+-- name: GetInitialWorkflowState :one
+SELECT project_id, status, name, color, position, is_initial, is_final, created_at, updated_at
+FROM workflow_states
+WHERE project_id = $1 AND is_initial;
+
+-- name: WorkflowTransitionAllowed :one
+SELECT EXISTS(
+    SELECT 1 FROM workflow_transitions
+    WHERE project_id = $1 AND from_status = $2 AND to_status = $3
+) AS allowed;
+
+-- name: CountWorkflowStates :one
+SELECT COUNT(*) FROM workflow_states WHERE project_id = $1;
+
+-- name: ListTaskStatusesInUse :many
+-- Includes tasks in the trash so that they keep a valid status when restored
+SELECT DISTINCT status FROM tasks WHERE project_id = $1 ORDER BY status;
+
+-- name: DeleteWorkflowTransitions :exec
+DELETE FROM workflow_transitions WHERE project_id = $1;
+
+-- name: DeleteWorkflowStates :exec
+DELETE FROM workflow_states WHERE project_id = $1;
+
+-- name: CreateWorkflowState :one
+INSERT INTO workflow_states (project_id, status, name, color, position, is_initial, is_final)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING project_id, status, name, color, position, is_initial, is_final, created_at, updated_at;
+
+-- name: CreateWorkflowTransition :exec
+INSERT INTO workflow_transitions (project_id, from_status, to_status)
+VALUES ($1, $2, $3);
+
+-- name: ListProjectLabels :many
+SELECT id, project_id, name, color, created_at, updated_at
+FROM labels
+WHERE project_id = $1
+ORDER BY lower(name);
+
+-- name: GetLabelByID :one
+SELECT id, project_id, name, color, created_at, updated_at
+FROM labels
+WHERE id = $1;
+
+-- name: CreateLabel :one
+INSERT INTO labels (project_id, name, color)
+VALUES ($1, $2, $3)
+RETURNING id, project_id, name, color, created_at, updated_at;
+
+-- name: UpdateLabel :one
+UPDATE labels
+SET name = $2, color = $3, updated_at = now()
 WHERE id = $1
-RETURNING id, project_id, sprint_id, assignee_id, description, status, created_at, updated_at;
+RETURNING id, project_id, name, color, created_at, updated_at;
+
+-- name: DeleteLabel :exec
+DELETE FROM labels WHERE id = $1;
+
+-- name: CountProjectLabels :one
+-- Counts how many of the given label IDs belong to the project
+SELECT COUNT(*) FROM labels
+WHERE project_id = sqlc.arg(project_id) AND id = ANY(sqlc.arg(label_ids)::uuid[]);
+
+-- name: ClearTaskLabels :exec
+DELETE FROM task_labels WHERE task_id = $1;
+
+-- name: AddTaskLabels :exec
+-- Only attaches labels from the task's own project
+INSERT INTO task_labels (task_id, label_id)
+SELECT t.id, l.id
+FROM tasks t
+JOIN labels l ON l.project_id = t.project_id
+WHERE t.id = sqlc.arg(task_id) AND l.id = ANY(sqlc.arg(label_ids)::uuid[])
+ON CONFLICT DO NOTHING;
+
+-- name: TouchTask :exec
+UPDATE tasks SET updated_at = now() WHERE id = $1;
+
+-- name: ListLabelsForTasks :many
+SELECT tl.task_id, l.id, l.name, l.color
+FROM task_labels tl
+JOIN labels l ON l.id = tl.label_id
+WHERE tl.task_id = ANY(sqlc.arg(task_ids)::uuid[])
+ORDER BY tl.task_id, lower(l.name);
 
--- name: DeleteTask :exec
-DELETE FROM tasks WHERE id = $1;
+-- name: DeferTaskNotifications :exec
+-- Holds back per-task cache invalidations until the end of the transaction;
+-- NotifyTasksChanged then sends one for all of them
+SELECT set_config('devhive.defer_task_notify', 'on', true);
 
+-- name: NotifyTasksChanged :exec
+SELECT notify_tasks_changed(sqlc.arg(project_id)::uuid, sqlc.arg(task_ids)::uuid[]);
@@ -0,0 +1,31 @@
+-- name: ListProjectTrash :many
+-- Sprints and tasks in the project's trash, most recently deleted first.
+-- Tasks deleted together with their sprint are counted on the sprint
+-- rather than listed, since restoring the sprint brings them back.
+SELECT resource, id, title, deleted_at, task_count
+FROM (
+    SELECT 'sprint'::text AS resource, s.id, s.name AS title, s.deleted_at::timestamptz AS deleted_at,
+           (SELECT COUNT(*) FROM tasks t WHERE t.sprint_id = s.id AND t.deleted_at = s.deleted_at) AS task_count
+    FROM sprints s
+    WHERE s.project_id = sqlc.arg(project_id) AND s.deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'task'::text, t.id, coalesce(t.description, ''), t.deleted_at::timestamptz, 0::bigint
+    FROM tasks t
+    LEFT JOIN sprints s ON s.id = t.sprint_id
+    WHERE t.project_id = sqlc.arg(project_id) AND t.deleted_at IS NOT NULL
+      AND (s.deleted_at IS NULL OR s.deleted_at <> t.deleted_at)
+) trash
+WHERE sqlc.narg(cursor_id)::uuid IS NULL
+   OR (deleted_at, id) < (sqlc.narg(cursor_deleted_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+ORDER BY deleted_at DESC, id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: PurgeDeletedTasks :execrows
+DELETE FROM tasks WHERE deleted_at < $1;
+
+-- name: PurgeDeletedSprints :execrows
+DELETE FROM sprints WHERE deleted_at < $1;
+
+-- name: PurgeDeletedProjects :execrows
+-- Cascades to everything else in the project
+DELETE FROM projects WHERE deleted_at < $1;
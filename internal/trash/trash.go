@@ -0,0 +1,67 @@
+// Package trash permanently removes soft-deleted projects, sprints and tasks
+// once their retention period has passed.
+package trash
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Store is the subset of repo.Queries used by the purge job
+type Store interface {
+	PurgeDeletedTasks(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	PurgeDeletedSprints(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+	PurgeDeletedProjects(ctx context.Context, deletedAt pgtype.Timestamptz) (int64, error)
+}
+
+// Purge removes everything deleted before the cutoff. Tasks go first so that
+// tasks deleted with their sprint are not detached by ON DELETE SET NULL.
+func Purge(ctx context.Context, store Store, cutoff time.Time) error {
+	before := pgtype.Timestamptz{Time: cutoff, Valid: true}
+
+	tasks, err := store.PurgeDeletedTasks(ctx, before)
+	if err != nil {
+		return err
+	}
+	sprints, err := store.PurgeDeletedSprints(ctx, before)
+	if err != nil {
+		return err
+	}
+	projects, err := store.PurgeDeletedProjects(ctx, before)
+	if err != nil {
+		return err
+	}
+
+	if tasks+sprints+projects > 0 {
+		log.Printf("trash: purged %d projects, %d sprints and %d tasks deleted before %s",
+			projects, sprints, tasks, cutoff.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// StartPurger runs Purge every interval until ctx is canceled, removing
+// items that have been in the trash for longer than retention
+func StartPurger(ctx context.Context, store Store, retention, interval time.Duration) {
+	if retention <= 0 || interval <= 0 {
+		log.Println("trash: purge job disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := Purge(ctx, store, time.Now().Add(-retention)); err != nil {
+				log.Printf("trash: purge failed: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -1,60 +1,72 @@
 -- name: GetUserByID :one
-SELECT id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE id = $1;
 
 -- name: GetUserByIDWithPassword :one
-SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE id = $1;
 
 -- name: GetUserByUsername :one
-SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE lower(username) = lower($1);
 
 -- name: GetUserByEmail :one
-SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, password_h, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE lower(email) = lower($1);
 
 -- name: CreateUser :one
 INSERT INTO users (username, email, password_h, first_name, last_name)
 VALUES ($1, $2, $3, $4, $5)
-RETURNING id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at;
+RETURNING id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at;
 
 -- name: UpdateUser :one
+-- Changing the email address clears its verification
 UPDATE users
-SET username = $2, email = $3, first_name = $4, last_name = $5, avatar_url = $6, updated_at = now()
+SET username = $2, email = $3, first_name = $4, last_name = $5, avatar_url = $6,
+    email_verified_at = CASE WHEN lower(email) = lower($3) THEN email_verified_at ELSE NULL END,
+    updated_at = now()
 WHERE id = $1
-RETURNING id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at;
+RETURNING id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at;
 
 -- name: UpdateUserPassword :exec
 UPDATE users
 SET password_h = $2, updated_at = now()
 WHERE id = $1;
 
+-- name: MarkEmailVerified :execrows
+-- Only verifies the address the token was issued for
+UPDATE users
+SET email_verified_at = now(), updated_at = now()
+WHERE id = $1 AND lower(email) = lower(sqlc.arg(email)::text) AND email_verified_at IS NULL;
+
 -- name: DeactivateUser :exec
 UPDATE users
 SET active = false, updated_at = now()
 WHERE id = $1;
 
 -- name: ListUsers :many
-SELECT id, username, email, first_name, last_name, active, avatar_url, created_at, updated_at
+SELECT id, username, email, first_name, last_name, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
-ORDER BY created_at DESC
-LIMIT $1 OFFSET $2;
+WHERE sqlc.narg(cursor_id)::uuid IS NULL
+   OR (created_at, id) < (sqlc.narg(cursor_created_at)::timestamptz, sqlc.narg(cursor_id)::uuid)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit');
 
 -- OAuth User Queries
 -- name: GetUserByGoogleID :one
-SELECT id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, created_at, updated_at
+SELECT id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, email_verified_at, created_at, updated_at
 FROM users
 WHERE google_id = $1;
 
 -- name: CreateOAuthUser :one
-INSERT INTO users (username, email, first_name, last_name, auth_provider, google_id, profile_picture_url)
-VALUES ($1, $2, $3, $4, $5, $6, $7)
-RETURNING id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, created_at, updated_at;
+-- Google has already verified the email address
+INSERT INTO users (username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, email_verified_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+RETURNING id, username, email, first_name, last_name, auth_provider, google_id, profile_picture_url, active, avatar_url, email_verified_at, created_at, updated_at;
 
 -- name: UpdateUserProfilePicture :exec
 UPDATE users
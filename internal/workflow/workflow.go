@@ -0,0 +1,294 @@
+// Package workflow validates task status changes against a project's
+// configured workflow states and transitions.
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrUnknownStatus is returned when a status is not a state of the project's workflow
+var ErrUnknownStatus = errors.New("workflow: unknown status")
+
+// ErrTransitionNotAllowed is returned when the workflow has no edge between two states
+var ErrTransitionNotAllowed = errors.New("workflow: transition not allowed")
+
+// ErrInvalidDefinition is returned when a replacement workflow fails validation
+var ErrInvalidDefinition = errors.New("workflow: invalid definition")
+
+// TransitionError describes a rejected status change; it wraps ErrTransitionNotAllowed
+type TransitionError struct {
+	From, To         int32
+	FromName, ToName string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("workflow: cannot move from %q to %q", e.FromName, e.ToName)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrTransitionNotAllowed
+}
+
+// Store is the subset of *repo.Queries used to check statuses and transitions
+type Store interface {
+	GetWorkflowState(ctx context.Context, arg repo.GetWorkflowStateParams) (repo.WorkflowState, error)
+	WorkflowTransitionAllowed(ctx context.Context, arg repo.WorkflowTransitionAllowedParams) (bool, error)
+	CountWorkflowStates(ctx context.Context, projectID uuid.UUID) (int64, error)
+}
+
+// State is a named workflow state keyed by the integer stored in tasks.status
+type State struct {
+	Status    int32  `json:"status"`
+	Name      string `json:"name"`
+	Color     string `json:"color"`
+	Position  int32  `json:"position"`
+	IsInitial bool   `json:"isInitial"`
+	IsFinal   bool   `json:"isFinal"`
+}
+
+// Transition is an allowed move between two states
+type Transition struct {
+	From int32 `json:"from"`
+	To   int32 `json:"to"`
+}
+
+// Definition is a project's complete workflow
+type Definition struct {
+	States      []State      `json:"states"`
+	Transitions []Transition `json:"transitions"`
+}
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// ValidColor reports whether color has the #RRGGBB form used for workflow
+// states and labels
+func ValidColor(color string) bool {
+	return colorPattern.MatchString(color)
+}
+
+// ValidateStatus checks that status is a state of the project's workflow.
+// Projects without a workflow accept any status.
+func ValidateStatus(ctx context.Context, store Store, projectID uuid.UUID, status int32) error {
+	_, err := loadState(ctx, store, projectID, status)
+	return err
+}
+
+// CheckTransition checks that the workflow allows moving a task from one
+// status to another. Staying in the same state is always allowed.
+func CheckTransition(ctx context.Context, store Store, projectID uuid.UUID, from, to int32) error {
+	if from == to {
+		return nil
+	}
+
+	target, err := loadState(ctx, store, projectID, to)
+	if err != nil || target == nil {
+		return err
+	}
+
+	allowed, err := store.WorkflowTransitionAllowed(ctx, repo.WorkflowTransitionAllowedParams{
+		ProjectID:  projectID,
+		FromStatus: from,
+		ToStatus:   to,
+	})
+	if err != nil {
+		return fmt.Errorf("workflow: failed to check transition: %w", err)
+	}
+	if allowed {
+		return nil
+	}
+
+	fromName := fmt.Sprintf("Status %d", from)
+	if source, err := store.GetWorkflowState(ctx, repo.GetWorkflowStateParams{ProjectID: projectID, Status: from}); err == nil {
+		fromName = source.Name
+	}
+	return &TransitionError{From: from, To: to, FromName: fromName, ToName: target.Name}
+}
+
+// loadState returns the state for status, nil if the project has no
+// workflow, or ErrUnknownStatus
+func loadState(ctx context.Context, store Store, projectID uuid.UUID, status int32) (*repo.WorkflowState, error) {
+	state, err := store.GetWorkflowState(ctx, repo.GetWorkflowStateParams{ProjectID: projectID, Status: status})
+	if err == nil {
+		return &state, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("workflow: failed to load state: %w", err)
+	}
+
+	count, err := store.CountWorkflowStates(ctx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("workflow: failed to count states: %w", err)
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnknownStatus, status)
+}
+
+// Load returns the project's workflow definition
+func Load(ctx context.Context, q *repo.Queries, projectID uuid.UUID) (Definition, error) {
+	states, err := q.ListWorkflowStates(ctx, projectID)
+	if err != nil {
+		return Definition{}, fmt.Errorf("workflow: failed to list states: %w", err)
+	}
+	transitions, err := q.ListWorkflowTransitions(ctx, projectID)
+	if err != nil {
+		return Definition{}, fmt.Errorf("workflow: failed to list transitions: %w", err)
+	}
+
+	def := Definition{
+		States:      make([]State, 0, len(states)),
+		Transitions: make([]Transition, 0, len(transitions)),
+	}
+	for _, s := range states {
+		def.States = append(def.States, State{
+			Status:    s.Status,
+			Name:      s.Name,
+			Color:     s.Color,
+			Position:  s.Position,
+			IsInitial: s.IsInitial,
+			IsFinal:   s.IsFinal,
+		})
+	}
+	for _, t := range transitions {
+		def.Transitions = append(def.Transitions, Transition{From: t.FromStatus, To: t.ToStatus})
+	}
+	return def, nil
+}
+
+// Validate checks a definition on its own and against the statuses
+// currently stored on the project's tasks, which must all remain states
+func (d Definition) Validate(inUse []int32) error {
+	if len(d.States) == 0 {
+		return fmt.Errorf("%w: at least one state is required", ErrInvalidDefinition)
+	}
+
+	known := make(map[int32]bool, len(d.States))
+	names := make(map[string]bool, len(d.States))
+	initial := 0
+	for _, s := range d.States {
+		name := strings.TrimSpace(s.Name)
+		switch {
+		case s.Status < 0:
+			return fmt.Errorf("%w: status %d must not be negative", ErrInvalidDefinition, s.Status)
+		case known[s.Status]:
+			return fmt.Errorf("%w: status %d is defined twice", ErrInvalidDefinition, s.Status)
+		case name == "":
+			return fmt.Errorf("%w: status %d needs a name", ErrInvalidDefinition, s.Status)
+		case names[strings.ToLower(name)]:
+			return fmt.Errorf("%w: state name %q is used twice", ErrInvalidDefinition, name)
+		case s.Color != "" && !ValidColor(s.Color):
+			return fmt.Errorf("%w: color %q must look like #RRGGBB", ErrInvalidDefinition, s.Color)
+		}
+		known[s.Status] = true
+		names[strings.ToLower(name)] = true
+		if s.IsInitial {
+			initial++
+		}
+	}
+	if initial != 1 {
+		return fmt.Errorf("%w: exactly one state must be initial", ErrInvalidDefinition)
+	}
+
+	seen := make(map[Transition]bool, len(d.Transitions))
+	for _, t := range d.Transitions {
+		switch {
+		case !known[t.From] || !known[t.To]:
+			return fmt.Errorf("%w: transition %d -> %d references an unknown state", ErrInvalidDefinition, t.From, t.To)
+		case t.From == t.To:
+			return fmt.Errorf("%w: transition %d -> %d does not change state", ErrInvalidDefinition, t.From, t.To)
+		case seen[t]:
+			return fmt.Errorf("%w: transition %d -> %d is listed twice", ErrInvalidDefinition, t.From, t.To)
+		}
+		seen[t] = true
+	}
+
+	for _, status := range inUse {
+		if !known[status] {
+			return fmt.Errorf("%w: status %d is still used by tasks", ErrInvalidDefinition, status)
+		}
+	}
+	return nil
+}
+
+// Replace validates def and swaps it in for the project's current workflow
+// in a single transaction. The statuses in use are read in the same
+// transaction, so the check sees the tasks as they are when the swap happens.
+func Replace(ctx context.Context, q *repo.Queries, projectID uuid.UUID, def Definition) (Definition, error) {
+	states := append([]State(nil), def.States...)
+	sort.SliceStable(states, func(i, j int) bool { return states[i].Position < states[j].Position })
+
+	var invalid error
+	err := q.InTx(ctx, func(tx *repo.Queries) error {
+		inUse, err := tx.ListTaskStatusesInUse(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("failed to list task statuses: %w", err)
+		}
+		if invalid = def.Validate(inUse); invalid != nil {
+			return invalid
+		}
+
+		if err := tx.DeleteWorkflowTransitions(ctx, projectID); err != nil {
+			return err
+		}
+		if err := tx.DeleteWorkflowStates(ctx, projectID); err != nil {
+			return err
+		}
+		for _, s := range states {
+			color := s.Color
+			if color == "" {
+				color = "#6B7280"
+			}
+			if _, err := tx.CreateWorkflowState(ctx, repo.CreateWorkflowStateParams{
+				ProjectID: projectID,
+				Status:    s.Status,
+				Name:      strings.TrimSpace(s.Name),
+				Color:     color,
+				Position:  s.Position,
+				IsInitial: s.IsInitial,
+				IsFinal:   s.IsFinal,
+			}); err != nil {
+				return err
+			}
+		}
+		for _, t := range def.Transitions {
+			if err := tx.CreateWorkflowTransition(ctx, repo.CreateWorkflowTransitionParams{
+				ProjectID:  projectID,
+				FromStatus: t.From,
+				ToStatus:   t.To,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if invalid != nil {
+		return Definition{}, invalid
+	}
+	if err != nil {
+		return Definition{}, fmt.Errorf("workflow: failed to replace workflow: %w", err)
+	}
+	return Load(ctx, q, projectID)
+}
+
+// InitialStatus returns the status new tasks start in, or 0 when the project has no workflow
+func InitialStatus(ctx context.Context, q *repo.Queries, projectID uuid.UUID) (int32, error) {
+	state, err := q.GetInitialWorkflowState(ctx, projectID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("workflow: failed to load initial state: %w", err)
+	}
+	return state.Status, nil
+}
@@ -0,0 +1,44 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefinitionValidate(t *testing.T) {
+	todo := State{Status: 0, Name: "To Do", Color: "#6B7280", Position: 0, IsInitial: true}
+	doing := State{Status: 1, Name: "In Progress", Position: 1}
+	done := State{Status: 2, Name: "Done", Color: "#10b981", Position: 2, IsFinal: true}
+	valid := []Transition{{From: 0, To: 1}, {From: 1, To: 2}, {From: 2, To: 0}}
+
+	tests := []struct {
+		name  string
+		def   Definition
+		inUse []int32
+		ok    bool
+	}{
+		{"valid", Definition{States: []State{todo, doing, done}, Transitions: valid}, []int32{0, 2}, true},
+		{"no transitions", Definition{States: []State{todo}}, nil, true},
+		{"no states", Definition{}, nil, false},
+		{"negative status", Definition{States: []State{todo, {Status: -1, Name: "Blocked"}}}, nil, false},
+		{"duplicate status", Definition{States: []State{todo, {Status: 0, Name: "Backlog"}}}, nil, false},
+		{"blank name", Definition{States: []State{todo, {Status: 1, Name: "  "}}}, nil, false},
+		{"duplicate name", Definition{States: []State{todo, {Status: 1, Name: " to do"}}}, nil, false},
+		{"bad color", Definition{States: []State{todo, {Status: 1, Name: "Doing", Color: "red"}}}, nil, false},
+		{"no initial state", Definition{States: []State{doing, done}}, nil, false},
+		{"two initial states", Definition{States: []State{todo, {Status: 1, Name: "Backlog", IsInitial: true}}}, nil, false},
+		{"unknown transition state", Definition{States: []State{todo, doing}, Transitions: []Transition{{From: 0, To: 2}}}, nil, false},
+		{"self transition", Definition{States: []State{todo, doing}, Transitions: []Transition{{From: 1, To: 1}}}, nil, false},
+		{"duplicate transition", Definition{States: []State{todo, doing}, Transitions: []Transition{{From: 0, To: 1}, {From: 0, To: 1}}}, nil, false},
+		{"status in use removed", Definition{States: []State{todo, doing}, Transitions: valid[:1]}, []int32{0, 2}, false},
+	}
+	for _, tt := range tests {
+		err := tt.def.Validate(tt.inUse)
+		if tt.ok && err != nil {
+			t.Errorf("%s: Validate() = %v, want nil", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidDefinition) {
+			t.Errorf("%s: Validate() = %v, want ErrInvalidDefinition", tt.name, err)
+		}
+	}
+}
@@ -1,30 +1,87 @@
 package ws
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+// sendBuffer is how many messages a client may fall behind by before it is
+// evicted as a slow consumer
+const sendBuffer = 256
+
 // Client represents a connected WebSocket client
 type Client struct {
-	conn      *websocket.Conn
-	userID    string
+	conn   *websocket.Conn
+	userID string
+	topics map[string]bool // Guarded by the hub's mutex
+	send   chan []byte
+	hub    *Hub
+
+	// While a resume is replaying missed events, live messages wait in
+	// pending so they reach the client after the replay. closeCode is the
+	// close frame WritePump sends once send is closed.
+	mu        sync.Mutex
+	replaying bool
+	pending   []queuedMessage
+	closed    bool
+	closeCode int
+
+	// Send metrics, see ClientStats
+	sent    atomic.Int64
+	written atomic.Int64
+	dropped atomic.Int64
+}
+
+// ClientStats are a connection's send metrics
+type ClientStats struct {
+	UserID   string `json:"userId"`
+	Sent     int64  `json:"sent"`     // Messages queued for the connection
+	Written  int64  `json:"written"`  // Messages written to the connection
+	Dropped  int64  `json:"dropped"`  // Messages lost to a full send buffer
+	Queued   int    `json:"queued"`   // Messages waiting to be written
+	Capacity int    `json:"capacity"` // Size of the send buffer
+}
+
+// queuedMessage is a live message held back during a replay
+type queuedMessage struct {
 	projectID string
-	send      chan []byte
-	hub       *Hub
+	seq       int64
+	data      []byte
 }
 
-// Hub manages all WebSocket connections
+// Hub manages all WebSocket connections. Its mutex guards clients, topics
+// and each client's topics: sends only need the read lock, while adding or
+// removing clients and subscriptions takes the write lock. A client's send
+// channel is only closed while the client is removed under the write lock,
+// and close is idempotent, so no message is sent on a closed channel.
 type Hub struct {
 	clients    map[*Client]bool
-	broadcast  chan []byte
-	Register   chan *Client // Exported for external registration
+	topics     map[string]map[*Client]bool // Subscribers by topic
+	Register   chan *Client                // Exported for external registration
 	unregister chan *Client
 	mutex      sync.RWMutex
+	store      Store
+	transport  Transport    // Carries PublishToProject events to every instance
+	evictions  atomic.Int64 // Slow consumers disconnected so far
+}
+
+// Store is the subset of repo.Queries used by the hub: membership checks for
+// project subscriptions and the realtime event log for replay. Sequence
+// numbers are assigned by the database when the notify triggers record an
+// event.
+type Store interface {
+	CheckProjectAccess(ctx context.Context, arg repo.CheckProjectAccessParams) (bool, error)
+	GetRealtimeEventBounds(ctx context.Context, projectID uuid.UUID) (repo.GetRealtimeEventBoundsRow, error)
+	ListRealtimeEventsSince(ctx context.Context, arg repo.ListRealtimeEventsSinceParams) ([]repo.ListRealtimeEventsSinceRow, error)
 }
 
 // Message represents a WebSocket message
@@ -35,25 +92,31 @@ type Message struct {
 	Data      interface{} `json:"data"`
 	ProjectID string      `json:"projectId,omitempty"`
 	UserID    string      `json:"userId,omitempty"`
+	Topic     string      `json:"topic,omitempty"`   // Topic to subscribe to or unsubscribe from
+	Seq       int64       `json:"seq,omitempty"`     // Project event sequence number
+	LastSeq   int64       `json:"lastSeq,omitempty"` // Last sequence number the client saw, on resume
 }
 
 // GlobalHub is the global WebSocket hub instance
 var GlobalHub *Hub
 
 // StartWebSocketHub initializes and starts the global WebSocket hub
-func StartWebSocketHub() {
-	GlobalHub = NewHub()
+func StartWebSocketHub(store Store) {
+	GlobalHub = NewHub(store)
 	go GlobalHub.Run()
 }
 
 // NewHub creates a new WebSocket hub
-func NewHub() *Hub {
-	return &Hub{
+func NewHub(store Store) *Hub {
+	h := &Hub{
 		clients:    make(map[*Client]bool),
-		broadcast:  make(chan []byte),
+		topics:     make(map[string]map[*Client]bool),
 		Register:   make(chan *Client),
 		unregister: make(chan *Client),
+		store:      store,
 	}
+	h.transport = NewLocalTransport(h)
+	return h
 }
 
 // Run starts the hub's main loop
@@ -61,71 +124,94 @@ func (h *Hub) Run() {
 	for {
 		select {
 		case client := <-h.Register:
-			h.mutex.Lock()
-			h.clients[client] = true
-			h.mutex.Unlock()
-			log.Printf("Client registered. Total clients: %d", len(h.clients))
+			total := h.register(client)
+			log.Printf("Client registered. Total clients: %d", total)
 
 		case client := <-h.unregister:
-			h.mutex.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.send)
-			}
-			h.mutex.Unlock()
-			log.Printf("Client unregistered. Total clients: %d", len(h.clients))
-
-		case message := <-h.broadcast:
-			h.mutex.RLock()
-			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
-					close(client.send)
-					delete(h.clients, client)
-				}
-			}
-			h.mutex.RUnlock()
+			total := h.disconnect(client, websocket.CloseNormalClosure)
+			log.Printf("Client unregistered. Total clients: %d", total)
 		}
 	}
 }
 
-// BroadcastToProject sends a message to all clients in a specific project
-func (h *Hub) BroadcastToProject(projectID string, messageType string, data interface{}) {
-	msg := Message{
+// BroadcastToProject sends a message to all clients subscribed to a project.
+// seq is the event's sequence number in the project's event log.
+func (h *Hub) BroadcastToProject(projectID string, seq int64, messageType string, data interface{}) {
+	matching, users := h.publish(Message{
 		Type:      messageType,
 		Data:      data,
 		ProjectID: projectID,
-	}
+		Seq:       seq,
+	}, ProjectTopic(projectID))
 
+	log.Printf("Broadcast: type=%s, project=%s, matching=%d, users=%v",
+		messageType, projectID, matching, users)
+}
+
+// publish sends msg to the subscribers of the topics and returns how many
+// there were and their user IDs. A client subscribed to several of the
+// topics gets the message once. Clients whose buffer is full are evicted.
+func (h *Hub) publish(msg Message, topics ...string) (int, []string) {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
 		log.Printf("Error marshaling message: %v", err)
-		return
+		return 0, nil
 	}
 
+	var slow []*Client
 	h.mutex.RLock()
-	totalClients := len(h.clients)
-	matchingClients := 0
-	clientsToNotify := []string{} // Track user IDs for logging
-
-	for client := range h.clients {
-		if client.projectID == projectID {
-			matchingClients++
-			clientsToNotify = append(clientsToNotify, client.userID)
-			select {
-			case client.send <- msgBytes:
-				// Message sent successfully
-			default:
-				close(client.send)
-				delete(h.clients, client)
+	sent := make(map[*Client]bool)
+	userIDs := []string{}
+	for _, topic := range topics {
+		for client := range h.topics[topic] {
+			if sent[client] {
+				continue
+			}
+			sent[client] = true
+			userIDs = append(userIDs, client.userID)
+			if !client.enqueue(msg.ProjectID, msg.Seq, msgBytes) {
+				slow = append(slow, client)
 			}
 		}
 	}
 	h.mutex.RUnlock()
 
-	log.Printf("Broadcast: type=%s, project=%s, total_clients=%d, matching=%d, users=%v",
-		messageType, projectID, totalClients, matchingClients, clientsToNotify)
+	h.evict(slow...)
+	return len(userIDs), userIDs
+}
+
+// disconnect removes the client and closes its send channel, making
+// WritePump send a close frame with the given code. It returns how many
+// clients remain and is a no-op for clients already removed.
+func (h *Hub) disconnect(c *Client, closeCode int) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.clients[c] {
+		h.removeClient(c)
+		c.close(closeCode)
+	}
+	return len(h.clients)
+}
+
+// evict disconnects slow consumers: clients whose send buffer filled up
+// because they read slower than events arrive. They are told to try again
+// later, and resume from their last sequence number when they reconnect.
+func (h *Hub) evict(clients ...*Client) {
+	for _, c := range clients {
+		h.mutex.Lock()
+		evicted := h.clients[c]
+		if evicted {
+			h.removeClient(c)
+			c.close(websocket.CloseTryAgainLater)
+		}
+		h.mutex.Unlock()
+
+		if evicted {
+			h.evictions.Add(1)
+			log.Printf("Evicted slow WebSocket client for user %s after %d dropped messages", c.userID, c.dropped.Load())
+		}
+	}
 }
 
 // GetProjectConnections returns connection status for a specific project
@@ -133,21 +219,66 @@ func (h *Hub) GetProjectConnections(projectID string) (int, int, []string) {
 	h.mutex.RLock()
 	defer h.mutex.RUnlock()
 
-	totalClients := len(h.clients)
-	matchingClients := 0
 	userIDs := []string{}
+	for client := range h.topics[ProjectTopic(projectID)] {
+		userIDs = append(userIDs, client.userID)
+	}
 
-	for client := range h.clients {
-		if client.projectID == projectID {
-			matchingClients++
-			userIDs = append(userIDs, client.userID)
-		}
+	return len(h.clients), len(userIDs), userIDs
+}
+
+// ProjectStats returns the send metrics of each connection subscribed to
+// the project
+func (h *Hub) ProjectStats(projectID string) []ClientStats {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	stats := []ClientStats{}
+	for client := range h.topics[ProjectTopic(projectID)] {
+		stats = append(stats, client.Stats())
+	}
+	return stats
+}
+
+// Evictions returns how many slow consumers the hub has disconnected
+func (h *Hub) Evictions() int64 {
+	return h.evictions.Load()
+}
+
+// Stats returns the client's send metrics
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		UserID:   c.userID,
+		Sent:     c.sent.Load(),
+		Written:  c.written.Load(),
+		Dropped:  c.dropped.Load(),
+		Queued:   len(c.send),
+		Capacity: cap(c.send),
 	}
+}
+
+// SendToUsers sends a project's sequenced event to every connection of the
+// given users, whether or not they are subscribed to the project. It is used
+// for events that change which projects a user can see.
+func (h *Hub) SendToUsers(userIDs []string, projectID string, seq int64, messageType string, data interface{}) {
+	topics := make([]string, 0, len(userIDs))
+	for _, userID := range userIDs {
+		topics = append(topics, UserTopic(userID))
+	}
+
+	matching, _ := h.publish(Message{
+		Type:      messageType,
+		Data:      data,
+		ProjectID: projectID,
+		Seq:       seq,
+	}, topics...)
 
-	return totalClients, matchingClients, userIDs
+	log.Printf("Sent to users: type=%s, project=%s, users=%d, connections=%d",
+		messageType, projectID, len(userIDs), matching)
 }
 
-// BroadcastToAll sends a message to all connected clients
+// BroadcastToAll sends a message to all connected clients. It is meant for
+// server-wide notices such as "reconnect"; project events go to topics.
 func (h *Hub) BroadcastToAll(messageType string, data interface{}) {
 	msg := Message{
 		Type: messageType,
@@ -160,16 +291,54 @@ func (h *Hub) BroadcastToAll(messageType string, data interface{}) {
 		return
 	}
 
+	var slow []*Client
 	h.mutex.RLock()
 	for client := range h.clients {
-		select {
-		case client.send <- msgBytes:
-		default:
-			close(client.send)
-			delete(h.clients, client)
+		if !client.enqueue("", 0, msgBytes) {
+			slow = append(slow, client)
 		}
 	}
 	h.mutex.RUnlock()
+
+	h.evict(slow...)
+}
+
+// enqueue queues a message for the client, holding it back while a replay
+// is in progress. It reports false when the client's buffer is full; the
+// caller must then evict the client, after releasing the hub's mutex.
+func (c *Client) enqueue(projectID string, seq int64, data []byte) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return true
+	}
+	if c.replaying {
+		c.pending = append(c.pending, queuedMessage{projectID: projectID, seq: seq, data: data})
+		return true
+	}
+	select {
+	case c.send <- data:
+		c.sent.Add(1)
+		return true
+	default:
+		c.dropped.Add(1)
+		return false
+	}
+}
+
+// close closes the client's send channel once, which makes WritePump send
+// a close frame with closeCode. Only the hub calls it, holding its mutex for
+// writing.
+func (c *Client) close(closeCode int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		c.closeCode = closeCode
+		close(c.send)
+	}
 }
 
 // ReadPump pumps messages from the WebSocket connection to the hub (exported)
@@ -227,8 +396,11 @@ func (c *Client) WritePump() {
 			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if !ok {
 				// Channel closed - send close frame and exit
-				// This happens when hub unregisters the client
-				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				// This happens when hub unregisters or evicts the client
+				c.mu.Lock()
+				closeCode := c.closeCode
+				c.mu.Unlock()
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, ""))
 				return
 			}
 
@@ -243,6 +415,7 @@ func (c *Client) WritePump() {
 				// Write error - ReadPump will handle cleanup
 				return
 			}
+			c.written.Add(1)
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -256,12 +429,16 @@ func (c *Client) WritePump() {
 // handleMessage processes incoming WebSocket messages
 func (c *Client) handleMessage(msg Message) {
 	switch msg.Type {
+	case "subscribe":
+		c.subscribe(msg.Topic)
+	case "unsubscribe":
+		c.unsubscribe(msg.Topic)
 	case "join_project":
-		c.projectID = msg.ProjectID
-		log.Printf("Client joined project: %s", msg.ProjectID)
+		c.join(msg.ProjectID)
 	case "leave_project":
-		c.projectID = ""
-		log.Printf("Client left project")
+		c.leave(msg.ProjectID)
+	case "resume":
+		c.resume(msg.ProjectID, msg.LastSeq)
 	case "init", "ping", "pong":
 		// Protocol control messages - silently accept
 		// These are used for connection health checks and initialization
@@ -271,13 +448,19 @@ func (c *Client) handleMessage(msg Message) {
 	}
 }
 
-// NewClient creates a new WebSocket client (exported helper function)
+// NewClient creates a new WebSocket client (exported helper function). The
+// client starts out subscribed to its user's topic and, if projectID is set,
+// to that project; the caller must have checked the user's access to it.
 func NewClient(conn *websocket.Conn, userID string, projectID string, hub *Hub) *Client {
+	topics := map[string]bool{UserTopic(userID): true}
+	if projectID != "" {
+		topics[ProjectTopic(projectID)] = true
+	}
 	return &Client{
-		conn:      conn,
-		userID:    userID,
-		projectID: projectID,
-		send:      make(chan []byte, 256),
-		hub:       hub,
+		conn:   conn,
+		userID: userID,
+		topics: topics,
+		send:   make(chan []byte, sendBuffer),
+		hub:    hub,
 	}
 }
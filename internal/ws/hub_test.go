@@ -0,0 +1,437 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"testing"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// fakeStore serves membership and the realtime event log from memory
+type fakeStore struct {
+	members map[repo.CheckProjectAccessParams]bool
+	bounds  repo.GetRealtimeEventBoundsRow
+	events  []repo.ListRealtimeEventsSinceRow
+	// onList runs while events are listed, i.e. in the middle of a replay
+	onList func()
+}
+
+func (s *fakeStore) CheckProjectAccess(_ context.Context, arg repo.CheckProjectAccessParams) (bool, error) {
+	return s.members[arg], nil
+}
+
+func (s *fakeStore) GetRealtimeEventBounds(context.Context, uuid.UUID) (repo.GetRealtimeEventBoundsRow, error) {
+	return s.bounds, nil
+}
+
+func (s *fakeStore) ListRealtimeEventsSince(_ context.Context, arg repo.ListRealtimeEventsSinceParams) ([]repo.ListRealtimeEventsSinceRow, error) {
+	if s.onList != nil {
+		s.onList()
+	}
+	var events []repo.ListRealtimeEventsSinceRow
+	for _, event := range s.events {
+		if event.Seq > arg.AfterSeq {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// eventLog returns the events first..last as kept in the realtime event log
+func eventLog(first, last int64) []repo.ListRealtimeEventsSinceRow {
+	var events []repo.ListRealtimeEventsSinceRow
+	for seq := first; seq <= last; seq++ {
+		events = append(events, repo.ListRealtimeEventsSinceRow{Seq: seq, Type: "cache_invalidate", Data: []byte(`{}`)})
+	}
+	return events
+}
+
+// describe summarises messages as type and sequence number, with the
+// lastSeq of resume acknowledgements, for comparison in tests
+func describe(msgs []Message) []string {
+	out := make([]string, len(msgs))
+	for i, msg := range msgs {
+		switch msg.Type {
+		case "resumed", "resync":
+			data, _ := msg.Data.(map[string]interface{})
+			out[i] = fmt.Sprintf("%s:%v", msg.Type, data["lastSeq"])
+		default:
+			out[i] = fmt.Sprintf("%s:%d", msg.Type, msg.Seq)
+		}
+	}
+	return out
+}
+
+// connect registers a client without a network connection; its messages are
+// read straight from the send channel
+func connect(h *Hub, userID, projectID string) *Client {
+	c := NewClient(nil, userID, projectID, h)
+	h.register(c)
+	return c
+}
+
+// received drains the messages queued for the client
+func received(t *testing.T, c *Client) []Message {
+	t.Helper()
+	var msgs []Message
+	for {
+		select {
+		case data, ok := <-c.send:
+			if !ok {
+				return msgs
+			}
+			var msg Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("unmarshal message: %v", err)
+			}
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func TestSendToUsers(t *testing.T) {
+	h := NewHub(nil)
+	projectID, otherProjectID := uuid.NewString(), uuid.NewString()
+	owner, member, outsider := uuid.NewString(), uuid.NewString(), uuid.NewString()
+
+	ownerConn := connect(h, owner, projectID)
+	ownerSecondConn := connect(h, owner, "")
+	memberConn := connect(h, member, "") // Not viewing the project
+	outsiderConn := connect(h, outsider, otherProjectID)
+	outsiderSameProject := connect(h, outsider, projectID) // Stale subscription
+
+	h.SendToUsers([]string{owner, member, owner}, projectID, 7, "cache_invalidate", map[string]string{"resource": "project_members"})
+
+	for name, c := range map[string]*Client{"owner": ownerConn, "owner second connection": ownerSecondConn, "member": memberConn} {
+		msgs := received(t, c)
+		if len(msgs) != 1 {
+			t.Fatalf("%s got %d messages, want 1", name, len(msgs))
+		}
+		if msgs[0].ProjectID != projectID || msgs[0].Seq != 7 || msgs[0].Type != "cache_invalidate" {
+			t.Errorf("%s got %+v", name, msgs[0])
+		}
+	}
+	for name, c := range map[string]*Client{"outsider": outsiderConn, "outsider subscribed to project": outsiderSameProject} {
+		if msgs := received(t, c); len(msgs) != 0 {
+			t.Errorf("%s got %d messages, want none: %+v", name, len(msgs), msgs)
+		}
+	}
+}
+
+func TestBroadcastToProject(t *testing.T) {
+	h := NewHub(nil)
+	projectID := uuid.NewString()
+
+	subscriber := connect(h, uuid.NewString(), projectID)
+	other := connect(h, uuid.NewString(), uuid.NewString())
+	unsubscribed := connect(h, uuid.NewString(), "")
+
+	h.BroadcastToProject(projectID, 3, "activity", nil)
+
+	if msgs := received(t, subscriber); len(msgs) != 1 || msgs[0].Seq != 3 {
+		t.Errorf("subscriber got %+v, want one message with seq 3", msgs)
+	}
+	if msgs := received(t, other); len(msgs) != 0 {
+		t.Errorf("client of another project got %+v", msgs)
+	}
+	if msgs := received(t, unsubscribed); len(msgs) != 0 {
+		t.Errorf("client without a project got %+v", msgs)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	h := NewHub(nil)
+	projectID := uuid.NewString()
+	removed, remaining := uuid.NewString(), uuid.NewString()
+
+	removedConn := connect(h, removed, projectID)
+	remainingConn := connect(h, remaining, projectID)
+
+	h.Unsubscribe(projectID, removed, "removed_from_project")
+	if msgs := received(t, removedConn); len(msgs) != 1 || msgs[0].Type != "unsubscribed" {
+		t.Fatalf("removed member got %+v, want an unsubscribed message", msgs)
+	}
+
+	h.BroadcastToProject(projectID, 1, "cache_invalidate", nil)
+	if msgs := received(t, removedConn); len(msgs) != 0 {
+		t.Errorf("removed member still got %+v", msgs)
+	}
+	if msgs := received(t, remainingConn); len(msgs) != 1 {
+		t.Errorf("remaining member got %d messages, want 1", len(msgs))
+	}
+}
+
+func TestSlowConsumerEviction(t *testing.T) {
+	h := NewHub(nil)
+	projectID := uuid.NewString()
+	fast := connect(h, uuid.NewString(), projectID)
+	slow := connect(h, uuid.NewString(), projectID)
+
+	for seq := int64(1); seq <= sendBuffer+10; seq++ {
+		h.BroadcastToProject(projectID, seq, "cache_invalidate", nil)
+		received(t, fast)
+	}
+
+	if total, matching, _ := h.GetProjectConnections(projectID); total != 1 || matching != 1 {
+		t.Fatalf("hub has %d clients, %d on the project, want only the fast one", total, matching)
+	}
+	if h.Evictions() != 1 {
+		t.Errorf("evictions = %d, want 1", h.Evictions())
+	}
+	if stats := slow.Stats(); stats.Sent != sendBuffer || stats.Dropped != 1 {
+		t.Errorf("slow client stats = %+v, want %d sent and 1 dropped", stats, sendBuffer)
+	}
+	if stats := fast.Stats(); stats.Sent != sendBuffer+10 || stats.Dropped != 0 {
+		t.Errorf("fast client stats = %+v, want %d sent and none dropped", stats, sendBuffer+10)
+	}
+	if slow.closeCode != websocket.CloseTryAgainLater {
+		t.Errorf("slow client close code = %d, want %d", slow.closeCode, websocket.CloseTryAgainLater)
+	}
+
+	// The buffered messages are still written before the close frame
+	if msgs := received(t, slow); len(msgs) != sendBuffer {
+		t.Errorf("slow client has %d queued messages, want %d", len(msgs), sendBuffer)
+	}
+	if !slow.closed {
+		t.Error("slow client's send channel is still open")
+	}
+}
+
+func TestReplayEvictsSlowConsumer(t *testing.T) {
+	h := NewHub(nil)
+	projectID := uuid.NewString()
+	c := connect(h, uuid.NewString(), projectID)
+
+	for seq := int64(1); seq <= sendBuffer; seq++ {
+		h.BroadcastToProject(projectID, seq, "cache_invalidate", nil)
+	}
+
+	// The resync message no longer fits in the buffer
+	c.resume(projectID, 0)
+
+	if total, _, _ := h.GetProjectConnections(projectID); total != 0 {
+		t.Fatalf("hub has %d clients, want the slow one evicted", total)
+	}
+	if h.Evictions() != 1 {
+		t.Errorf("evictions = %d, want 1", h.Evictions())
+	}
+	if c.closeCode != websocket.CloseTryAgainLater {
+		t.Errorf("close code = %d, want %d", c.closeCode, websocket.CloseTryAgainLater)
+	}
+}
+
+func TestResume(t *testing.T) {
+	tests := []struct {
+		name    string
+		bounds  repo.GetRealtimeEventBoundsRow
+		events  []repo.ListRealtimeEventsSinceRow
+		lastSeq int64
+		want    []string
+	}{
+		{"up to date", repo.GetRealtimeEventBoundsRow{OldestSeq: 1, LastSeq: 5}, eventLog(1, 5), 5, []string{"resumed:5"}},
+		{"missed events", repo.GetRealtimeEventBoundsRow{OldestSeq: 1, LastSeq: 5}, eventLog(1, 5), 2,
+			[]string{"cache_invalidate:3", "cache_invalidate:4", "cache_invalidate:5", "resumed:5"}},
+		{"missed from the oldest kept event", repo.GetRealtimeEventBoundsRow{OldestSeq: 4, LastSeq: 5}, eventLog(4, 5), 3,
+			[]string{"cache_invalidate:4", "cache_invalidate:5", "resumed:5"}},
+		{"ahead of the log", repo.GetRealtimeEventBoundsRow{OldestSeq: 1, LastSeq: 5}, eventLog(1, 5), 9, []string{"resync:5"}},
+		{"empty log", repo.GetRealtimeEventBoundsRow{}, nil, 3, []string{"resync:0"}},
+		{"missed events pruned", repo.GetRealtimeEventBoundsRow{OldestSeq: 4, LastSeq: 5}, eventLog(4, 5), 2, []string{"resync:5"}},
+		{"gap larger than maxReplay", repo.GetRealtimeEventBoundsRow{OldestSeq: 1, LastSeq: maxReplay + 10}, eventLog(1, maxReplay+10), 9,
+			[]string{fmt.Sprintf("resync:%d", maxReplay+10)}},
+	}
+	for _, tt := range tests {
+		h := NewHub(&fakeStore{bounds: tt.bounds, events: tt.events})
+		projectID := uuid.NewString()
+		c := connect(h, uuid.NewString(), projectID)
+
+		c.resume(projectID, tt.lastSeq)
+
+		got := describe(received(t, c))
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResumeDeduplicatesLiveEvents(t *testing.T) {
+	projectID := uuid.NewString()
+	store := &fakeStore{bounds: repo.GetRealtimeEventBoundsRow{OldestSeq: 1, LastSeq: 5}, events: eventLog(1, 5)}
+	h := NewHub(store)
+	c := connect(h, uuid.NewString(), projectID)
+
+	// Events published while the replay is loaded are held back; those the
+	// replay already covers are dropped
+	store.onList = func() {
+		h.BroadcastToProject(projectID, 4, "cache_invalidate", nil)
+		h.BroadcastToProject(projectID, 6, "cache_invalidate", nil)
+		h.BroadcastToAll("reconnect", nil)
+	}
+	c.resume(projectID, 3)
+
+	got := describe(received(t, c))
+	want := []string{"cache_invalidate:4", "cache_invalidate:5", "resumed:5", "cache_invalidate:6", "reconnect:0"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	userID := uuid.New()
+	memberOf, otherMemberOf, notMemberOf := uuid.New(), uuid.New(), uuid.New()
+	h := NewHub(&fakeStore{members: map[repo.CheckProjectAccessParams]bool{
+		{ProjectID: memberOf, UserID: userID}:      true,
+		{ProjectID: otherMemberOf, UserID: userID}: true,
+	}})
+	c := connect(h, userID.String(), "")
+
+	// A non-member is refused and not subscribed
+	c.join(notMemberOf.String())
+	if msgs := received(t, c); len(msgs) != 1 || msgs[0].Type != "error" || msgs[0].ProjectID != notMemberOf.String() {
+		t.Fatalf("join as non-member got %+v, want one error message", msgs)
+	}
+	if h.subscribed(c, ProjectTopic(notMemberOf.String())) {
+		t.Fatal("non-member was subscribed")
+	}
+
+	// A member joins
+	c.join(memberOf.String())
+	if msgs := received(t, c); len(msgs) != 1 || msgs[0].Type != "joined" || msgs[0].ProjectID != memberOf.String() {
+		t.Fatalf("join as member got %+v, want one joined message", msgs)
+	}
+
+	// A rejected join keeps the current subscription
+	c.join(notMemberOf.String())
+	if msgs := received(t, c); len(msgs) != 1 || msgs[0].Type != "error" {
+		t.Fatalf("second join as non-member got %+v, want one error message", msgs)
+	}
+	h.BroadcastToProject(notMemberOf.String(), 1, "cache_invalidate", nil)
+	h.BroadcastToProject(memberOf.String(), 1, "cache_invalidate", nil)
+	if msgs := received(t, c); len(msgs) != 1 || msgs[0].ProjectID != memberOf.String() {
+		t.Fatalf("after a rejected join got %+v, want only the joined project's event", msgs)
+	}
+
+	// Joining another project replaces the first
+	c.join(otherMemberOf.String())
+	if msgs := received(t, c); len(msgs) != 1 || msgs[0].Type != "joined" {
+		t.Fatalf("join of another project got %+v, want one joined message", msgs)
+	}
+	if h.subscribed(c, ProjectTopic(memberOf.String())) || !h.subscribed(c, ProjectTopic(otherMemberOf.String())) {
+		t.Error("joining another project did not replace the subscription")
+	}
+}
+
+// TestHubConcurrency runs broadcasts, subscription changes, connects,
+// disconnects and evictions at the same time. Run it with -race.
+func TestHubConcurrency(t *testing.T) {
+	if testing.Short() {
+		t.Skip("stress test")
+	}
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(io.Discard)
+
+	const (
+		readers           = 2000 // Drain their messages
+		stalled           = 500  // Never read, and get evicted
+		churn             = 1000 // Connect and disconnect during the test
+		projectBroadcasts = 200
+		allBroadcasts     = 40
+		broadcasters      = 8
+	)
+
+	h := NewHub(nil)
+	projectID, busyProjectID := uuid.NewString(), uuid.NewString()
+
+	counts := make([]int, readers)
+	clients := make([]*Client, readers)
+	var readWG sync.WaitGroup
+	for i := range clients {
+		clients[i] = connect(h, uuid.NewString(), projectID)
+		readWG.Add(1)
+		go func(i int) {
+			defer readWG.Done()
+			for range clients[i].send {
+				counts[i]++
+			}
+		}(i)
+	}
+	stalledClients := make([]*Client, stalled)
+	for i := range stalledClients {
+		stalledClients[i] = connect(h, uuid.NewString(), busyProjectID)
+	}
+
+	var wg sync.WaitGroup
+	for b := 0; b < broadcasters; b++ {
+		wg.Add(1)
+		go func(b int) {
+			defer wg.Done()
+			for i := 0; i < projectBroadcasts/broadcasters; i++ {
+				h.BroadcastToProject(projectID, int64(b*1000+i), "cache_invalidate", nil)
+				h.BroadcastToProject(busyProjectID, int64(i), "cache_invalidate", nil)
+				h.BroadcastToProject(busyProjectID, int64(i), "cache_invalidate", nil)
+				if i < allBroadcasts/broadcasters {
+					h.BroadcastToAll("reconnect", nil)
+				}
+			}
+		}(b)
+	}
+
+	// Disconnect half the stalled clients while they are being evicted
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for _, c := range stalledClients[:stalled/2] {
+			h.disconnect(c, websocket.CloseNormalClosure)
+		}
+	}()
+
+	// Clients that come and go, changing subscriptions in between
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < churn; i++ {
+			userID := uuid.NewString()
+			c := connect(h, userID, busyProjectID)
+			c.leave("")
+			c.subscribe(UserTopic(userID))
+			h.SendToUsers([]string{userID}, busyProjectID, 1, "cache_invalidate", nil)
+			h.Unsubscribe(busyProjectID, userID, "removed_from_project")
+			h.disconnect(c, websocket.CloseNormalClosure)
+		}
+	}()
+
+	wg.Wait()
+
+	for _, c := range stalledClients {
+		h.disconnect(c, websocket.CloseNormalClosure)
+	}
+	for _, c := range clients {
+		if stats := c.Stats(); stats.Dropped != 0 {
+			t.Fatalf("reader dropped messages: %+v", stats)
+		}
+		h.disconnect(c, websocket.CloseNormalClosure)
+	}
+	readWG.Wait()
+
+	want := projectBroadcasts + allBroadcasts
+	for i, n := range counts {
+		if n != want {
+			t.Fatalf("reader %d got %d messages, want %d", i, n, want)
+		}
+	}
+	if h.Evictions() == 0 {
+		t.Error("no stalled client was evicted")
+	}
+	if total, _, _ := h.GetProjectConnections(projectID); total != 0 || len(h.topics) != 0 {
+		t.Errorf("hub still has %d clients and %d topics", total, len(h.topics))
+	}
+}
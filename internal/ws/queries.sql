@@ -0,0 +1,15 @@
+-- name: GetRealtimeEventBounds :one
+-- Oldest sequence number still in the project's event log and the last one
+-- assigned; both are 0 before the project's first event
+SELECT
+    COALESCE((SELECT MIN(seq) FROM realtime_events WHERE project_id = $1), 0)::bigint AS oldest_seq,
+    COALESCE((SELECT last_seq FROM project_event_seqs WHERE project_id = $1), 0)::bigint AS last_seq;
+
+-- name: ListRealtimeEventsSince :many
+SELECT seq, type, data FROM realtime_events
+WHERE project_id = $1 AND seq > sqlc.arg(after_seq)
+ORDER BY seq;
+
+-- name: PublishHubEvent :exec
+-- Fan a hub event out to the NOTIFY listener of every API instance
+SELECT pg_notify('hub_events', sqlc.arg(payload)::text);
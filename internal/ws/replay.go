@@ -0,0 +1,153 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// maxReplay caps how many missed events a resume replays. It stays below
+// the client's send buffer so a replay never has to wait for the writer;
+// larger gaps get a resync instead.
+const maxReplay = 200
+
+// resume replays the project events the client missed after lastSeq,
+// followed by a "resumed" message. If the events are no longer in the log,
+// or there are too many, the client gets a "resync" message and must refetch
+// its state. Live events that arrive meanwhile are delivered after the
+// replay, minus any the replay already covered.
+func (c *Client) resume(projectID string, lastSeq int64) {
+	// Clients of a single project may leave projectId out
+	if projectID == "" {
+		projectID = c.hub.onlyProject(c)
+	}
+	if projectID == "" || !c.hub.subscribed(c, ProjectTopic(projectID)) {
+		c.sendError(projectID, "", "resume requires a subscribed project")
+		return
+	}
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		c.sendError(projectID, "", "invalid project ID")
+		return
+	}
+
+	c.mu.Lock()
+	c.replaying = true
+	c.mu.Unlock()
+
+	replay, lastSent, ok := c.loadReplay(projectUUID, lastSeq)
+	if !c.finishReplay(projectID, replay, lastSent, ok) {
+		c.hub.evict(c)
+	}
+}
+
+// finishReplay queues the replay and the live events held back during it,
+// and ends the replay. It reports false when the client's buffer filled up;
+// the caller must then evict the client.
+func (c *Client) finishReplay(projectID string, replay [][]byte, lastSent int64, ok bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.replaying = false
+	pending := c.pending
+	c.pending = nil
+	if c.closed {
+		return true
+	}
+
+	if !ok {
+		replay = nil
+		if msg, err := json.Marshal(Message{Type: "resync", ProjectID: projectID, Data: map[string]int64{"lastSeq": lastSent}}); err == nil {
+			replay = append(replay, msg)
+		}
+	} else if msg, err := json.Marshal(Message{Type: "resumed", ProjectID: projectID, Data: map[string]interface{}{"lastSeq": lastSent, "replayed": len(replay)}}); err == nil {
+		replay = append(replay, msg)
+	}
+
+	for _, msg := range replay {
+		if !c.trySend(msg) {
+			return false
+		}
+	}
+	for _, queued := range pending {
+		if queued.projectID == projectID && queued.seq != 0 && queued.seq <= lastSent {
+			continue
+		}
+		if !c.trySend(queued.data) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadReplay fetches the messages to replay after lastSeq and the sequence
+// number the client is caught up to afterwards. ok is false when the client
+// must resync; lastSent is then the project's latest sequence number.
+func (c *Client) loadReplay(projectID uuid.UUID, lastSeq int64) (replay [][]byte, lastSent int64, ok bool) {
+	if c.hub.store == nil {
+		return nil, 0, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	bounds, err := c.hub.store.GetRealtimeEventBounds(ctx, projectID)
+	if err != nil {
+		log.Printf("Failed to load realtime event bounds for project %s: %v", projectID, err)
+		return nil, 0, false
+	}
+	if lastSeq == bounds.LastSeq {
+		return nil, lastSeq, true
+	}
+	// A client ahead of the log saw a different history (e.g. a restored
+	// database); one behind the oldest kept event has missed too much
+	if lastSeq > bounds.LastSeq || bounds.OldestSeq == 0 || lastSeq < bounds.OldestSeq-1 || bounds.LastSeq-lastSeq > maxReplay {
+		return nil, bounds.LastSeq, false
+	}
+
+	events, err := c.hub.store.ListRealtimeEventsSince(ctx, repo.ListRealtimeEventsSinceParams{
+		ProjectID: projectID,
+		AfterSeq:  lastSeq,
+	})
+	if err != nil {
+		log.Printf("Failed to load realtime events for project %s: %v", projectID, err)
+		return nil, bounds.LastSeq, false
+	}
+
+	lastSent = lastSeq
+	for _, event := range events {
+		if len(replay) == maxReplay {
+			break
+		}
+		msg, err := json.Marshal(Message{
+			Type:      event.Type,
+			Data:      json.RawMessage(event.Data),
+			ProjectID: projectID.String(),
+			Seq:       event.Seq,
+		})
+		if err != nil {
+			continue
+		}
+		replay = append(replay, msg)
+		lastSent = event.Seq
+	}
+	return replay, lastSent, true
+}
+
+// trySend queues msg without blocking; the caller holds c.mu. A full buffer
+// means the client is not keeping up, and it is evicted like any other slow
+// consumer.
+func (c *Client) trySend(msg []byte) bool {
+	select {
+	case c.send <- msg:
+		c.sent.Add(1)
+		return true
+	default:
+		c.dropped.Add(1)
+		return false
+	}
+}
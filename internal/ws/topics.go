@@ -0,0 +1,264 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"devhive-backend/internal/repo"
+
+	"github.com/google/uuid"
+)
+
+// Topic prefixes. A client may subscribe to any number of project topics it
+// is a member of, and to its own user topic, which it joins on connect.
+const (
+	projectTopicPrefix = "project:"
+	userTopicPrefix    = "user:"
+)
+
+// ProjectTopic is the topic carrying a project's events
+func ProjectTopic(projectID string) string {
+	return projectTopicPrefix + projectID
+}
+
+// UserTopic is the topic carrying events for one user on all their connections
+func UserTopic(userID string) string {
+	return userTopicPrefix + userID
+}
+
+// register adds a client with the subscriptions it was created with and
+// returns how many clients there are
+func (h *Hub) register(c *Client) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.clients[c] = true
+	for topic := range c.topics {
+		h.addTopic(c, topic)
+	}
+	return len(h.clients)
+}
+
+// addTopic subscribes the client to a topic; the caller holds h.mutex
+func (h *Hub) addTopic(c *Client, topic string) {
+	if c.topics == nil {
+		c.topics = make(map[string]bool)
+	}
+	c.topics[topic] = true
+
+	subscribers, ok := h.topics[topic]
+	if !ok {
+		subscribers = make(map[*Client]bool)
+		h.topics[topic] = subscribers
+	}
+	subscribers[c] = true
+}
+
+// removeTopic unsubscribes the client from a topic; the caller holds h.mutex
+func (h *Hub) removeTopic(c *Client, topic string) {
+	delete(c.topics, topic)
+
+	if subscribers, ok := h.topics[topic]; ok {
+		delete(subscribers, c)
+		if len(subscribers) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+}
+
+// removeClient forgets the client and all its subscriptions; the caller
+// holds h.mutex
+func (h *Hub) removeClient(c *Client) {
+	for topic := range c.topics {
+		h.removeTopic(c, topic)
+	}
+	delete(h.clients, c)
+}
+
+// subscribed reports whether the client is subscribed to topic
+func (h *Hub) subscribed(c *Client, topic string) bool {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	return c.topics[topic]
+}
+
+// onlyProject returns the client's project when it is subscribed to exactly
+// one, and "" otherwise
+func (h *Hub) onlyProject(c *Client) string {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	projectID := ""
+	for topic := range c.topics {
+		if id, ok := strings.CutPrefix(topic, projectTopicPrefix); ok {
+			if projectID != "" {
+				return ""
+			}
+			projectID = id
+		}
+	}
+	return projectID
+}
+
+// subscribe adds a topic after checking the client may see it, and
+// acknowledges with "subscribed" or "error"
+func (c *Client) subscribe(topic string) {
+	projectID, ok := c.authorizeTopic(topic)
+	if !ok {
+		return
+	}
+
+	c.hub.mutex.Lock()
+	c.hub.addTopic(c, topic)
+	c.hub.mutex.Unlock()
+
+	c.sendControl(Message{Type: "subscribed", ProjectID: projectID, Topic: topic})
+}
+
+// unsubscribe removes a topic and acknowledges with "unsubscribed"
+func (c *Client) unsubscribe(topic string) {
+	c.hub.mutex.Lock()
+	c.hub.removeTopic(c, topic)
+	c.hub.mutex.Unlock()
+
+	projectID := ""
+	if id, ok := strings.CutPrefix(topic, projectTopicPrefix); ok {
+		projectID = id
+	}
+	c.sendControl(Message{Type: "unsubscribed", ProjectID: projectID, Topic: topic})
+}
+
+// join handles the single-project join_project message: it subscribes the
+// client to the project in place of any other and acknowledges with
+// "joined", or with "error" leaving the current subscriptions in place
+func (c *Client) join(projectID string) {
+	topic := ProjectTopic(projectID)
+	if _, ok := c.authorizeTopic(topic); !ok {
+		return
+	}
+
+	c.hub.mutex.Lock()
+	for t := range c.topics {
+		if strings.HasPrefix(t, projectTopicPrefix) && t != topic {
+			c.hub.removeTopic(c, t)
+		}
+	}
+	c.hub.addTopic(c, topic)
+	c.hub.mutex.Unlock()
+
+	log.Printf("Client joined project: %s", projectID)
+	c.sendControl(Message{Type: "joined", ProjectID: projectID, Topic: topic})
+}
+
+// leave handles leave_project: it drops the given project, or every project
+// when projectID is empty
+func (c *Client) leave(projectID string) {
+	c.hub.mutex.Lock()
+	for t := range c.topics {
+		if strings.HasPrefix(t, projectTopicPrefix) && (projectID == "" || t == ProjectTopic(projectID)) {
+			c.hub.removeTopic(c, t)
+		}
+	}
+	c.hub.mutex.Unlock()
+
+	log.Printf("Client left project")
+}
+
+// authorizeTopic checks that the client may subscribe to topic, sending an
+// "error" message when it may not. For project topics it also returns the
+// project ID.
+func (c *Client) authorizeTopic(topic string) (string, bool) {
+	if topic == UserTopic(c.userID) {
+		return "", true
+	}
+
+	projectID, ok := strings.CutPrefix(topic, projectTopicPrefix)
+	if !ok {
+		c.sendError("", topic, "unknown topic")
+		return "", false
+	}
+	if c.hub.store == nil {
+		c.sendError(projectID, topic, "project subscriptions are unavailable")
+		return "", false
+	}
+	projectUUID, err := uuid.Parse(projectID)
+	if err != nil {
+		c.sendError(projectID, topic, "invalid project ID")
+		return "", false
+	}
+	userUUID, err := uuid.Parse(c.userID)
+	if err != nil {
+		c.sendError(projectID, topic, "invalid user ID")
+		return "", false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	hasAccess, err := c.hub.store.CheckProjectAccess(ctx, repo.CheckProjectAccessParams{
+		ProjectID: projectUUID,
+		UserID:    userUUID,
+	})
+	if err != nil {
+		log.Printf("ERROR: CheckProjectAccess failed for project %s, user %s: %v", projectID, c.userID, err)
+		c.sendError(projectID, topic, "failed to verify project access")
+		return "", false
+	}
+	if !hasAccess {
+		log.Printf("WARN: User %s tried to subscribe to project %s without access", c.userID, projectID)
+		c.sendError(projectID, topic, "not a member of this project")
+		return "", false
+	}
+	return projectID, true
+}
+
+// Unsubscribe drops the project subscription of the user's clients, or of
+// every client when userID is empty, and tells them why. It is called when a
+// member is removed or the project is deleted.
+func (h *Hub) Unsubscribe(projectID, userID, reason string) {
+	topic := ProjectTopic(projectID)
+	msg, err := json.Marshal(Message{
+		Type:      "unsubscribed",
+		ProjectID: projectID,
+		Topic:     topic,
+		Data:      map[string]string{"reason": reason},
+	})
+	if err != nil {
+		return
+	}
+
+	var slow []*Client
+	h.mutex.Lock()
+	for client := range h.topics[topic] {
+		if userID != "" && client.userID != userID {
+			continue
+		}
+		h.removeTopic(client, topic)
+		if !client.enqueue(projectID, 0, msg) {
+			slow = append(slow, client)
+		}
+		log.Printf("Unsubscribed user %s from project %s: %s", client.userID, projectID, reason)
+	}
+	h.mutex.Unlock()
+
+	h.evict(slow...)
+}
+
+// sendControl queues a protocol message for the client
+func (c *Client) sendControl(msg Message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	if !c.enqueue(msg.ProjectID, 0, data) {
+		c.hub.evict(c)
+	}
+}
+
+// sendError answers a client request that could not be carried out
+func (c *Client) sendError(projectID, topic, reason string) {
+	c.sendControl(Message{Type: "error", ProjectID: projectID, Topic: topic, Data: map[string]string{"error": reason}})
+}
@@ -0,0 +1,116 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// HubEventsChannel is the PostgreSQL NOTIFY channel PostgresTransport
+// publishes on; every instance's NOTIFY listener passes its payloads to
+// Hub.Deliver
+const HubEventsChannel = "hub_events"
+
+// maxNotifyPayload stays under PostgreSQL's 8000 byte NOTIFY limit
+const maxNotifyPayload = 7900
+
+// Envelope is a hub event on its way to the clients of every instance
+type Envelope struct {
+	Topics  []string `json:"topics,omitempty"` // No topics means every client
+	Message Message  `json:"message"`
+}
+
+// Transport carries hub events between API instances. Publish must hand the
+// envelope to Deliver on every instance, this one included, so each
+// instance sends it to its own clients only.
+type Transport interface {
+	Publish(ctx context.Context, env Envelope) error
+}
+
+// LocalTransport delivers straight to this instance's hub. It is enough
+// when a single API instance serves all WebSocket clients.
+type LocalTransport struct {
+	hub *Hub
+}
+
+// NewLocalTransport creates an in-process transport for hub
+func NewLocalTransport(hub *Hub) *LocalTransport {
+	return &LocalTransport{hub: hub}
+}
+
+// Publish delivers the envelope to the hub's clients
+func (t *LocalTransport) Publish(ctx context.Context, env Envelope) error {
+	t.hub.Deliver(env)
+	return nil
+}
+
+// NotifyStore is the subset of repo.Queries used by PostgresTransport
+type NotifyStore interface {
+	PublishHubEvent(ctx context.Context, payload string) error
+}
+
+// PostgresTransport publishes hub events with NOTIFY on HubEventsChannel, so
+// any number of API replicas behind a load balancer reach all clients.
+// Delivery on each instance is done by its NOTIFY listener.
+type PostgresTransport struct {
+	store NotifyStore
+}
+
+// NewPostgresTransport creates a transport that publishes through store
+func NewPostgresTransport(store NotifyStore) *PostgresTransport {
+	return &PostgresTransport{store: store}
+}
+
+// Publish sends the envelope to every instance. Events too large for a
+// NOTIFY payload are rejected; clients still get the cache invalidation
+// for the change and refetch.
+func (t *PostgresTransport) Publish(ctx context.Context, env Envelope) error {
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		return fmt.Errorf("hub event %s is %d bytes, over the %d byte NOTIFY limit", env.Message.Type, len(payload), maxNotifyPayload)
+	}
+	return t.store.PublishHubEvent(ctx, string(payload))
+}
+
+// SetTransport sets how the hub's published events reach clients. Hubs
+// start with a LocalTransport.
+func (h *Hub) SetTransport(transport Transport) {
+	h.transport = transport
+}
+
+// PublishToProject sends an event to the project's subscribers on every
+// instance. It implements broadcast.Publisher.
+func (h *Hub) PublishToProject(ctx context.Context, projectID, eventType string, data interface{}) error {
+	return h.transport.Publish(ctx, Envelope{
+		Topics: []string{ProjectTopic(projectID)},
+		Message: Message{
+			Type:      eventType,
+			Data:      data,
+			ProjectID: projectID,
+		},
+	})
+}
+
+// Deliver sends an envelope received from the transport to this instance's
+// clients
+func (h *Hub) Deliver(env Envelope) {
+	if len(env.Topics) == 0 {
+		h.BroadcastToAll(env.Message.Type, env.Message.Data)
+		return
+	}
+	h.publish(env.Message, env.Topics...)
+}
+
+// DeliverPayload decodes a NOTIFY payload from HubEventsChannel and delivers it
+func (h *Hub) DeliverPayload(payload string) {
+	var env Envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		log.Printf("❌ Failed to parse hub event: %v. Payload: %s", err, payload)
+		return
+	}
+	h.Deliver(env)
+}
@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// fakeBus stands in for PostgreSQL NOTIFY: every published payload reaches
+// the listener of each instance
+type fakeBus struct {
+	instances []*Hub
+}
+
+func (b *fakeBus) PublishHubEvent(ctx context.Context, payload string) error {
+	for _, h := range b.instances {
+		h.DeliverPayload(payload)
+	}
+	return nil
+}
+
+func TestPostgresTransportFansOut(t *testing.T) {
+	bus := &fakeBus{}
+	a, b := NewHub(nil), NewHub(nil)
+	bus.instances = []*Hub{a, b}
+	a.SetTransport(NewPostgresTransport(bus))
+	b.SetTransport(NewPostgresTransport(bus))
+
+	projectID := uuid.NewString()
+	onA := connect(a, uuid.NewString(), projectID)
+	onB := connect(b, uuid.NewString(), projectID)
+	otherProject := connect(b, uuid.NewString(), uuid.NewString())
+
+	if err := a.PublishToProject(context.Background(), projectID, "message_created", map[string]string{"content": "hi"}); err != nil {
+		t.Fatalf("PublishToProject() error = %v", err)
+	}
+
+	for name, c := range map[string]*Client{"client on publishing instance": onA, "client on other instance": onB} {
+		msgs := received(t, c)
+		if len(msgs) != 1 || msgs[0].Type != "message_created" || msgs[0].ProjectID != projectID {
+			t.Errorf("%s got %+v, want one message_created", name, msgs)
+		}
+	}
+	if msgs := received(t, otherProject); len(msgs) != 0 {
+		t.Errorf("client of another project got %+v", msgs)
+	}
+}
+
+func TestPostgresTransportRejectsOversizedEvents(t *testing.T) {
+	bus := &fakeBus{}
+	h := NewHub(nil)
+	bus.instances = []*Hub{h}
+	h.SetTransport(NewPostgresTransport(bus))
+
+	err := h.PublishToProject(context.Background(), uuid.NewString(), "message_created", strings.Repeat("x", maxNotifyPayload))
+	if err == nil {
+		t.Error("PublishToProject() error = nil, want payload size error")
+	}
+}